@@ -194,28 +194,15 @@ func (d *Pan123) Put(ctx context.Context, dstDir model.Obj, file model.FileStrea
 			return err
 		}
 	}
-	data := base.Json{
-		"driveId":      0,
-		"duplicate":    2, // 2->覆盖 1->重命名 0->默认
-		"etag":         etag,
-		"fileName":     file.GetName(),
-		"parentFileId": dstDir.GetID(),
-		"size":         file.GetSize(),
-		"type":         0,
-	}
-	var resp UploadResp
-	res, err := d.Request(UploadRequest, http.MethodPost, func(req *resty.Request) {
-		req.SetBody(data).SetContext(ctx)
-	}, &resp)
+	resp, err := d.uploadRequest(ctx, dstDir, file.GetName(), file.GetSize(), etag)
 	if err != nil {
 		return err
 	}
-	log.Debugln("upload request res: ", string(res))
 	if resp.Data.Reuse || resp.Data.Key == "" {
 		return nil
 	}
 	if resp.Data.AccessKeyId == "" || resp.Data.SecretAccessKey == "" || resp.Data.SessionToken == "" {
-		err = d.newUpload(ctx, &resp, file, up)
+		err = d.newUpload(ctx, resp, file, up)
 		return err
 	} else {
 		cfg := &aws.Config{
@@ -253,6 +240,21 @@ func (d *Pan123) Put(ctx context.Context, dstDir model.Obj, file model.FileStrea
 	return err
 }
 
+func (d *Pan123) PutHashTypes() []*utils.HashType {
+	return []*utils.HashType{utils.MD5}
+}
+
+func (d *Pan123) PutByHash(ctx context.Context, dstDir model.Obj, name string, size int64, hashes utils.HashInfo) (model.Obj, error) {
+	resp, err := d.uploadRequest(ctx, dstDir, name, size, hashes.GetHash(utils.MD5))
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Data.Reuse {
+		return nil, errs.RapidUploadMissed
+	}
+	return nil, nil
+}
+
 func (d *Pan123) APIRateLimit(ctx context.Context, api string) error {
 	value, _ := d.apiRateLimit.LoadOrStore(api,
 		rate.NewLimiter(rate.Every(700*time.Millisecond), 1))
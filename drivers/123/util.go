@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/go-resty/resty/v2"
 	jsoniter "github.com/json-iterator/go"
@@ -282,3 +283,25 @@ func (d *Pan123) getFiles(ctx context.Context, parentId string, name string) ([]
 	}
 	return res, nil
 }
+
+// uploadRequest create the upload of the file, which is finished at once if the file with the etag exists (reuse)
+func (d *Pan123) uploadRequest(ctx context.Context, dstDir model.Obj, name string, size int64, etag string) (*UploadResp, error) {
+	data := base.Json{
+		"driveId":      0,
+		"duplicate":    2, // 2->覆盖 1->重命名 0->默认
+		"etag":         strings.ToLower(etag),
+		"fileName":     name,
+		"parentFileId": dstDir.GetID(),
+		"size":         size,
+		"type":         0,
+	}
+	var resp UploadResp
+	res, err := d.Request(UploadRequest, http.MethodPost, func(req *resty.Request) {
+		req.SetBody(data).SetContext(ctx)
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugln("upload request res: ", string(res))
+	return &resp, nil
+}
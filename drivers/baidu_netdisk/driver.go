@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"io"
+	"net/http"
 	"net/url"
 	"os"
 	stdpath "path"
@@ -22,6 +23,7 @@ import (
 	"github.com/alist-org/alist/v3/pkg/errgroup"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/avast/retry-go"
+	"github.com/go-resty/resty/v2"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -369,4 +371,18 @@ func (d *BaiduNetdisk) uploadSlice(ctx context.Context, params map[string]string
 	return nil
 }
 
+func (d *BaiduNetdisk) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var resp QuotaResp
+	_, err := d.request("https://pan.baidu.com/api/quota", http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx).SetQueryParams(map[string]string{
+			"checkfree":   "1",
+			"checkexpire": "1",
+		})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewStorageDetails(resp.Total, resp.Used), nil
+}
+
 var _ driver.Driver = (*BaiduNetdisk)(nil)
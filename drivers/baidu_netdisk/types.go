@@ -189,3 +189,11 @@ type PrecreateResp struct {
 	// return_type=2
 	File File `json:"info"`
 }
+
+type QuotaResp struct {
+	Errno  int   `json:"errno"`
+	Total  int64 `json:"total"`
+	Used   int64 `json:"used"`
+	Free   int64 `json:"free"`
+	Expire bool  `json:"expire"`
+}
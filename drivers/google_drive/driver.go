@@ -167,4 +167,27 @@ func (d *GoogleDrive) Put(ctx context.Context, dstDir model.Obj, stream model.Fi
 	return err
 }
 
+func (d *GoogleDrive) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var about About
+	_, err := d.request("https://www.googleapis.com/drive/v3/about", http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx).SetQueryParam("fields", "storageQuota")
+	}, &about)
+	if err != nil {
+		return nil, err
+	}
+	// the limit is not present if the storage is unlimited
+	if about.StorageQuota.Limit == "" {
+		return nil, errs.NotSupport
+	}
+	total, err := strconv.ParseInt(about.StorageQuota.Limit, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	used, err := strconv.ParseInt(about.StorageQuota.Usage, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewStorageDetails(total, used), nil
+}
+
 var _ driver.Driver = (*GoogleDrive)(nil)
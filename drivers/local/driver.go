@@ -3,7 +3,6 @@ package local
 import (
 	"bytes"
 	"context"
-	"errors"
 	"fmt"
 	"io/fs"
 	"net/http"
@@ -23,6 +22,7 @@ import (
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/times"
 	cp "github.com/otiai10/copy"
+	"github.com/shirou/gopsutil/v3/disk"
 	log "github.com/sirupsen/logrus"
 	_ "golang.org/x/image/webp"
 )
@@ -300,6 +300,31 @@ func (d *Local) Rename(ctx context.Context, srcObj model.Obj, newName string) er
 	return nil
 }
 
+func (d *Local) HardLink(_ context.Context, srcObj, dstObj model.Obj) error {
+	srcPath, dstPath := srcObj.GetPath(), dstObj.GetPath()
+	srcInfo, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+	dstInfo, err := os.Stat(dstPath)
+	if err != nil {
+		return err
+	}
+	if os.SameFile(srcInfo, dstInfo) {
+		return nil
+	}
+	// link to a temp file first, so that dst is replaced atomically
+	tmpPath := dstPath + ".alist-link"
+	if err = os.Link(srcPath, tmpPath); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, dstPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
 func (d *Local) Copy(_ context.Context, srcObj, dstDir model.Obj) error {
 	srcPath := srcObj.GetPath()
 	dstPath := filepath.Join(dstDir.GetPath(), srcObj.GetName())
@@ -311,6 +336,15 @@ func (d *Local) Copy(_ context.Context, srcObj, dstDir model.Obj) error {
 		Sync:          true, // Sync file to disk after copy, may have performance penalty in filesystem such as ZFS
 		PreserveTimes: true,
 		PreserveOwner: true,
+		// unlink the existing file instead of truncating it, it may be a hard link shared with other files
+		Skip: func(srcInfo fs.FileInfo, src, dest string) (bool, error) {
+			if srcInfo.Mode().IsRegular() {
+				if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+					return false, err
+				}
+			}
+			return false, nil
+		},
 	})
 }
 
@@ -337,25 +371,39 @@ func (d *Local) Remove(ctx context.Context, obj model.Obj) error {
 
 func (d *Local) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
 	fullPath := filepath.Join(dstDir.GetPath(), stream.GetName())
-	out, err := os.Create(fullPath)
+	// write to a temp file and rename it to the target, the existing file may be
+	// a hard link shared with other files, so it must not be truncated
+	tmpPath := fullPath + ".alist-upload-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	out, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o666)
 	if err != nil {
 		return err
 	}
 	defer func() {
 		_ = out.Close()
-		if errors.Is(err, context.Canceled) {
-			_ = os.Remove(fullPath)
+		if err != nil {
+			_ = os.Remove(tmpPath)
 		}
 	}()
 	err = utils.CopyWithCtx(ctx, out, stream, stream.GetSize(), up)
 	if err != nil {
 		return err
 	}
-	err = os.Chtimes(fullPath, stream.ModTime(), stream.ModTime())
-	if err != nil {
+	if err = out.Close(); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmpPath, stream.ModTime(), stream.ModTime()); err != nil {
 		log.Errorf("[local] failed to change time of %s: %s", fullPath, err)
 	}
-	return nil
+	err = os.Rename(tmpPath, fullPath)
+	return err
+}
+
+func (d *Local) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	usage, err := disk.UsageWithContext(ctx, d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	return model.NewStorageDetails(int64(usage.Total), int64(usage.Used)), nil
 }
 
 var _ driver.Driver = (*Local)(nil)
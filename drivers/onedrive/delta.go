@@ -0,0 +1,115 @@
+package onedrive
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+type deltaItem struct {
+	Id              string    `json:"id"`
+	Name            string    `json:"name"`
+	Root            *struct{} `json:"root"`
+	ParentReference struct {
+		Id   string `json:"id"`
+		Path string `json:"path"`
+	} `json:"parentReference"`
+}
+
+type deltaResp struct {
+	Value     []deltaItem `json:"value"`
+	NextLink  string      `json:"@odata.nextLink"`
+	DeltaLink string      `json:"@odata.deltaLink"`
+}
+
+// GetIndexChanges use the delta api, the cursor is the delta link
+// ApiDoc: https://learn.microsoft.com/en-us/onedrive/developer/rest-api/api/driveitem_delta
+func (d *Onedrive) GetIndexChanges(ctx context.Context, cursor string) ([]string, string, error) {
+	link := cursor
+	if link == "" {
+		link = d.GetDriveUrl() + "/root/delta?token=latest"
+	}
+	parents := make(map[string]struct{})
+	for {
+		var resp deltaResp
+		_, err := d.Request(link, http.MethodGet, func(req *resty.Request) {
+			req.SetContext(ctx)
+		}, &resp)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, item := range resp.Value {
+			if item.Root == nil && item.ParentReference.Id != "" {
+				parents[item.ParentReference.Id] = struct{}{}
+			}
+		}
+		if resp.NextLink == "" {
+			link = resp.DeltaLink
+			break
+		}
+		link = resp.NextLink
+	}
+	if cursor == "" {
+		return nil, link, nil
+	}
+	var dirs []string
+	for id := range parents {
+		dir, err := d.itemPath(ctx, id)
+		if err != nil {
+			// the parent may be deleted as well, which is reported by its own parent
+			utils.Log.Debugf("[Onedrive] failed get path of item %s: %+v", id, err)
+			continue
+		}
+		if dir, ok := d.relativePath(dir); ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, link, nil
+}
+
+// itemPath return the path of the item in the drive
+func (d *Onedrive) itemPath(ctx context.Context, id string) (string, error) {
+	var item deltaItem
+	_, err := d.Request(d.GetDriveUrl()+"/items/"+id+"?$select=id,name,root,parentReference", http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx)
+	}, &item)
+	if err != nil {
+		return "", err
+	}
+	if item.Root != nil {
+		return "/", nil
+	}
+	// the path of parent is like /drive/root:/a/b
+	parent := item.ParentReference.Path
+	if i := strings.Index(parent, "root:"); i != -1 {
+		parent = parent[i+len("root:"):]
+	}
+	// the path is percent-encoded
+	if unescaped, err := url.PathUnescape(parent); err == nil {
+		parent = unescaped
+	}
+	return stdpath.Join("/", parent, item.Name), nil
+}
+
+// relativePath convert the path in the drive to the path relative to the root folder
+func (d *Onedrive) relativePath(p string) (string, bool) {
+	root := utils.FixAndCleanPath(d.RootFolderPath)
+	if root == "/" {
+		return p, true
+	}
+	if p == root {
+		return "/", true
+	}
+	if strings.HasPrefix(p, root+"/") {
+		return strings.TrimPrefix(p, root), true
+	}
+	return "", false
+}
+
+var _ driver.IndexChanges = (*Onedrive)(nil)
@@ -7,6 +7,7 @@ import (
 	"hash"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/alist-org/alist/v3/drivers/base"
@@ -221,4 +222,27 @@ func (d *QuarkOrUC) Put(ctx context.Context, dstDir model.Obj, stream model.File
 	return d.upFinish(pre)
 }
 
+func (d *QuarkOrUC) PutHashTypes() []*utils.HashType {
+	return []*utils.HashType{utils.MD5, utils.SHA1}
+}
+
+func (d *QuarkOrUC) PutByHash(ctx context.Context, dstDir model.Obj, name string, size int64, hashes utils.HashInfo) (model.Obj, error) {
+	file := &streamPkg.FileStream{
+		Obj:      &model.Object{Name: name, Size: size},
+		Mimetype: utils.GetMimeType(name),
+	}
+	pre, err := d.upPre(file, dstDir.GetID())
+	if err != nil {
+		return nil, err
+	}
+	finish, err := d.upHash(strings.ToLower(hashes.GetHash(utils.MD5)), strings.ToLower(hashes.GetHash(utils.SHA1)), pre.Data.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	if !finish {
+		return nil, errs.RapidUploadMissed
+	}
+	return nil, nil
+}
+
 var _ driver.Driver = (*QuarkOrUC)(nil)
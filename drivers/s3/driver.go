@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/cron"
@@ -183,4 +184,9 @@ func (d *S3) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up
 	return err
 }
 
+// GetDetails s3 buckets have no fixed capacity
+func (d *S3) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	return nil, errs.NotSupport
+}
+
 var _ driver.Driver = (*S3)(nil)
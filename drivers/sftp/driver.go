@@ -115,4 +115,20 @@ func (d *SFTP) Put(ctx context.Context, dstDir model.Obj, stream model.FileStrea
 	return err
 }
 
+func (d *SFTP) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	if err := d.clientReconnectOnConnectionError(); err != nil {
+		return nil, err
+	}
+	stat, err := d.client.StatVFS(d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	total := int64(stat.Blocks * stat.Frsize)
+	return &model.StorageDetails{
+		TotalSpace: total,
+		UsedSpace:  total - int64(stat.Bfree*stat.Frsize),
+		FreeSpace:  int64(stat.Bavail * stat.Frsize),
+	}, nil
+}
+
 var _ driver.Driver = (*SFTP)(nil)
@@ -197,4 +197,23 @@ func (d *SMB) Put(ctx context.Context, dstDir model.Obj, stream model.FileStream
 //	return nil, errs.NotSupport
 //}
 
+func (d *SMB) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	if err := d.checkConn(); err != nil {
+		return nil, err
+	}
+	info, err := d.fs.Statfs(d.GetRootPath())
+	if err != nil {
+		d.cleanLastConnTime()
+		return nil, err
+	}
+	d.updateLastConnTime()
+	unit := int64(info.BlockSize() * info.FragmentSize())
+	total := int64(info.TotalBlockCount()) * unit
+	return &model.StorageDetails{
+		TotalSpace: total,
+		UsedSpace:  total - int64(info.FreeBlockCount())*unit,
+		FreeSpace:  int64(info.AvailableBlockCount()) * unit,
+	}, nil
+}
+
 var _ driver.Driver = (*SMB)(nil)
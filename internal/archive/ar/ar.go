@@ -0,0 +1,83 @@
+package ar
+
+import (
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+)
+
+type Ar struct {
+}
+
+func (Ar) AcceptedExtensions() []string {
+	return []string{".ar", ".deb"}
+}
+
+func (Ar) AcceptedMultipartExtensions() map[string]tool.MultipartExtension {
+	return map[string]tool.MultipartExtension{}
+}
+
+func (c Ar) GetMeta(ss []*stream.SeekableStream, args model.ArchiveArgs) (model.ArchiveMeta, error) {
+	index, err := c.BuildIndex(ss, args)
+	if err != nil {
+		return nil, err
+	}
+	return tool.GetMetaByIndex(index), nil
+}
+
+func (Ar) List(ss []*stream.SeekableStream, args model.ArchiveInnerArgs) ([]model.Obj, error) {
+	return nil, errs.NotSupport
+}
+
+func (c Ar) Extract(ss []*stream.SeekableStream, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	index, err := c.BuildIndex(ss, args.ArchiveArgs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tool.ExtractFromIndex(index, args.InnerPath, func(e *model.ArchiveIndexEntry) (io.ReadCloser, error) {
+		return c.ExtractByIndex(ss, index, e)
+	})
+}
+
+func (c Ar) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
+	index, err := c.BuildIndex(ss, args.ArchiveArgs)
+	if err != nil {
+		return err
+	}
+	return tool.DecompressFromFolderTraversal(&tool.IndexReader{
+		Index: index,
+		Open: func(e *model.ArchiveIndexEntry) (io.ReadCloser, error) {
+			return c.ExtractByIndex(ss, index, e)
+		},
+	}, outputPath, args, up)
+}
+
+func (Ar) CanIndex(name string) bool {
+	return true
+}
+
+func (Ar) BuildIndex(ss []*stream.SeekableStream, args model.ArchiveArgs) (*model.ArchiveIndex, error) {
+	ra, err := getReaderAt(ss[0])
+	if err != nil {
+		return nil, err
+	}
+	return buildIndex(ra, ss[0].GetSize())
+}
+
+func (Ar) ExtractByIndex(ss []*stream.SeekableStream, index *model.ArchiveIndex, entry *model.ArchiveIndexEntry) (io.ReadCloser, error) {
+	ra, err := getReaderAt(ss[0])
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(io.NewSectionReader(ra, entry.Offset, entry.Size)), nil
+}
+
+var _ tool.Tool = (*Ar)(nil)
+var _ tool.Indexer = (*Ar)(nil)
+
+func init() {
+	tool.RegisterTool(Ar{})
+}
@@ -0,0 +1,110 @@
+package ar
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/pkg/errors"
+)
+
+const (
+	globalHeader = "!<arch>\n"
+	headerLen    = 60
+	// the long names of the gnu variant are stored in the member named "//"
+	maxLongNamesSize = 16 << 20
+)
+
+func getReaderAt(ss *stream.SeekableStream) (io.ReaderAt, error) {
+	reader, err := stream.NewReadAtSeeker(ss, 0)
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := reader.(*stream.RangeReadReadAtSeeker); ok {
+		r.InitHeadCache()
+	}
+	return reader, nil
+}
+
+// buildIndex read the member headers of the common, gnu and bsd variants, e.g. .deb and .a files
+func buildIndex(ra io.ReaderAt, size int64) (*model.ArchiveIndex, error) {
+	magic := make([]byte, len(globalHeader))
+	if _, err := ra.ReadAt(magic, 0); err != nil || string(magic) != globalHeader {
+		return nil, errors.WithMessage(errs.UnknownArchiveFormat, "not an ar archive")
+	}
+	index := &model.ArchiveIndex{}
+	var longNames []byte
+	off := int64(len(globalHeader))
+	buf := make([]byte, headerLen)
+	for off+headerLen <= size {
+		if _, err := ra.ReadAt(buf, off); err != nil {
+			return nil, errors.WithMessage(err, "failed read ar header")
+		}
+		if string(buf[58:60]) != "`\n" {
+			return nil, errors.Errorf("invalid ar header at %d", off)
+		}
+		name := strings.TrimRight(string(buf[0:16]), " ")
+		mtime, _ := strconv.ParseInt(strings.TrimSpace(string(buf[16:28])), 10, 64)
+		memberSize, err := strconv.ParseInt(strings.TrimSpace(string(buf[48:58])), 10, 64)
+		if err != nil || memberSize < 0 {
+			return nil, errors.Errorf("invalid ar member size at %d", off)
+		}
+		dataOff, dataSize := off+headerLen, memberSize
+		switch {
+		case name == "//":
+			if memberSize > maxLongNamesSize {
+				return nil, errors.New("the long names table of ar is too large")
+			}
+			longNames = make([]byte, memberSize)
+			if _, err = ra.ReadAt(longNames, dataOff); err != nil {
+				return nil, errors.WithMessage(err, "failed read ar long names")
+			}
+			name = ""
+		case name == "/" || name == "/SYM64/" || strings.HasPrefix(name, "__.SYMDEF"):
+			// the symbol tables
+			name = ""
+		case strings.HasPrefix(name, "#1/"):
+			// bsd: the name is stored before the data
+			n, err := strconv.ParseInt(name[3:], 10, 64)
+			if err != nil || n < 0 || n > memberSize {
+				return nil, errors.Errorf("invalid ar bsd name at %d", off)
+			}
+			nameBuf := make([]byte, n)
+			if _, err = ra.ReadAt(nameBuf, dataOff); err != nil {
+				return nil, errors.WithMessage(err, "failed read ar name")
+			}
+			name = string(bytes.TrimRight(nameBuf, "\x00"))
+			dataOff += n
+			dataSize -= n
+		case strings.HasPrefix(name, "/"):
+			// gnu: the offset in the long names table
+			i, err := strconv.Atoi(name[1:])
+			if err != nil || i < 0 || i >= len(longNames) {
+				return nil, errors.Errorf("invalid ar long name at %d", off)
+			}
+			name = string(longNames[i:])
+			if end := strings.Index(name, "/\n"); end != -1 {
+				name = name[:end]
+			}
+		default:
+			name = strings.TrimSuffix(name, "/")
+		}
+		if name = tool.CleanEntryName(name); name != "" {
+			index.Entries = append(index.Entries, model.ArchiveIndexEntry{
+				Name:     name,
+				Size:     dataSize,
+				Modified: time.Unix(mtime, 0),
+				Offset:   dataOff,
+			})
+		}
+		// the members are aligned to 2 bytes
+		off += headerLen + memberSize + memberSize%2
+	}
+	return index, nil
+}
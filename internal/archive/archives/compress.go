@@ -0,0 +1,66 @@
+package archives
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	stderrors "errors"
+	"io"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func (Archives) CompressFormats() []string {
+	return []string{".tar", ".tar.gz", ".tgz"}
+}
+
+func (Archives) NewWriter(w io.Writer, args model.ArchiveCompressArgs) (tool.ArchiveWriter, error) {
+	if args.Password != "" {
+		return nil, errors.WithMessage(errs.NotSupport, "only zip archives can be encrypted")
+	}
+	tw := &tarWriter{}
+	if args.Format == ".tar" {
+		tw.Writer = tar.NewWriter(w)
+	} else {
+		tw.gw = gzip.NewWriter(w)
+		tw.Writer = tar.NewWriter(tw.gw)
+	}
+	return tw, nil
+}
+
+type tarWriter struct {
+	*tar.Writer
+	gw *gzip.Writer
+}
+
+func (w *tarWriter) Add(name string, obj model.Obj, r io.Reader) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    obj.GetSize(),
+		ModTime: obj.ModTime(),
+		Format:  tar.FormatPAX,
+	}
+	if obj.IsDir() {
+		hdr.Typeflag = tar.TypeDir
+		hdr.Name = strings.TrimSuffix(name, "/") + "/"
+		hdr.Mode = 0755
+		hdr.Size = 0
+	}
+	if err := w.WriteHeader(hdr); err != nil || obj.IsDir() {
+		return err
+	}
+	_, err := io.Copy(w.Writer, r)
+	return err
+}
+
+func (w *tarWriter) Close() error {
+	err := w.Writer.Close()
+	if w.gw != nil {
+		err = stderrors.Join(err, w.gw.Close())
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+package archives
+
+import (
+	"io"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/mholt/archives"
+)
+
+// the short extensions of the compressed tar archives
+var tarExtensions = []string{".tgz", ".taz", ".tbz", ".tbz2", ".tb2", ".txz", ".tlz", ".tlz4", ".tsz"}
+
+func (Archives) CanIndex(name string) bool {
+	name = strings.ToLower(name)
+	ext := stdpath.Ext(name)
+	return ext == ".tar" || stdpath.Ext(strings.TrimSuffix(name, ext)) == ".tar" || utils.SliceContains(tarExtensions, ext)
+}
+
+func (Archives) BuildIndex(ss []*stream.SeekableStream, args model.ArchiveArgs) (*model.ArchiveIndex, error) {
+	open, _, err := getTarStream(ss[0])
+	if err != nil {
+		return nil, err
+	}
+	rc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	entries, err := tool.IndexTar(rc)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ArchiveIndex{Entries: entries}, nil
+}
+
+func (Archives) ExtractByIndex(ss []*stream.SeekableStream, index *model.ArchiveIndex, entry *model.ArchiveIndexEntry) (io.ReadCloser, error) {
+	open, ra, err := getTarStream(ss[0])
+	if err != nil {
+		return nil, err
+	}
+	if ra != nil {
+		// the uncompressed tar is read randomly
+		return io.NopCloser(io.NewSectionReader(ra, entry.Offset, entry.Size)), nil
+	}
+	rc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	return tool.ExtractAt(rc, entry.Offset, entry.Size)
+}
+
+// getTarStream return the function opening the decompressed tar stream,
+// and the ReaderAt of the archive if it is not compressed
+func getTarStream(ss *stream.SeekableStream) (func() (io.ReadCloser, error), io.ReaderAt, error) {
+	reader, err := stream.NewReadAtSeeker(ss, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r, ok := reader.(*stream.RangeReadReadAtSeeker); ok {
+		r.InitHeadCache()
+	}
+	format, _, err := archives.Identify(ss.Ctx, ss.GetName(), reader)
+	if err != nil {
+		return nil, nil, errs.UnknownArchiveFormat
+	}
+	size := ss.GetSize()
+	switch f := format.(type) {
+	case archives.Tar:
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(io.NewSectionReader(reader, 0, size)), nil
+		}, reader, nil
+	case archives.CompressedArchive:
+		if _, ok := f.Archival.(archives.Tar); ok {
+			return func() (io.ReadCloser, error) {
+				return f.Compression.OpenReader(io.NewSectionReader(reader, 0, size))
+			}, nil, nil
+		}
+	}
+	return nil, nil, errs.NotSupport
+}
+
+var _ tool.Indexer = (*Archives)(nil)
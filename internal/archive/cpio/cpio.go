@@ -0,0 +1,83 @@
+package cpio
+
+import (
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+)
+
+type Cpio struct {
+}
+
+func (Cpio) AcceptedExtensions() []string {
+	return []string{".cpio"}
+}
+
+func (Cpio) AcceptedMultipartExtensions() map[string]tool.MultipartExtension {
+	return map[string]tool.MultipartExtension{}
+}
+
+func (c Cpio) GetMeta(ss []*stream.SeekableStream, args model.ArchiveArgs) (model.ArchiveMeta, error) {
+	index, err := c.BuildIndex(ss, args)
+	if err != nil {
+		return nil, err
+	}
+	return tool.GetMetaByIndex(index), nil
+}
+
+func (Cpio) List(ss []*stream.SeekableStream, args model.ArchiveInnerArgs) ([]model.Obj, error) {
+	return nil, errs.NotSupport
+}
+
+func (c Cpio) Extract(ss []*stream.SeekableStream, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	index, err := c.BuildIndex(ss, args.ArchiveArgs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tool.ExtractFromIndex(index, args.InnerPath, func(e *model.ArchiveIndexEntry) (io.ReadCloser, error) {
+		return c.ExtractByIndex(ss, index, e)
+	})
+}
+
+func (c Cpio) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
+	index, err := c.BuildIndex(ss, args.ArchiveArgs)
+	if err != nil {
+		return err
+	}
+	return tool.DecompressFromFolderTraversal(&tool.IndexReader{
+		Index: index,
+		Open: func(e *model.ArchiveIndexEntry) (io.ReadCloser, error) {
+			return c.ExtractByIndex(ss, index, e)
+		},
+	}, outputPath, args, up)
+}
+
+func (Cpio) CanIndex(name string) bool {
+	return true
+}
+
+func (Cpio) BuildIndex(ss []*stream.SeekableStream, args model.ArchiveArgs) (*model.ArchiveIndex, error) {
+	ra, err := getReaderAt(ss[0])
+	if err != nil {
+		return nil, err
+	}
+	return buildIndex(ra, ss[0].GetSize())
+}
+
+func (Cpio) ExtractByIndex(ss []*stream.SeekableStream, index *model.ArchiveIndex, entry *model.ArchiveIndexEntry) (io.ReadCloser, error) {
+	ra, err := getReaderAt(ss[0])
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(io.NewSectionReader(ra, entry.Offset, entry.Size)), nil
+}
+
+var _ tool.Tool = (*Cpio)(nil)
+var _ tool.Indexer = (*Cpio)(nil)
+
+func init() {
+	tool.RegisterTool(Cpio{})
+}
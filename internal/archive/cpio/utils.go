@@ -0,0 +1,152 @@
+package cpio
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/pkg/errors"
+)
+
+const (
+	newcHeaderLen = 110
+	odcHeaderLen  = 76
+	trailer       = "TRAILER!!!"
+	maxNameSize   = 1 << 16
+
+	modeType = 0170000
+	modeDir  = 0040000
+	modeReg  = 0100000
+)
+
+type header struct {
+	mode     int64
+	mtime    int64
+	size     int64
+	nameSize int64
+}
+
+func getReaderAt(ss *stream.SeekableStream) (io.ReaderAt, error) {
+	reader, err := stream.NewReadAtSeeker(ss, 0)
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := reader.(*stream.RangeReadReadAtSeeker); ok {
+		r.InitHeadCache()
+	}
+	return reader, nil
+}
+
+// buildIndex read the headers of the newc (070701), crc (070702) and odc (070707) formats,
+// the contents are skipped by the sizes, so only the headers are read
+func buildIndex(ra io.ReaderAt, size int64) (*model.ArchiveIndex, error) {
+	index := &model.ArchiveIndex{}
+	var off int64
+	magic := make([]byte, 6)
+	for off < size {
+		if _, err := ra.ReadAt(magic, off); err != nil {
+			return nil, errors.WithMessage(err, "failed read cpio header")
+		}
+		var (
+			h         *header
+			err       error
+			headerLen int64
+			align     int64
+		)
+		switch string(magic) {
+		case "070701", "070702":
+			headerLen, align = newcHeaderLen, 4
+			h, err = readNewcHeader(ra, off)
+		case "070707":
+			headerLen, align = odcHeaderLen, 1
+			h, err = readOdcHeader(ra, off)
+		default:
+			return nil, errors.WithMessagef(errs.UnknownArchiveFormat, "unsupported cpio magic %q", magic)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.nameSize <= 0 || h.nameSize > maxNameSize || h.size < 0 {
+			return nil, errors.Errorf("invalid cpio header at %d", off)
+		}
+		name := make([]byte, h.nameSize)
+		if _, err = ra.ReadAt(name, off+headerLen); err != nil {
+			return nil, errors.WithMessage(err, "failed read cpio name")
+		}
+		rawName := string(bytes.TrimRight(name, "\x00"))
+		if rawName == trailer {
+			break
+		}
+		dataOff := alignUp(off+headerLen+h.nameSize, align)
+		entryName := tool.CleanEntryName(rawName)
+		if entryName != "" {
+			switch h.mode & modeType {
+			case modeDir:
+				index.Entries = append(index.Entries, model.ArchiveIndexEntry{
+					Name:     entryName,
+					Modified: time.Unix(h.mtime, 0),
+					IsDir:    true,
+				})
+			case modeReg:
+				index.Entries = append(index.Entries, model.ArchiveIndexEntry{
+					Name:     entryName,
+					Size:     h.size,
+					Modified: time.Unix(h.mtime, 0),
+					Offset:   dataOff,
+				})
+			}
+		}
+		off = alignUp(dataOff+h.size, align)
+	}
+	return index, nil
+}
+
+func readNewcHeader(ra io.ReaderAt, off int64) (*header, error) {
+	buf := make([]byte, newcHeaderLen)
+	if _, err := ra.ReadAt(buf, off); err != nil {
+		return nil, errors.WithMessage(err, "failed read cpio header")
+	}
+	// the fields after the magic are 8 hex digits each
+	field := func(i int) (int64, error) {
+		return strconv.ParseInt(string(buf[6+i*8:6+(i+1)*8]), 16, 64)
+	}
+	var h header
+	var err error
+	for i, dst := range map[int]*int64{1: &h.mode, 5: &h.mtime, 6: &h.size, 11: &h.nameSize} {
+		if *dst, err = field(i); err != nil {
+			return nil, errors.WithMessage(err, "invalid cpio header")
+		}
+	}
+	return &h, nil
+}
+
+func readOdcHeader(ra io.ReaderAt, off int64) (*header, error) {
+	buf := make([]byte, odcHeaderLen)
+	if _, err := ra.ReadAt(buf, off); err != nil {
+		return nil, errors.WithMessage(err, "failed read cpio header")
+	}
+	// the fields are octal numbers: magic(6) dev(6) ino(6) mode(6) uid(6) gid(6) nlink(6) rdev(6) mtime(11) namesize(6) filesize(11)
+	field := func(start, end int) (int64, error) {
+		return strconv.ParseInt(string(buf[start:end]), 8, 64)
+	}
+	var h header
+	var err error
+	for _, f := range []struct {
+		dst        *int64
+		start, end int
+	}{{&h.mode, 18, 24}, {&h.mtime, 48, 59}, {&h.nameSize, 59, 65}, {&h.size, 65, 76}} {
+		if *f.dst, err = field(f.start, f.end); err != nil {
+			return nil, errors.WithMessage(err, "invalid cpio header")
+		}
+	}
+	return &h, nil
+}
+
+func alignUp(n, align int64) int64 {
+	return (n + align - 1) / align * align
+}
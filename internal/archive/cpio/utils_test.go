@@ -0,0 +1,53 @@
+package cpio
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func newcEntry(name string, mode int64, body string) []byte {
+	name += "\x00"
+	var b bytes.Buffer
+	b.WriteString("070701")
+	for _, v := range []int64{1, mode, 0, 0, 1, 1700000000, int64(len(body)), 0, 0, 0, 0, int64(len(name)), 0} {
+		b.WriteString(fmt.Sprintf("%08X", v))
+	}
+	b.WriteString(name)
+	b.Write(make([]byte, alignUp(int64(b.Len()), 4)-int64(b.Len())))
+	b.WriteString(body)
+	b.Write(make([]byte, alignUp(int64(b.Len()), 4)-int64(b.Len())))
+	return b.Bytes()
+}
+
+func odcEntry(name string, mode int64, body string) []byte {
+	name += "\x00"
+	return []byte(fmt.Sprintf("070707%06o%06o%06o%06o%06o%06o%06o%011o%06o%011o%s%s",
+		0, 1, mode, 0, 0, 1, 0, 1700000000, len(name), len(body), name, body))
+}
+
+func TestBuildIndex(t *testing.T) {
+	for format, entry := range map[string]func(string, int64, string) []byte{"newc": newcEntry, "odc": odcEntry} {
+		var archive []byte
+		archive = append(archive, entry("./dir", modeDir|0o755, "")...)
+		archive = append(archive, entry("dir/a.txt", modeReg|0o644, "hello")...)
+		archive = append(archive, entry("b.txt", modeReg|0o644, "world!")...)
+		archive = append(archive, entry(trailer, 0, "")...)
+		index, err := buildIndex(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			t.Fatalf("%s: %+v", format, err)
+		}
+		if len(index.Entries) != 3 {
+			t.Fatalf("%s: expect 3 entries, got %d", format, len(index.Entries))
+		}
+		if e := index.Entries[0]; e.Name != "dir" || !e.IsDir {
+			t.Errorf("%s: unexpected dir entry %+v", format, e)
+		}
+		for i, want := range []string{"hello", "world!"} {
+			e := index.Entries[i+1]
+			if got := string(archive[e.Offset : e.Offset+e.Size]); got != want {
+				t.Errorf("%s: expect content %q of %s, got %q", format, want, e.Name, got)
+			}
+		}
+	}
+}
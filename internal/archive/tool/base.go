@@ -19,3 +19,17 @@ type Tool interface {
 	Extract(ss []*stream.SeekableStream, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error)
 	Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error
 }
+
+// Compressor is implemented by the tools which can create archives
+type Compressor interface {
+	// CompressFormats return the extensions of the archives it creates
+	CompressFormats() []string
+	NewWriter(w io.Writer, args model.ArchiveCompressArgs) (ArchiveWriter, error)
+}
+
+// ArchiveWriter add the entries to the archive one by one
+type ArchiveWriter interface {
+	// Add write the obj as the entry of the slash separated name, the content of files is read from r
+	Add(name string, obj model.Obj, r io.Reader) error
+	Close() error
+}
@@ -0,0 +1,267 @@
+package tool
+
+import (
+	"archive/tar"
+	"io"
+	"io/fs"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+)
+
+// Indexer is implemented by the tools of the stream archives, which have to read the whole archive to list it.
+// The index is persisted by op, so that the later listing and extracting don't rescan the archive
+type Indexer interface {
+	// CanIndex tell whether the archive of the name is a stream archive by its name
+	CanIndex(name string) bool
+	// BuildIndex return errs.NotSupport if the content turns out not to be a stream archive
+	BuildIndex(ss []*stream.SeekableStream, args model.ArchiveArgs) (*model.ArchiveIndex, error)
+	// ExtractByIndex open the content of the file entry
+	ExtractByIndex(ss []*stream.SeekableStream, index *model.ArchiveIndex, entry *model.ArchiveIndexEntry) (io.ReadCloser, error)
+}
+
+// CleanEntryName convert the name in the archive to the slash separated relative path,
+// the names escaping the root are kept in the root
+func CleanEntryName(name string) string {
+	return strings.TrimPrefix(stdpath.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+}
+
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// IndexTar read the headers of the tar stream, and record the offsets of the contents in it
+func IndexTar(r io.Reader) ([]model.ArchiveIndexEntry, error) {
+	cr := &countReader{r: r}
+	tr := tar.NewReader(cr)
+	var entries []model.ArchiveIndexEntry
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := CleanEntryName(hdr.Name)
+		if name == "" || isSparse(hdr) {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			entries = append(entries, model.ArchiveIndexEntry{Name: name, Modified: hdr.ModTime, IsDir: true})
+		case tar.TypeReg:
+			entries = append(entries, model.ArchiveIndexEntry{
+				Name:     name,
+				Size:     hdr.Size,
+				Modified: hdr.ModTime,
+				// the tar reader doesn't read ahead, so the content begins right after the header
+				Offset: cr.n,
+			})
+		}
+	}
+}
+
+// the content of the sparse files is not continuous in the stream
+func isSparse(hdr *tar.Header) bool {
+	if hdr.Typeflag == tar.TypeGNUSparse {
+		return true
+	}
+	for k := range hdr.PAXRecords {
+		if strings.HasPrefix(k, "GNU.sparse.") {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractAt skip the bytes before the offset of the decompressed stream, and return the size of content after it,
+// the stream is closed with the returned reader
+func ExtractAt(rc io.ReadCloser, offset, size int64) (io.ReadCloser, error) {
+	if _, err := io.CopyN(io.Discard, rc, offset); err != nil {
+		_ = rc.Close()
+		return nil, err
+	}
+	return &limitedReadCloser{Reader: io.LimitReader(rc, size), Closer: rc}, nil
+}
+
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// FindIndexEntry return the entry of the inner path, the dirs only implied by the files are not found
+func FindIndexEntry(index *model.ArchiveIndex, innerPath string) (*model.ArchiveIndexEntry, error) {
+	name := CleanEntryName(innerPath)
+	for i := range index.Entries {
+		if index.Entries[i].Name == name {
+			return &index.Entries[i], nil
+		}
+	}
+	return nil, errs.ObjectNotFound
+}
+
+// SequentialOpener open the entries of a stream archive by reading the decompressed stream forward,
+// it only reopens the stream when an entry before the current position is requested,
+// so that opening the entries in the order of the offsets reads the stream once
+type SequentialOpener struct {
+	open func() (io.ReadCloser, error)
+	rc   io.ReadCloser
+	pos  int64
+}
+
+func NewSequentialOpener(open func() (io.ReadCloser, error)) *SequentialOpener {
+	return &SequentialOpener{open: open}
+}
+
+func (o *SequentialOpener) Open(e *model.ArchiveIndexEntry) (io.ReadCloser, error) {
+	if o.rc == nil || e.Offset < o.pos {
+		if err := o.Close(); err != nil {
+			return nil, err
+		}
+		rc, err := o.open()
+		if err != nil {
+			return nil, err
+		}
+		o.rc, o.pos = rc, 0
+	}
+	if _, err := io.CopyN(io.Discard, o.rc, e.Offset-o.pos); err != nil {
+		return nil, err
+	}
+	o.pos = e.Offset
+	return io.NopCloser(&sequentialReader{o: o, left: e.Size}), nil
+}
+
+func (o *SequentialOpener) Close() error {
+	if o.rc == nil {
+		return nil
+	}
+	err := o.rc.Close()
+	o.rc = nil
+	return err
+}
+
+type sequentialReader struct {
+	o    *SequentialOpener
+	left int64
+}
+
+func (r *sequentialReader) Read(p []byte) (int, error) {
+	if r.left <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.left {
+		p = p[:r.left]
+	}
+	n, err := r.o.rc.Read(p)
+	r.o.pos += int64(n)
+	r.left -= int64(n)
+	return n, err
+}
+
+// IndexReader adapt the entries of the index to ArchiveReader, so that the meta tree is generated and
+// the entries are decompressed as the other archives
+type IndexReader struct {
+	Index *model.ArchiveIndex
+	// Open is only required for decompressing
+	Open func(e *model.ArchiveIndexEntry) (io.ReadCloser, error)
+}
+
+func (r *IndexReader) Files() []SubFile {
+	files := make([]SubFile, 0, len(r.Index.Entries))
+	for i := range r.Index.Entries {
+		files = append(files, &indexSubFile{entry: &r.Index.Entries[i], open: r.Open})
+	}
+	return files
+}
+
+type indexSubFile struct {
+	entry *model.ArchiveIndexEntry
+	open  func(e *model.ArchiveIndexEntry) (io.ReadCloser, error)
+}
+
+func (f *indexSubFile) Name() string {
+	if f.entry.IsDir {
+		return f.entry.Name + "/"
+	}
+	return f.entry.Name
+}
+
+func (f *indexSubFile) FileInfo() fs.FileInfo {
+	return indexFileInfo{f.entry}
+}
+
+func (f *indexSubFile) Open() (io.ReadCloser, error) {
+	if f.open == nil {
+		return nil, errs.NotSupport
+	}
+	return f.open(f.entry)
+}
+
+type indexFileInfo struct {
+	entry *model.ArchiveIndexEntry
+}
+
+func (i indexFileInfo) Name() string {
+	return stdpath.Base(i.entry.Name)
+}
+
+func (i indexFileInfo) Size() int64 {
+	return i.entry.Size
+}
+
+func (i indexFileInfo) Mode() fs.FileMode {
+	if i.entry.IsDir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (i indexFileInfo) ModTime() time.Time {
+	return i.entry.Modified
+}
+
+func (i indexFileInfo) IsDir() bool {
+	return i.entry.IsDir
+}
+
+func (i indexFileInfo) Sys() any {
+	return nil
+}
+
+// GetMetaByIndex generate the meta of the archive from the index
+func GetMetaByIndex(index *model.ArchiveIndex) model.ArchiveMeta {
+	_, tree := GenerateMetaTreeFromFolderTraversal(&IndexReader{Index: index})
+	if tree == nil {
+		// a nil tree means the archive should be listed by the tool
+		tree = []model.ObjTree{}
+	}
+	return &model.ArchiveMetaInfo{Tree: tree}
+}
+
+// ExtractFromIndex open the file entry of the inner path
+func ExtractFromIndex(index *model.ArchiveIndex, innerPath string, open func(e *model.ArchiveIndexEntry) (io.ReadCloser, error)) (io.ReadCloser, int64, error) {
+	entry, err := FindIndexEntry(index, innerPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	if entry.IsDir {
+		return nil, 0, errs.NotFile
+	}
+	rc, err := open(entry)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rc, entry.Size, nil
+}
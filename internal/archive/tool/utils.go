@@ -7,6 +7,7 @@ import (
 var (
 	Tools               = make(map[string]Tool)
 	MultipartExtensions = make(map[string]MultipartExtension)
+	Compressors         = make(map[string]Compressor)
 )
 
 func RegisterTool(tool Tool) {
@@ -17,6 +18,11 @@ func RegisterTool(tool Tool) {
 		MultipartExtensions[mainFile] = ext
 		Tools[mainFile] = tool
 	}
+	if c, ok := tool.(Compressor); ok {
+		for _, format := range c.CompressFormats() {
+			Compressors[format] = c
+		}
+	}
 }
 
 func GetArchiveTool(ext string) (*MultipartExtension, Tool, error) {
@@ -30,3 +36,11 @@ func GetArchiveTool(ext string) (*MultipartExtension, Tool, error) {
 	}
 	return &partExt, t, nil
 }
+
+func GetCompressor(format string) (Compressor, error) {
+	c, ok := Compressors[format]
+	if !ok {
+		return nil, errs.UnknownArchiveFormat
+	}
+	return c, nil
+}
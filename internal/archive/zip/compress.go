@@ -0,0 +1,59 @@
+package zip
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/yeka/zip"
+)
+
+func (Zip) CompressFormats() []string {
+	return []string{".zip"}
+}
+
+func (Zip) NewWriter(w io.Writer, args model.ArchiveCompressArgs) (tool.ArchiveWriter, error) {
+	return &writer{Writer: zip.NewWriter(w), password: args.Password}, nil
+}
+
+type writer struct {
+	*zip.Writer
+	password string
+}
+
+func (w *writer) Add(name string, obj model.Obj, r io.Reader) error {
+	fh := &zip.FileHeader{
+		Name:   name,
+		Method: zip.Deflate,
+	}
+	if obj.IsDir() {
+		fh.Name = strings.TrimSuffix(name, "/") + "/"
+		fh.Method = zip.Store
+	}
+	fh.SetModTime(obj.ModTime())
+	// mark the names are encoded in UTF-8, so that they are not decoded by the local charset
+	if !isASCII(fh.Name) && utf8.ValidString(fh.Name) {
+		fh.Flags |= 0x800
+	}
+	if w.password != "" && !obj.IsDir() {
+		fh.SetPassword(w.password)
+		fh.SetEncryptionMethod(zip.AES256Encryption)
+	}
+	fw, err := w.CreateHeader(fh)
+	if err != nil || obj.IsDir() {
+		return err
+	}
+	_, err = io.Copy(fw, r)
+	return err
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package zstd
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// the seek table of the seekable format is stored in a skippable frame at the end of the archive,
+// see https://github.com/facebook/zstd/blob/dev/contrib/seekable_format/zstd_seekable_compression_format.md
+const (
+	seekTableFooterLen   = 9
+	seekTableMagic       = 0x8F92EAB1
+	skippableFrameMagic  = 0x184D2A5E
+	skippableFrameHeader = 8
+	checksumFlag         = 1 << 7
+	// avoid reading a huge seek table of a broken archive
+	maxFrames = 1 << 20
+)
+
+func getReaderAt(ss *stream.SeekableStream) (io.ReaderAt, error) {
+	reader, err := stream.NewReadAtSeeker(ss, 0)
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := reader.(*stream.RangeReadReadAtSeeker); ok {
+		r.InitHeadCache()
+	}
+	return reader, nil
+}
+
+func newDecoder(r io.Reader) (io.ReadCloser, error) {
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return dec.IOReadCloser(), nil
+}
+
+// readSeekTable return the frames of the seekable archive, nil is returned if the archive is not seekable
+func readSeekTable(ra io.ReaderAt, size int64) ([]model.ArchiveIndexFrame, error) {
+	if size < skippableFrameHeader+seekTableFooterLen {
+		return nil, nil
+	}
+	footer := make([]byte, seekTableFooterLen)
+	if _, err := ra.ReadAt(footer, size-seekTableFooterLen); err != nil {
+		return nil, errors.WithMessage(err, "failed read zstd seek table")
+	}
+	if binary.LittleEndian.Uint32(footer[5:9]) != seekTableMagic {
+		return nil, nil
+	}
+	n := int64(binary.LittleEndian.Uint32(footer[0:4]))
+	entryLen := int64(8)
+	if footer[4]&checksumFlag != 0 {
+		entryLen = 12
+	}
+	tableLen := n*entryLen + seekTableFooterLen
+	if n > maxFrames || tableLen+skippableFrameHeader > size {
+		return nil, errors.New("invalid zstd seek table")
+	}
+	table := make([]byte, skippableFrameHeader+tableLen)
+	if _, err := ra.ReadAt(table, size-int64(len(table))); err != nil {
+		return nil, errors.WithMessage(err, "failed read zstd seek table")
+	}
+	if binary.LittleEndian.Uint32(table[0:4]) != skippableFrameMagic ||
+		int64(binary.LittleEndian.Uint32(table[4:8])) != tableLen {
+		return nil, errors.New("invalid zstd seek table")
+	}
+	frames := make([]model.ArchiveIndexFrame, 0, n)
+	var c, d int64
+	for i := int64(0); i < n; i++ {
+		entry := table[skippableFrameHeader+i*entryLen:]
+		frames = append(frames, model.ArchiveIndexFrame{CompressedOffset: c, DecompressedOffset: d})
+		c += int64(binary.LittleEndian.Uint32(entry[0:4]))
+		d += int64(binary.LittleEndian.Uint32(entry[4:8]))
+	}
+	return frames, nil
+}
+
+// findFrame return the last frame starting before the offset
+func findFrame(frames []model.ArchiveIndexFrame, offset int64) *model.ArchiveIndexFrame {
+	var frame *model.ArchiveIndexFrame
+	for i := range frames {
+		if frames[i].DecompressedOffset > offset {
+			break
+		}
+		frame = &frames[i]
+	}
+	return frame
+}
@@ -0,0 +1,118 @@
+package zstd
+
+import (
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+)
+
+// Zstd read the tar archives compressed by zstd, the entries of the seekable archives are
+// decompressed from the frame containing them instead of the beginning
+type Zstd struct {
+}
+
+func (Zstd) AcceptedExtensions() []string {
+	return []string{".tar.zst", ".tzst"}
+}
+
+func (Zstd) AcceptedMultipartExtensions() map[string]tool.MultipartExtension {
+	return map[string]tool.MultipartExtension{}
+}
+
+func (z Zstd) GetMeta(ss []*stream.SeekableStream, args model.ArchiveArgs) (model.ArchiveMeta, error) {
+	index, err := z.BuildIndex(ss, args)
+	if err != nil {
+		return nil, err
+	}
+	return tool.GetMetaByIndex(index), nil
+}
+
+func (Zstd) List(ss []*stream.SeekableStream, args model.ArchiveInnerArgs) ([]model.Obj, error) {
+	return nil, errs.NotSupport
+}
+
+func (z Zstd) Extract(ss []*stream.SeekableStream, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	index, err := z.BuildIndex(ss, args.ArchiveArgs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tool.ExtractFromIndex(index, args.InnerPath, func(e *model.ArchiveIndexEntry) (io.ReadCloser, error) {
+		return z.ExtractByIndex(ss, index, e)
+	})
+}
+
+func (z Zstd) Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
+	index, err := z.BuildIndex(ss, args.ArchiveArgs)
+	if err != nil {
+		return err
+	}
+	open := func(e *model.ArchiveIndexEntry) (io.ReadCloser, error) {
+		return z.ExtractByIndex(ss, index, e)
+	}
+	if len(index.Frames) == 0 {
+		// read the stream once instead of decompressing from the beginning for each entry
+		ra, err := getReaderAt(ss[0])
+		if err != nil {
+			return err
+		}
+		opener := tool.NewSequentialOpener(func() (io.ReadCloser, error) {
+			return newDecoder(io.NewSectionReader(ra, 0, ss[0].GetSize()))
+		})
+		defer opener.Close()
+		open = opener.Open
+	}
+	return tool.DecompressFromFolderTraversal(&tool.IndexReader{Index: index, Open: open}, outputPath, args, up)
+}
+
+func (Zstd) CanIndex(name string) bool {
+	return true
+}
+
+func (Zstd) BuildIndex(ss []*stream.SeekableStream, args model.ArchiveArgs) (*model.ArchiveIndex, error) {
+	ra, err := getReaderAt(ss[0])
+	if err != nil {
+		return nil, err
+	}
+	size := ss[0].GetSize()
+	frames, err := readSeekTable(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := newDecoder(io.NewSectionReader(ra, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	entries, err := tool.IndexTar(rc)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ArchiveIndex{Entries: entries, Frames: frames}, nil
+}
+
+func (Zstd) ExtractByIndex(ss []*stream.SeekableStream, index *model.ArchiveIndex, entry *model.ArchiveIndexEntry) (io.ReadCloser, error) {
+	ra, err := getReaderAt(ss[0])
+	if err != nil {
+		return nil, err
+	}
+	size := ss[0].GetSize()
+	var start, skip int64 = 0, entry.Offset
+	if frame := findFrame(index.Frames, entry.Offset); frame != nil {
+		start, skip = frame.CompressedOffset, entry.Offset-frame.DecompressedOffset
+	}
+	rc, err := newDecoder(io.NewSectionReader(ra, start, size-start))
+	if err != nil {
+		return nil, err
+	}
+	return tool.ExtractAt(rc, skip, entry.Size)
+}
+
+var _ tool.Tool = (*Zstd)(nil)
+var _ tool.Indexer = (*Zstd)(nil)
+
+func init() {
+	tool.RegisterTool(Zstd{})
+}
@@ -0,0 +1,21 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	log "github.com/sirupsen/logrus"
+)
+
+var auditCron *cron.Cron
+
+// InitAuditLog delete the expired audit logs daily
+func InitAuditLog() {
+	auditCron = cron.NewCron(24 * time.Hour)
+	auditCron.Do(func() {
+		if err := fs.PruneAuditLogs(); err != nil {
+			log.Errorf("failed prune audit logs: %+v", err)
+		}
+	})
+}
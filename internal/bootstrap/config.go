@@ -117,6 +117,10 @@ func CleanTempDir() {
 		log.Errorln("failed list temp file: ", err)
 	}
 	for _, file := range files {
+		// the resumable uploads are kept until they expire
+		if file.Name() == conf.UploadSessionDirName || file.Name() == conf.S3MultipartDirName {
+			continue
+		}
 		if err := os.RemoveAll(filepath.Join(conf.Conf.TempDir, file.Name())); err != nil {
 			log.Errorln("failed delete temp file: ", err)
 		}
@@ -146,6 +146,12 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.PreviewArchivesByDefault, Value: "true", Type: conf.TypeBool, Group: model.PREVIEW},
 		{Key: conf.ReadMeAutoRender, Value: "true", Type: conf.TypeBool, Group: model.PREVIEW},
 		{Key: conf.FilterReadMeScripts, Value: "true", Type: conf.TypeBool, Group: model.PREVIEW},
+		{Key: conf.ThumbnailEnabled, Value: "true", Type: conf.TypeBool, Group: model.PREVIEW, Flag: model.PRIVATE},
+		{Key: conf.ThumbnailSize, Value: "256", Type: conf.TypeNumber, Group: model.PREVIEW, Flag: model.PRIVATE},
+		{Key: conf.ThumbnailMaxFileSize, Value: "20", Type: conf.TypeNumber, Group: model.PREVIEW, Flag: model.PRIVATE, Help: `the images larger than it (in MB) get no thumbnails`},
+		{Key: conf.ThumbnailCacheSize, Value: "512", Type: conf.TypeNumber, Group: model.PREVIEW, Flag: model.PRIVATE, Help: `the least recently used thumbnails are removed once the cache (in MB) exceeds it`},
+		{Key: conf.MediaInfoEnabled, Value: "false", Type: conf.TypeBool, Group: model.PREVIEW, Flag: model.PRIVATE, Help: `read the exif, tags, duration and resolution of the media files for the file info`},
+		{Key: conf.MediaInfoPublicGPS, Value: "false", Type: conf.TypeBool, Group: model.PREVIEW, Flag: model.PRIVATE, Help: `show the gps location in the media info to the users who can't write the file`},
 		// global settings
 		{Key: conf.HideFiles, Value: "/\\/README.md/i", Type: conf.TypeText, Group: model.GLOBAL},
 		{Key: "package_download", Value: "true", Type: conf.TypeBool, Group: model.GLOBAL},
@@ -162,6 +168,14 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.ForwardDirectLinkParams, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL},
 		{Key: conf.IgnoreDirectLinkParams, Value: "sign,alist_ts", Type: conf.TypeString, Group: model.GLOBAL},
 		{Key: conf.WebauthnLoginEnabled, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PUBLIC},
+		{Key: conf.TrashEnabled, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `move removed objects to the trash instead of deleting them`},
+		{Key: conf.TrashRetentionDays, Value: "30", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `purge the objects in the trash after days, 0 means keep forever`},
+		{Key: conf.WebhookMaxRetries, Value: "3", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `retry the failed webhook deliveries with exponential backoff`},
+		{Key: conf.WebhookRetentionDays, Value: "7", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `delete the webhook delivery logs after days, 0 means keep forever`},
+		{Key: conf.AuditLogEnabled, Value: "true", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `record the file operations of users from all protocols`},
+		{Key: conf.AuditLogRetentionDays, Value: "90", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `delete the audit logs after days, 0 means keep forever`},
+		{Key: conf.UploadSessionExpiry, Value: "24", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `delete the resumable uploads which are not written for hours, 0 means keep forever`},
+		{Key: conf.WebdavLockSystem, Value: "database", Type: conf.TypeSelect, Options: "database,memory", Group: model.GLOBAL, Flag: model.PRIVATE, Help: `where the webdav locks are kept, the locks in memory are lost on restarting and not shared by the instances, take effect after restarting`},
 
 		// single settings
 		{Key: conf.Token, Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE},
@@ -169,6 +183,9 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.AutoUpdateIndex, Value: "false", Type: conf.TypeBool, Group: model.INDEX},
 		{Key: conf.IgnorePaths, Value: "", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line`},
 		{Key: conf.MaxIndexDepth, Value: "20", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `max depth of index`},
+		{Key: conf.SearchContentMaxSize, Value: "10", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `the files larger than it (in MB) are indexed without content`},
+		{Key: conf.SearchContentExts, Value: "txt,md,markdown,rst,log,csv,json,yaml,yml,toml,ini,xml,html,htm,css,js,ts,go,py,java,c,h,cpp,hpp,cs,rs,rb,php,sh,sql,pdf,docx,xlsx,pptx", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `the extensions of the files whose content will be indexed, separated by commas`},
+		{Key: conf.SearchMediaInfo, Value: "false", Type: conf.TypeBool, Group: model.INDEX, Flag: model.PRIVATE, Help: `index the resolution, duration and taken time of the media files for the filters`},
 		{Key: conf.IndexProgress, Value: "{}", Type: conf.TypeText, Group: model.SINGLE, Flag: model.PRIVATE},
 
 		// SSO settings
@@ -220,6 +237,9 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.TaskCopyThreadsNum, Value: strconv.Itoa(conf.Conf.Tasks.Copy.Workers), Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
 		{Key: conf.TaskDecompressDownloadThreadsNum, Value: strconv.Itoa(conf.Conf.Tasks.Decompress.Workers), Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
 		{Key: conf.TaskDecompressUploadThreadsNum, Value: strconv.Itoa(conf.Conf.Tasks.DecompressUpload.Workers), Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
+		{Key: conf.TaskSyncThreadsNum, Value: strconv.Itoa(conf.Conf.Tasks.Sync.Workers), Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
+		{Key: conf.TaskDedupThreadsNum, Value: strconv.Itoa(conf.Conf.Tasks.Dedup.Workers), Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
+		{Key: conf.TaskCompressThreadsNum, Value: strconv.Itoa(conf.Conf.Tasks.Compress.Workers), Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
 		{Key: conf.StreamMaxClientDownloadSpeed, Value: "-1", Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
 		{Key: conf.StreamMaxClientUploadSpeed, Value: "-1", Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
 		{Key: conf.StreamMaxServerDownloadSpeed, Value: "-1", Type: conf.TypeNumber, Group: model.TRAFFIC, Flag: model.PRIVATE},
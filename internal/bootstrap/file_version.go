@@ -0,0 +1,19 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+var fileVersionCron *cron.Cron
+
+// InitFileVersions prune the expired file versions periodically
+func InitFileVersions() {
+	fileVersionCron = cron.NewCron(time.Hour * 6)
+	fileVersionCron.Do(func() {
+		op.PruneExpiredFileVersions(context.Background())
+	})
+}
@@ -6,6 +6,8 @@ import (
 )
 
 func InitIndex() {
+	// the progress written by the searcher init may be overwritten by the initial settings
+	search.CheckVersion()
 	progress, err := search.Progress()
 	if err != nil {
 		log.Errorf("init index error: %+v", err)
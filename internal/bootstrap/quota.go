@@ -0,0 +1,28 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	log "github.com/sirupsen/logrus"
+)
+
+var quotaCron *cron.Cron
+
+// InitQuota reconcile the usage of the new quota paths every 10 minutes,
+// and the usage of all quota paths every 12 hours
+func InitQuota() {
+	if err := op.SyncQuotaPaths(); err != nil {
+		log.Errorf("failed sync quota paths: %+v", err)
+	}
+	go fs.ReconcileQuotaUsage(context.Background(), true)
+	var ticks int
+	quotaCron = cron.NewCron(time.Minute * 10)
+	quotaCron.Do(func() {
+		ticks++
+		fs.ReconcileQuotaUsage(context.Background(), ticks%72 == 0)
+	})
+}
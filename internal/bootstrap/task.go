@@ -41,6 +41,18 @@ func InitTaskManager() {
 	op.RegisterSettingChangingCallback(func() {
 		fs.ArchiveDownloadTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskDecompressDownloadThreadsNum, conf.Conf.Tasks.Decompress.Workers)))
 	})
+	fs.SyncTaskManager = tache.NewManager[*fs.SyncTask](tache.WithWorks(setting.GetInt(conf.TaskSyncThreadsNum, conf.Conf.Tasks.Sync.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc("sync", conf.Conf.Tasks.Sync.TaskPersistant), db.UpdateTaskDataFunc("sync", conf.Conf.Tasks.Sync.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Sync.MaxRetry))
+	op.RegisterSettingChangingCallback(func() {
+		fs.SyncTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskSyncThreadsNum, conf.Conf.Tasks.Sync.Workers)))
+	})
+	fs.DedupTaskManager = tache.NewManager[*fs.DedupTask](tache.WithWorks(setting.GetInt(conf.TaskDedupThreadsNum, conf.Conf.Tasks.Dedup.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc("dedup", conf.Conf.Tasks.Dedup.TaskPersistant), db.UpdateTaskDataFunc("dedup", conf.Conf.Tasks.Dedup.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Dedup.MaxRetry))
+	op.RegisterSettingChangingCallback(func() {
+		fs.DedupTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskDedupThreadsNum, conf.Conf.Tasks.Dedup.Workers)))
+	})
+	fs.ArchiveCompressTaskManager = tache.NewManager[*fs.ArchiveCompressTask](tache.WithWorks(setting.GetInt(conf.TaskCompressThreadsNum, conf.Conf.Tasks.Compress.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc("compress", conf.Conf.Tasks.Compress.TaskPersistant), db.UpdateTaskDataFunc("compress", conf.Conf.Tasks.Compress.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Compress.MaxRetry))
+	op.RegisterSettingChangingCallback(func() {
+		fs.ArchiveCompressTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskCompressThreadsNum, conf.Conf.Tasks.Compress.Workers)))
+	})
 	fs.ArchiveContentUploadTaskManager.Manager = tache.NewManager[*fs.ArchiveContentUploadTask](tache.WithWorks(setting.GetInt(conf.TaskDecompressUploadThreadsNum, conf.Conf.Tasks.DecompressUpload.Workers)), tache.WithMaxRetry(conf.Conf.Tasks.DecompressUpload.MaxRetry)) //decompress upload will not support persist
 	op.RegisterSettingChangingCallback(func() {
 		fs.ArchiveContentUploadTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskDecompressUploadThreadsNum, conf.Conf.Tasks.DecompressUpload.Workers)))
@@ -0,0 +1,19 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+var trashCron *cron.Cron
+
+// InitTrash purge the expired objects in the trash periodically
+func InitTrash() {
+	trashCron = cron.NewCron(time.Hour)
+	trashCron.Do(func() {
+		fs.PurgeExpiredTrash(context.Background())
+	})
+}
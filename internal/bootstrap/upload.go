@@ -0,0 +1,16 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+var uploadSessionCron *cron.Cron
+
+// InitUploadSessions purge the expired resumable uploads periodically
+func InitUploadSessions() {
+	uploadSessionCron = cron.NewCron(time.Hour)
+	uploadSessionCron.Do(fs.PurgeExpiredUploadSessions)
+}
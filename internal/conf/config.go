@@ -60,6 +60,9 @@ type TasksConfig struct {
 	Copy               TaskConfig `json:"copy" envPrefix:"COPY_"`
 	Decompress         TaskConfig `json:"decompress" envPrefix:"DECOMPRESS_"`
 	DecompressUpload   TaskConfig `json:"decompress_upload" envPrefix:"DECOMPRESS_UPLOAD_"`
+	Sync               TaskConfig `json:"sync" envPrefix:"SYNC_"`
+	Dedup              TaskConfig `json:"dedup" envPrefix:"DEDUP_"`
+	Compress           TaskConfig `json:"compress" envPrefix:"COMPRESS_"`
 	AllowRetryCanceled bool       `json:"allow_retry_canceled" env:"ALLOW_RETRY_CANCELED"`
 }
 
@@ -104,6 +107,8 @@ type Config struct {
 	Scheme                Scheme      `json:"scheme"`
 	TempDir               string      `json:"temp_dir" env:"TEMP_DIR"`
 	BleveDir              string      `json:"bleve_dir" env:"BLEVE_DIR"`
+	ArchiveIndexDir       string      `json:"archive_index_dir" env:"ARCHIVE_INDEX_DIR"`
+	ThumbnailDir          string      `json:"thumbnail_dir" env:"THUMBNAIL_DIR"`
 	DistDir               string      `json:"dist_dir"`
 	Log                   LogConfig   `json:"log"`
 	DelayedStart          int         `json:"delayed_start" env:"DELAYED_START"`
@@ -121,6 +126,8 @@ type Config struct {
 func DefaultConfig() *Config {
 	tempDir := filepath.Join(flags.DataDir, "temp")
 	indexDir := filepath.Join(flags.DataDir, "bleve")
+	archiveIndexDir := filepath.Join(flags.DataDir, "archive_index")
+	thumbnailDir := filepath.Join(flags.DataDir, "thumbnail")
 	logPath := filepath.Join(flags.DataDir, "log/log.log")
 	dbPath := filepath.Join(flags.DataDir, "data.db")
 	return &Config{
@@ -145,7 +152,9 @@ func DefaultConfig() *Config {
 		Meilisearch: Meilisearch{
 			Host: "http://localhost:7700",
 		},
-		BleveDir: indexDir,
+		BleveDir:        indexDir,
+		ArchiveIndexDir: archiveIndexDir,
+		ThumbnailDir:    thumbnailDir,
 		Log: LogConfig{
 			Enable:     true,
 			Name:       logPath,
@@ -184,6 +193,21 @@ func DefaultConfig() *Config {
 				Workers:  5,
 				MaxRetry: 2,
 			},
+			Sync: TaskConfig{
+				Workers:  1,
+				MaxRetry: 1,
+				// TaskPersistant: true,
+			},
+			Dedup: TaskConfig{
+				Workers:  1,
+				MaxRetry: 0,
+				// TaskPersistant: true,
+			},
+			Compress: TaskConfig{
+				Workers:  1,
+				MaxRetry: 0,
+				// TaskPersistant: true,
+			},
 			AllowRetryCanceled: false,
 		},
 		Cors: Cors{
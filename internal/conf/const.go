@@ -34,6 +34,12 @@ const (
 	PreviewArchivesByDefault = "preview_archives_by_default"
 	ReadMeAutoRender         = "readme_autorender"
 	FilterReadMeScripts      = "filter_readme_scripts"
+	ThumbnailEnabled         = "thumbnail_enabled"
+	ThumbnailSize            = "thumbnail_size"
+	ThumbnailMaxFileSize     = "thumbnail_max_file_size"
+	ThumbnailCacheSize       = "thumbnail_cache_size"
+	MediaInfoEnabled         = "media_info_enabled"
+	MediaInfoPublicGPS       = "media_info_public_gps"
 	// global
 	HideFiles               = "hide_files"
 	CustomizeHead           = "customize_head"
@@ -46,12 +52,25 @@ const (
 	ForwardDirectLinkParams = "forward_direct_link_params"
 	IgnoreDirectLinkParams  = "ignore_direct_link_params"
 	WebauthnLoginEnabled    = "webauthn_login_enabled"
+	TrashEnabled            = "trash_enabled"
+	TrashRetentionDays      = "trash_retention_days"
+	WebhookMaxRetries       = "webhook_max_retries"
+	WebhookRetentionDays    = "webhook_retention_days"
+	AuditLogEnabled         = "audit_log_enabled"
+	AuditLogRetentionDays   = "audit_log_retention_days"
+	UploadSessionExpiry     = "upload_session_expiry"
+	WebdavLockSystem        = "webdav_lock_system"
 
 	// index
 	SearchIndex     = "search_index"
 	AutoUpdateIndex = "auto_update_index"
 	IgnorePaths     = "ignore_paths"
 	MaxIndexDepth   = "max_index_depth"
+	// content index
+	SearchContentMaxSize = "search_content_max_size"
+	SearchContentExts    = "search_content_exts"
+	// media info index
+	SearchMediaInfo = "search_media_info"
 
 	// aria2
 	Aria2Uri    = "aria2_uri"
@@ -126,6 +145,9 @@ const (
 	TaskCopyThreadsNum                    = "copy_task_threads_num"
 	TaskDecompressDownloadThreadsNum      = "decompress_download_task_threads_num"
 	TaskDecompressUploadThreadsNum        = "decompress_upload_task_threads_num"
+	TaskSyncThreadsNum                    = "sync_task_threads_num"
+	TaskDedupThreadsNum                   = "dedup_task_threads_num"
+	TaskCompressThreadsNum                = "compress_task_threads_num"
 	StreamMaxClientDownloadSpeed          = "max_client_download_speed"
 	StreamMaxClientUploadSpeed            = "max_client_upload_speed"
 	StreamMaxServerDownloadSpeed          = "max_server_download_speed"
@@ -144,5 +166,21 @@ const (
 
 // ContextKey is the type of context keys.
 const (
-	NoTaskKey = "no_task"
+	NoTaskKey   = "no_task"
+	NoTrashKey  = "no_trash"
+	ProtocolKey = "protocol"
+	ClientIPKey = "client_ip"
+	// HiddenKey allows the trash and versions dirs to be accessed, by their own api
+	HiddenKey = "hidden"
+)
+
+const (
+	// TrashDirName is the dir at the root of each storage where the removed objects are kept
+	TrashDirName = ".alist-trash"
+	// VersionsDirName is the dir at the root of each storage where the overwritten contents are kept
+	VersionsDirName = ".alist-versions"
+	// UploadSessionDirName is the dir in the temp dir where the data of the resumable uploads are kept
+	UploadSessionDirName = "upload-sessions"
+	// S3MultipartDirName is the dir in the temp dir where the parts of the s3 multipart uploads are kept
+	S3MultipartDirName = "s3-multipart"
 )
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func CreateAuditLogs(logs []model.AuditLog) error {
+	return errors.WithStack(db.CreateInBatches(logs, 100).Error)
+}
+
+func auditLogDB(q model.AuditLogQuery) *gorm.DB {
+	logDB := db.Model(&model.AuditLog{})
+	if q.Username != "" {
+		logDB = logDB.Where(columnName("username")+" = ?", q.Username)
+	}
+	if q.Protocol != "" {
+		logDB = logDB.Where(columnName("protocol")+" = ?", q.Protocol)
+	}
+	if q.Action != "" {
+		logDB = logDB.Where(columnName("action")+" = ?", q.Action)
+	}
+	if q.Path != "" {
+		logDB = logDB.Where("("+columnName("src_path")+" LIKE ? OR "+columnName("dst_path")+" LIKE ?)", q.Path+"%", q.Path+"%")
+	}
+	if q.IP != "" {
+		logDB = logDB.Where(columnName("ip")+" = ?", q.IP)
+	}
+	if q.Success != nil {
+		logDB = logDB.Where(columnName("success")+" = ?", *q.Success)
+	}
+	if q.Start != nil {
+		logDB = logDB.Where(columnName("time")+" >= ?", *q.Start)
+	}
+	if q.End != nil {
+		logDB = logDB.Where(columnName("time")+" < ?", *q.End)
+	}
+	return logDB
+}
+
+func GetAuditLogs(q model.AuditLogQuery, pageIndex, pageSize int) (logs []model.AuditLog, count int64, err error) {
+	logDB := auditLogDB(q)
+	if err := logDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get audit logs count")
+	}
+	if err := logDB.Order(columnName("id") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find audit logs")
+	}
+	return logs, count, nil
+}
+
+// WalkAuditLogs call fn with the matched logs in batches, oldest first
+func WalkAuditLogs(q model.AuditLogQuery, fn func([]model.AuditLog) error) error {
+	var logs []model.AuditLog
+	return errors.WithStack(auditLogDB(q).FindInBatches(&logs, 500, func(tx *gorm.DB, batch int) error {
+		return fn(logs)
+	}).Error)
+}
+
+func DeleteAuditLogsBefore(t time.Time) error {
+	return errors.WithStack(db.Where(columnName("time")+" < ?", t).Delete(&model.AuditLog{}).Error)
+}
@@ -12,7 +12,7 @@ var db *gorm.DB
 
 func Init(d *gorm.DB) {
 	db = d
-	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SSHPublicKey), new(model.Role), new(model.Label), new(model.LabelFileBinDing), new(model.ObjFile))
+	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SSHPublicKey), new(model.Role), new(model.Label), new(model.LabelFileBinDing), new(model.ObjFile), new(model.Share), new(model.ShareAccessLog), new(model.TrashItem), new(model.FileVersion), new(model.ScheduledJob), new(model.ScheduledJobRun), new(model.Webhook), new(model.WebhookDelivery), new(model.QuotaUsage), new(model.AuditLog), new(model.FileHash), new(model.DirFingerprint), new(model.MediaMeta), new(model.UploadSession), new(model.S3AccessKey), new(model.S3MultipartUpload), new(model.S3MultipartPart), new(model.S3ObjectMeta), new(model.WebdavLock), new(model.ObjProp))
 	if err != nil {
 		log.Fatalf("failed migrate database: %s", err.Error())
 	}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// fileHashUnder limit the query to the files under the dir of the mount path
+func fileHashUnder(tx *gorm.DB, dirPath string) *gorm.DB {
+	dirPath = utils.FixAndCleanPath(dirPath)
+	if dirPath == "/" {
+		return tx
+	}
+	return tx.Where(subPathsCond("path", dirPath))
+}
+
+func GetFileHashesUnder(dirPath string) ([]model.FileHash, error) {
+	var hashes []model.FileHash
+	if err := fileHashUnder(db, dirPath).Find(&hashes).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find file hashes")
+	}
+	return hashes, nil
+}
+
+func SaveFileHash(h *model.FileHash) error {
+	return errors.WithStack(db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"size", "modified", "md5", "sha1", "sha256", "updated"}),
+	}).Create(h).Error)
+}
+
+func DeleteFileHashesByIds(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return errors.WithStack(db.Delete(&model.FileHash{}, ids).Error)
+}
+
+func DeleteFileHashByPath(path string) error {
+	return errors.WithStack(db.Where(columnName("path")+" = ?", path).Delete(&model.FileHash{}).Error)
+}
+
+// GetDupHashes get the hashes of the column shared by more than one file of the same size
+func GetDupHashes(column, dirPath string) ([]model.DupHash, error) {
+	var res []model.DupHash
+	col := columnName(column)
+	err := fileHashUnder(db.Model(&model.FileHash{}), dirPath).
+		Select(col+" AS hash, "+columnName("size")+" AS size, COUNT(*) AS count").
+		Where(col+" <> ?", "").
+		Group(col+", "+columnName("size")).
+		Having("COUNT(*) > ?", 1).
+		Scan(&res).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get duplicate %s", column)
+	}
+	return res, nil
+}
+
+func GetFileHashesByHashes(column, dirPath string, hashes []string) ([]model.FileHash, error) {
+	var res []model.FileHash
+	for _, chunk := range chunkStrings(hashes, 500) {
+		var part []model.FileHash
+		if err := fileHashUnder(db, dirPath).Where(columnName(column)+" IN ?", chunk).Find(&part).Error; err != nil {
+			return nil, errors.Wrapf(err, "failed find file hashes by %s", column)
+		}
+		res = append(res, part...)
+	}
+	return res, nil
+}
+
+func chunkStrings(s []string, size int) [][]string {
+	var res [][]string
+	for len(s) > size {
+		res = append(res, s[:size])
+		s = s[size:]
+	}
+	if len(s) > 0 {
+		res = append(res, s)
+	}
+	return res
+}
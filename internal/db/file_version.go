@@ -0,0 +1,85 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetFileVersionById(id uint) (*model.FileVersion, error) {
+	var v model.FileVersion
+	if err := db.First(&v, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get file version")
+	}
+	return &v, nil
+}
+
+// GetFileVersions get the versions of the file, the newest first
+func GetFileVersions(storageId uint, path string) ([]model.FileVersion, error) {
+	var versions []model.FileVersion
+	if err := db.Where(model.FileVersion{StorageID: storageId, Path: path}).
+		Order(columnName("created") + " desc").Find(&versions).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find file versions")
+	}
+	return versions, nil
+}
+
+// GetFileVersionsCreatedBefore get the versions of the storage which were created before the given time
+func GetFileVersionsCreatedBefore(storageId uint, t time.Time) ([]model.FileVersion, error) {
+	var versions []model.FileVersion
+	if err := db.Where(model.FileVersion{StorageID: storageId}).
+		Where(columnName("created")+" < ?", t).Find(&versions).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find expired file versions")
+	}
+	return versions, nil
+}
+
+func CreateFileVersion(v *model.FileVersion) error {
+	return errors.WithStack(db.Create(v).Error)
+}
+
+func DeleteFileVersionById(id uint) error {
+	return errors.WithStack(db.Delete(&model.FileVersion{}, id).Error)
+}
+
+// fileVersionsUnder limit the query to the versions of the file of the path and the files under it
+func fileVersionsUnder(tx *gorm.DB, storageId uint, path string) *gorm.DB {
+	tx = tx.Where(model.FileVersion{StorageID: storageId})
+	path = utils.FixAndCleanPath(path)
+	if path == "/" {
+		return tx
+	}
+	cond, arg := subPathsCond("path", path)
+	return tx.Where(columnName("path")+" = ? OR "+cond, path, arg)
+}
+
+// GetFileVersionsUnder get the versions of the file of the path and the files under it
+func GetFileVersionsUnder(storageId uint, path string) ([]model.FileVersion, error) {
+	var versions []model.FileVersion
+	if err := fileVersionsUnder(db, storageId, path).Find(&versions).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find file versions")
+	}
+	return versions, nil
+}
+
+// MoveFileVersions move the versions of the file and the files under it to the new path
+func MoveFileVersions(storageId uint, srcPath, dstPath string) error {
+	srcPath, dstPath = utils.FixAndCleanPath(srcPath), utils.FixAndCleanPath(dstPath)
+	return db.Transaction(func(tx *gorm.DB) error {
+		var versions []model.FileVersion
+		if err := fileVersionsUnder(tx, storageId, srcPath).Find(&versions).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		for _, v := range versions {
+			newPath := dstPath + strings.TrimPrefix(v.Path, srcPath)
+			if err := tx.Model(&model.FileVersion{ID: v.ID}).Update("path", newPath).Error; err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	})
+}
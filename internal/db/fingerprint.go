@@ -0,0 +1,41 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm/clause"
+)
+
+// GetDirFingerprint return nil if the dir has never been fingerprinted
+func GetDirFingerprint(path string) (*model.DirFingerprint, error) {
+	var fs []model.DirFingerprint
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("path")), path).Limit(1).Find(&fs).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get fingerprint of %s", path)
+	}
+	if len(fs) == 0 {
+		return nil, nil
+	}
+	return &fs[0], nil
+}
+
+func SaveDirFingerprint(f *model.DirFingerprint) error {
+	return errors.WithStack(db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"modified", "count", "size", "dir_modified", "dir_size", "cursor", "updated"}),
+	}).Create(f).Error)
+}
+
+// DeleteDirFingerprints delete the fingerprints of the dir and all the dirs under it
+func DeleteDirFingerprints(path string) error {
+	path = utils.FixAndCleanPath(path)
+	tx := db.Where(fmt.Sprintf("%s = ?", columnName("path")), path)
+	if path == "/" {
+		tx = db.Where("1 = 1")
+	} else {
+		tx = tx.Or(subPathsCond("path", path))
+	}
+	return errors.WithStack(tx.Delete(&model.DirFingerprint{}).Error)
+}
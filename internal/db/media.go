@@ -0,0 +1,28 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm/clause"
+)
+
+// GetMediaMeta return nil if the media info of the file has never been extracted
+func GetMediaMeta(path string) (*model.MediaMeta, error) {
+	var ms []model.MediaMeta
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("path")), path).Limit(1).Find(&ms).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get media meta of %s", path)
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	return &ms[0], nil
+}
+
+func SaveMediaMeta(m *model.MediaMeta) error {
+	return errors.WithStack(db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"size", "modified", "info"}),
+	}).Create(m).Error)
+}
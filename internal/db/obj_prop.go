@@ -0,0 +1,87 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// objPropsUnder limit the query to the properties of the object of the path and the objects under it
+func objPropsUnder(tx *gorm.DB, path string) *gorm.DB {
+	path = utils.FixAndCleanPath(path)
+	if path == "/" {
+		return tx.Where("1 = 1")
+	}
+	cond, arg := subPathsCond("path", path)
+	return tx.Where(columnName("path")+" = ? OR "+cond, path, arg)
+}
+
+func GetObjProps(path string) ([]model.ObjProp, error) {
+	var props []model.ObjProp
+	if err := db.Where(columnName("path")+" = ?", path).Order(columnName("id")).Find(&props).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find obj props")
+	}
+	return props, nil
+}
+
+// GetObjPropsInDir get the properties of the objects directly in the dir
+func GetObjPropsInDir(dir string) ([]model.ObjProp, error) {
+	cond, arg := subPathsCond("path", utils.FixAndCleanPath(dir))
+	var props []model.ObjProp
+	// the objects in the sub dirs are excluded
+	err := db.Where(cond, arg).Where("NOT "+cond, arg+"/%").Order(columnName("id")).Find(&props).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed find obj props in dir")
+	}
+	return props, nil
+}
+
+// SaveObjProps replace all the properties of the object of the path
+func SaveObjProps(path string, props []model.ObjProp) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(columnName("path")+" = ?", path).Delete(&model.ObjProp{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if len(props) == 0 {
+			return nil
+		}
+		for i := range props {
+			props[i].ID = 0
+			props[i].Path = path
+		}
+		return errors.WithStack(tx.Create(&props).Error)
+	})
+}
+
+// MoveObjProps move the properties of the object and the objects under it to the new path,
+// the properties of the objects which are replaced at the new path are deleted
+func MoveObjProps(srcPath, dstPath string) error {
+	srcPath, dstPath = utils.FixAndCleanPath(srcPath), utils.FixAndCleanPath(dstPath)
+	return db.Transaction(func(tx *gorm.DB) error {
+		var props []model.ObjProp
+		if err := objPropsUnder(tx, srcPath).Find(&props).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if len(props) == 0 {
+			return nil
+		}
+		if err := objPropsUnder(tx, dstPath).Delete(&model.ObjProp{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		for _, p := range props {
+			newPath := dstPath + strings.TrimPrefix(p.Path, srcPath)
+			if err := tx.Model(&model.ObjProp{ID: p.ID}).Update("path", newPath).Error; err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteObjProps delete the properties of the object and the objects under it
+func DeleteObjProps(path string) error {
+	return errors.WithStack(objPropsUnder(db, path).Delete(&model.ObjProp{}).Error)
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetQuotaPaths get the paths of the quotas set on all the users and roles
+func GetQuotaPaths() ([]string, error) {
+	var users []model.User
+	if err := db.Find(&users).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find users")
+	}
+	var roles []model.Role
+	if err := db.Find(&roles).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find roles")
+	}
+	var paths []string
+	seen := make(map[string]struct{})
+	add := func(quotas model.Quotas) {
+		for _, q := range quotas {
+			if _, ok := seen[q.Path]; !ok {
+				seen[q.Path] = struct{}{}
+				paths = append(paths, q.Path)
+			}
+		}
+	}
+	for _, u := range users {
+		add(u.Quotas)
+	}
+	for _, r := range roles {
+		add(r.Quotas)
+	}
+	return paths, nil
+}
+
+func GetQuotaUsages() ([]model.QuotaUsage, error) {
+	var usages []model.QuotaUsage
+	if err := db.Find(&usages).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find quota usages")
+	}
+	return usages, nil
+}
+
+// SyncQuotaUsages create the usages of the new paths and delete the ones not in paths
+func SyncQuotaUsages(paths []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&model.QuotaUsage{})
+		if len(paths) > 0 {
+			query = query.Where(columnName("path")+" NOT IN ?", paths)
+		} else {
+			query = query.Where("1 = 1")
+		}
+		if err := query.Delete(&model.QuotaUsage{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if len(paths) == 0 {
+			return nil
+		}
+		usages := make([]model.QuotaUsage, 0, len(paths))
+		for _, p := range paths {
+			usages = append(usages, model.QuotaUsage{Path: p})
+		}
+		return errors.WithStack(tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&usages).Error)
+	})
+}
+
+func UpdateQuotaUsage(u *model.QuotaUsage) error {
+	return errors.WithStack(db.Model(&model.QuotaUsage{Path: u.Path}).Updates(map[string]any{
+		"bytes":      u.Bytes,
+		"files":      u.Files,
+		"reconciled": u.Reconciled,
+	}).Error)
+}
+
+// IncreaseQuotaUsage add the delta to the usages of the paths, the delta could be negative
+func IncreaseQuotaUsage(paths []string, bytes, files int64) error {
+	return errors.WithStack(db.Model(&model.QuotaUsage{}).Where(columnName("path")+" IN ?", paths).Updates(map[string]any{
+		"bytes": gorm.Expr(columnName("bytes")+" + ?", bytes),
+		"files": gorm.Expr(columnName("files")+" + ?", files),
+	}).Error)
+}
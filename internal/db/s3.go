@@ -0,0 +1,132 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetS3MultipartUploadById(id string) (*model.S3MultipartUpload, error) {
+	var u model.S3MultipartUpload
+	if err := db.Where(columnName("id")+" = ?", id).First(&u).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get s3 multipart upload")
+	}
+	return &u, nil
+}
+
+func GetS3MultipartUploadsByBucket(userId uint, bucket string) ([]model.S3MultipartUpload, error) {
+	var uploads []model.S3MultipartUpload
+	if err := db.Where(model.S3MultipartUpload{UserID: userId, Bucket: bucket}).
+		Order(columnName("object_key")).Order(columnName("id")).Find(&uploads).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find s3 multipart uploads")
+	}
+	return uploads, nil
+}
+
+// GetS3MultipartUploadsUpdatedBefore get the uploads which have no part uploaded since the given time
+func GetS3MultipartUploadsUpdatedBefore(t time.Time) ([]model.S3MultipartUpload, error) {
+	var uploads []model.S3MultipartUpload
+	if err := db.Where(columnName("updated")+" < ?", t).Find(&uploads).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find stale s3 multipart uploads")
+	}
+	return uploads, nil
+}
+
+func CreateS3MultipartUpload(u *model.S3MultipartUpload) error {
+	return errors.WithStack(db.Create(u).Error)
+}
+
+// DeleteS3MultipartUploadById delete the upload with its parts
+func DeleteS3MultipartUploadById(id string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(columnName("upload_id")+" = ?", id).Delete(&model.S3MultipartPart{}).Error; err != nil {
+			return err
+		}
+		return tx.Where(columnName("id")+" = ?", id).Delete(&model.S3MultipartUpload{}).Error
+	}))
+}
+
+func GetS3MultipartParts(uploadId string) ([]model.S3MultipartPart, error) {
+	var parts []model.S3MultipartPart
+	if err := db.Where(columnName("upload_id")+" = ?", uploadId).Order(columnName("number")).Find(&parts).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find s3 multipart parts")
+	}
+	return parts, nil
+}
+
+// SaveS3MultipartPart save the part, which replaces the part of the same number, and refresh the updated time of the upload
+func SaveS3MultipartPart(p *model.S3MultipartPart) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(p).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.S3MultipartUpload{}).Where(columnName("id")+" = ?", p.UploadID).
+			Update("updated", p.Modified).Error
+	}))
+}
+
+// GetS3ObjectMeta return nil if no metadata of the object is kept
+func GetS3ObjectMeta(path string) (*model.S3ObjectMeta, error) {
+	var ms []model.S3ObjectMeta
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("path")), path).Limit(1).Find(&ms).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get s3 object meta of %s", path)
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	return &ms[0], nil
+}
+
+func SaveS3ObjectMeta(m *model.S3ObjectMeta) error {
+	return errors.WithStack(db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"meta"}),
+	}).Create(m).Error)
+}
+
+func DeleteS3ObjectMeta(path string) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ?", columnName("path")), path).Delete(&model.S3ObjectMeta{}).Error)
+}
+
+// s3ObjectMetasUnder limit the query to the object of the path and the objects under it
+func s3ObjectMetasUnder(tx *gorm.DB, path string) *gorm.DB {
+	path = utils.FixAndCleanPath(path)
+	if path == "/" {
+		return tx.Where("1 = 1")
+	}
+	cond, arg := subPathsCond("path", path)
+	return tx.Where(columnName("path")+" = ? OR "+cond, path, arg)
+}
+
+// MoveS3ObjectMetas move the metadata of the object and the objects under it to the new path
+func MoveS3ObjectMetas(srcPath, dstPath string) error {
+	srcPath, dstPath = utils.FixAndCleanPath(srcPath), utils.FixAndCleanPath(dstPath)
+	return db.Transaction(func(tx *gorm.DB) error {
+		var ms []model.S3ObjectMeta
+		if err := s3ObjectMetasUnder(tx, srcPath).Find(&ms).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		// the metadata of the overwritten objects are dropped
+		if err := s3ObjectMetasUnder(tx, dstPath).Delete(&model.S3ObjectMeta{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		for _, m := range ms {
+			newPath := dstPath + strings.TrimPrefix(m.Path, srcPath)
+			if err := tx.Model(&model.S3ObjectMeta{ID: m.ID}).Update("path", newPath).Error; err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteS3ObjectMetasUnder delete the metadata of the object and the objects under it
+func DeleteS3ObjectMetasUnder(path string) error {
+	return errors.WithStack(s3ObjectMetasUnder(db, path).Delete(&model.S3ObjectMeta{}).Error)
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetScheduledJobById(id uint) (*model.ScheduledJob, error) {
+	var j model.ScheduledJob
+	if err := db.First(&j, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get scheduled job")
+	}
+	return &j, nil
+}
+
+func GetScheduledJobs(pageIndex, pageSize int) (jobs []model.ScheduledJob, count int64, err error) {
+	jobDB := db.Model(&model.ScheduledJob{})
+	if err := jobDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get scheduled jobs count")
+	}
+	if err := jobDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&jobs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find scheduled jobs")
+	}
+	return jobs, count, nil
+}
+
+func GetEnabledScheduledJobs() ([]model.ScheduledJob, error) {
+	var jobs []model.ScheduledJob
+	if err := db.Where(columnName("disabled")+" = ?", false).Find(&jobs).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find enabled scheduled jobs")
+	}
+	return jobs, nil
+}
+
+func CreateScheduledJob(j *model.ScheduledJob) error {
+	return errors.WithStack(db.Create(j).Error)
+}
+
+func UpdateScheduledJob(j *model.ScheduledJob) error {
+	return errors.WithStack(db.Save(j).Error)
+}
+
+func DeleteScheduledJobById(id uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(columnName("job_id")+" = ?", id).Delete(&model.ScheduledJobRun{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(tx.Delete(&model.ScheduledJob{}, id).Error)
+	})
+}
+
+// CreateScheduledJobRun record the run unless the job has been deleted while it was running,
+// so that no run is left without its job
+func CreateScheduledJobRun(r *model.ScheduledJobRun) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.ScheduledJob{}).Where(columnName("id")+" = ?", r.JobID).Count(&count).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if count == 0 {
+			return nil
+		}
+		return errors.WithStack(tx.Create(r).Error)
+	})
+}
+
+func GetScheduledJobRuns(jobId uint, pageIndex, pageSize int) (runs []model.ScheduledJobRun, count int64, err error) {
+	runDB := db.Model(&model.ScheduledJobRun{})
+	query := model.ScheduledJobRun{JobID: jobId}
+	if err := runDB.Where(query).Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get scheduled job runs count")
+	}
+	if err := runDB.Where(query).Order(columnName("start_time") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&runs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find scheduled job runs")
+	}
+	return runs, count, nil
+}
+
+// UpdateScheduledJobLastRun only update the columns changed by a run,
+// so that the changes made by the admin at the same time are not overwritten
+func UpdateScheduledJobLastRun(id uint, lastRun time.Time, state string) error {
+	return errors.WithStack(db.Model(&model.ScheduledJob{ID: id}).Updates(map[string]any{
+		"last_run": lastRun,
+		"state":    state,
+	}).Error)
+}
@@ -35,7 +35,7 @@ func DeleteSearchNodesByParent(path string) error {
 	if err != nil {
 		return err
 	}
-	dir, name := stdpath.Split(path)
+	dir, name := stdpath.Dir(path), stdpath.Base(path)
 	return db.Where(fmt.Sprintf("%s = ? AND %s = ?",
 		columnName("parent"), columnName("name")),
 		dir, name).Delete(&model.SearchNode{}).Error
@@ -56,7 +56,7 @@ func GetSearchNodesByParent(parent string) ([]model.SearchNode, error) {
 
 func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int64, error) {
 	var searchDB *gorm.DB
-	if !useFullText || conf.Conf.Database.Type == "sqlite3" {
+	if !useFullText || conf.Conf.Database.Type == "sqlite3" || strings.TrimSpace(req.Keywords) == "" {
 		keywordsClause := db.Where("1 = 1")
 		for _, keyword := range strings.Fields(req.Keywords) {
 			keywordsClause = keywordsClause.Where("name LIKE ?", fmt.Sprintf("%%%s%%", keyword))
@@ -77,15 +77,70 @@ func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int6
 		isDir := req.Scope == 1
 		searchDB.Where(db.Where("is_dir = ?", isDir))
 	}
+	searchDB = whereSearchFilter(searchDB, req.SearchFilter)
 
 	var count int64
 	if err := searchDB.Count(&count).Error; err != nil {
 		return nil, 0, errors.Wrapf(err, "failed get search items count")
 	}
+	order := "name"
+	if req.OrderBy != "" {
+		order = req.OrderBy
+	}
+	order = columnName(order)
+	if req.OrderDirection == "desc" {
+		order += " desc"
+	} else {
+		order += " asc"
+	}
 	var files []model.SearchNode
-	if err := searchDB.Order("name asc").Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
+	if err := searchDB.Order(order).Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
 		Find(&files).Error; err != nil {
 		return nil, 0, err
 	}
 	return files, count, nil
 }
+
+func whereSearchFilter(searchDB *gorm.DB, f model.SearchFilter) *gorm.DB {
+	if f.MinSize != nil {
+		searchDB = searchDB.Where(columnName("size")+" >= ?", *f.MinSize)
+	}
+	if f.MaxSize != nil {
+		searchDB = searchDB.Where(columnName("size")+" <= ?", *f.MaxSize)
+	}
+	if f.ModifiedAfter != nil {
+		searchDB = searchDB.Where(columnName("modified")+" >= ?", *f.ModifiedAfter)
+	}
+	if f.ModifiedBefore != nil {
+		searchDB = searchDB.Where(columnName("modified")+" < ?", *f.ModifiedBefore)
+	}
+	if len(f.Exts) > 0 {
+		searchDB = searchDB.Where(columnName("ext")+" IN ?", f.Exts)
+	}
+	if len(f.Types) > 0 {
+		searchDB = searchDB.Where(columnName("obj_type")+" IN ?", f.Types)
+	}
+	if len(f.Storages) > 0 {
+		searchDB = searchDB.Where(columnName("storage")+" IN ?", f.Storages)
+	}
+	if f.MinWidth != nil {
+		searchDB = searchDB.Where(columnName("width")+" >= ?", *f.MinWidth)
+	}
+	if f.MinHeight != nil {
+		searchDB = searchDB.Where(columnName("height")+" >= ?", *f.MinHeight)
+	}
+	if f.MinDuration != nil {
+		searchDB = searchDB.Where(columnName("duration")+" >= ?", *f.MinDuration)
+	}
+	if f.MaxDuration != nil {
+		// the files without duration are not matched
+		searchDB = searchDB.Where(columnName("duration")+" > 0 AND "+columnName("duration")+" <= ?", *f.MaxDuration)
+	}
+	if f.TakenAfter != nil {
+		searchDB = searchDB.Where(columnName("taken")+" >= ?", *f.TakenAfter)
+	}
+	if f.TakenBefore != nil {
+		searchDB = searchDB.Where(columnName("taken")+" < ?", *f.TakenBefore)
+	}
+	return searchDB
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetShareById(id string) (*model.Share, error) {
+	var s model.Share
+	if err := db.Where(columnName("id")+" = ?", id).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get share")
+	}
+	return &s, nil
+}
+
+func GetShares(pageIndex, pageSize int) (shares []model.Share, count int64, err error) {
+	shareDB := db.Model(&model.Share{})
+	if err := shareDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get shares count")
+	}
+	if err := shareDB.Order(columnName("created")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&shares).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find shares")
+	}
+	return shares, count, nil
+}
+
+func GetSharesByCreatorId(creatorId uint, pageIndex, pageSize int) (shares []model.Share, count int64, err error) {
+	shareDB := db.Model(&model.Share{})
+	query := model.Share{CreatorID: creatorId}
+	if err := shareDB.Where(query).Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get user's shares count")
+	}
+	if err := shareDB.Where(query).Order(columnName("created")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&shares).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find user's shares")
+	}
+	return shares, count, nil
+}
+
+func CreateShare(s *model.Share) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func UpdateShare(s *model.Share) error {
+	return errors.WithStack(db.Save(s).Error)
+}
+
+func UpdateSharePassword(s *model.Share) error {
+	return errors.WithStack(db.Model(&model.Share{ID: s.ID}).Updates(map[string]any{
+		"password": s.Password,
+		"salt":     s.Salt,
+	}).Error)
+}
+
+func DeleteShareById(id string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(columnName("share_id")+" = ?", id).Delete(&model.ShareAccessLog{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(tx.Where(columnName("id")+" = ?", id).Delete(&model.Share{}).Error)
+	})
+}
+
+// IncreaseShareDownloads increases the download counter of the share
+// and returns false if the share has reached its download limit
+func IncreaseShareDownloads(id string) (bool, error) {
+	res := db.Model(&model.Share{}).
+		Where(columnName("id")+" = ?", id).
+		Where("("+columnName("max_downloads")+" = 0 OR "+columnName("downloads")+" < "+columnName("max_downloads")+")").
+		UpdateColumn("downloads", gorm.Expr(columnName("downloads")+" + 1"))
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func CreateShareAccessLog(l *model.ShareAccessLog) error {
+	return errors.WithStack(db.Create(l).Error)
+}
+
+func GetShareAccessLogs(shareId string, pageIndex, pageSize int) (logs []model.ShareAccessLog, count int64, err error) {
+	logDB := db.Model(&model.ShareAccessLog{}).Where(columnName("share_id")+" = ?", shareId)
+	if err := logDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get share access logs count")
+	}
+	if err := logDB.Order(columnName("id") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find share access logs")
+	}
+	return logs, count, nil
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetUploadSessionById(id string) (*model.UploadSession, error) {
+	var s model.UploadSession
+	if err := db.Where(columnName("id")+" = ?", id).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get upload session")
+	}
+	return &s, nil
+}
+
+// GetUploadSessionsUpdatedBefore get the sessions which have not been written since the given time
+func GetUploadSessionsUpdatedBefore(t time.Time) ([]model.UploadSession, error) {
+	var sessions []model.UploadSession
+	if err := db.Where(columnName("updated")+" < ?", t).Find(&sessions).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find stale upload sessions")
+	}
+	return sessions, nil
+}
+
+func CreateUploadSession(s *model.UploadSession) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func UpdateUploadSession(s *model.UploadSession) error {
+	return errors.WithStack(db.Save(s).Error)
+}
+
+func DeleteUploadSessionById(id string) error {
+	return errors.WithStack(db.Where(columnName("id")+" = ?", id).Delete(&model.UploadSession{}).Error)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"gorm.io/gorm"
@@ -17,3 +18,11 @@ func columnName(name string) string {
 func addStorageOrder(db *gorm.DB) *gorm.DB {
 	return db.Order(fmt.Sprintf("%s, %s", columnName("order"), columnName("id")))
 }
+
+var likeReplacer = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// subPathsCond the condition and the arg matching the paths under the dir,
+// the wildcards in the dir are escaped by '!' which has no special meaning in any of the databases
+func subPathsCond(column, dir string) (string, string) {
+	return columnName(column) + " LIKE ? ESCAPE '!'", likeReplacer.Replace(strings.TrimSuffix(dir, "/")) + "/%"
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"database/sql"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetWebdavLocks(pageIndex, pageSize int) (locks []model.WebdavLock, count int64, err error) {
+	lockDB := db.Model(&model.WebdavLock{})
+	if err := lockDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get webdav locks count")
+	}
+	if err := lockDB.Order(columnName("root")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&locks).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find webdav locks")
+	}
+	return locks, count, nil
+}
+
+func GetWebdavLockByToken(token string) (*model.WebdavLock, error) {
+	var l model.WebdavLock
+	if err := db.Where(columnName("token")+" = ?", token).First(&l).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get webdav lock")
+	}
+	return &l, nil
+}
+
+// the times the transaction of creating a lock is retried if it is aborted by the concurrent ones
+const webdavLockRetries = 5
+
+// CreateWebdavLock create the lock if it doesn't conflict with the locks which have not expired,
+// return false if it conflicts. The expired locks are deleted at the same time.
+// The conflict check and the insert are done in a serializable transaction, so the instances
+// sharing the db can't create the conflicting locks at the same time, and the transaction
+// aborted by a concurrent one is retried.
+func CreateWebdavLock(l *model.WebdavLock, now time.Time) (bool, error) {
+	var err error
+	for i := 0; i < webdavLockRetries; i++ {
+		var created bool
+		if created, err = createWebdavLock(l, now); err == nil {
+			return created, nil
+		}
+		time.Sleep(time.Duration(i+1) * 10 * time.Millisecond)
+	}
+	return false, err
+}
+
+func createWebdavLock(l *model.WebdavLock, now time.Time) (bool, error) {
+	created := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(columnName("expires")+" <= ?", now).Delete(&model.WebdavLock{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		// only the locks of the root, its ancestors and its descendants may conflict with it
+		roots := []string{l.Root}
+		for p := l.Root; p != "/" && p != "."; {
+			p = stdpath.Dir(p)
+			roots = append(roots, p)
+		}
+		conflictDB := tx.Where(columnName("root")+" IN ?", roots)
+		if !l.ZeroDepth {
+			conflictDB = conflictDB.Or(subPathsCond("root", l.Root))
+		}
+		var locks []model.WebdavLock
+		if err := conflictDB.Find(&locks).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		for i := range locks {
+			if locks[i].Conflicts(l.Root, l.ZeroDepth) {
+				return nil
+			}
+		}
+		if err := tx.Create(l).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		created = true
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	return created, err
+}
+
+// notHeld limit the query to the locks which are not held by any request
+func notHeld(tx *gorm.DB, now time.Time) *gorm.DB {
+	return tx.Where("("+columnName("held_until")+" IS NULL OR "+columnName("held_until")+" <= ?)", now)
+}
+
+// RefreshWebdavLock update the timeout of the lock, return false if it is held by a request
+func RefreshWebdavLock(l *model.WebdavLock, now time.Time) (bool, error) {
+	res := notHeld(db.Model(&model.WebdavLock{}).Where(columnName("token")+" = ?", l.Token), now).
+		Updates(map[string]any{"timeout": l.Timeout, "expires": l.Expires})
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// HoldWebdavLocks hold all the locks of the tokens for the holder until the time, or none of them
+// if any one is missing or held by another request, return whether they are held
+func HoldWebdavLocks(tokens []string, holder string, now, until time.Time) (bool, error) {
+	held := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		res := notHeld(tx.Model(&model.WebdavLock{}).Where(columnName("token")+" IN ?", tokens), now).
+			Updates(map[string]any{"held_by": holder, "held_until": until})
+		if res.Error != nil {
+			return errors.WithStack(res.Error)
+		}
+		if res.RowsAffected != int64(len(tokens)) {
+			return errWebdavLockHeld
+		}
+		held = true
+		return nil
+	})
+	if errors.Is(err, errWebdavLockHeld) {
+		return false, nil
+	}
+	return held, err
+}
+
+// errWebdavLockHeld roll back the holding of the locks
+var errWebdavLockHeld = errors.New("webdav lock is held")
+
+// RenewWebdavLocks extend the hold of the locks by the holder
+func RenewWebdavLocks(tokens []string, holder string, until time.Time) error {
+	return errors.WithStack(db.Model(&model.WebdavLock{}).
+		Where(columnName("token")+" IN ? AND "+columnName("held_by")+" = ?", tokens, holder).
+		Update("held_until", until).Error)
+}
+
+// ReleaseWebdavLocks end the hold of the locks by the holder
+func ReleaseWebdavLocks(tokens []string, holder string) error {
+	return errors.WithStack(db.Model(&model.WebdavLock{}).
+		Where(columnName("token")+" IN ? AND "+columnName("held_by")+" = ?", tokens, holder).
+		Updates(map[string]any{"held_by": "", "held_until": nil}).Error)
+}
+
+// UnlockWebdavLock delete the lock, return false if it is held by a request
+func UnlockWebdavLock(token string, now time.Time) (bool, error) {
+	res := notHeld(db.Where(columnName("token")+" = ?", token), now).Delete(&model.WebdavLock{})
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func DeleteWebdavLockByToken(token string) error {
+	return errors.WithStack(db.Where(columnName("token")+" = ?", token).Delete(&model.WebdavLock{}).Error)
+}
+
+func DeleteExpiredWebdavLocks(now time.Time) error {
+	return errors.WithStack(db.Where(columnName("expires")+" <= ?", now).Delete(&model.WebdavLock{}).Error)
+}
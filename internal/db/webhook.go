@@ -0,0 +1,94 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetWebhookById(id uint) (*model.Webhook, error) {
+	var w model.Webhook
+	if err := db.First(&w, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get webhook")
+	}
+	return &w, nil
+}
+
+func GetWebhooks(pageIndex, pageSize int) (webhooks []model.Webhook, count int64, err error) {
+	webhookDB := db.Model(&model.Webhook{})
+	if err := webhookDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get webhooks count")
+	}
+	if err := webhookDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&webhooks).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find webhooks")
+	}
+	return webhooks, count, nil
+}
+
+func GetEnabledWebhooks() ([]model.Webhook, error) {
+	var webhooks []model.Webhook
+	if err := db.Where(columnName("disabled")+" = ?", false).Find(&webhooks).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find enabled webhooks")
+	}
+	return webhooks, nil
+}
+
+func CreateWebhook(w *model.Webhook) error {
+	return errors.WithStack(db.Create(w).Error)
+}
+
+func UpdateWebhook(w *model.Webhook) error {
+	return errors.WithStack(db.Save(w).Error)
+}
+
+func DeleteWebhookById(id uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(columnName("webhook_id")+" = ?", id).Delete(&model.WebhookDelivery{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(tx.Delete(&model.Webhook{}, id).Error)
+	})
+}
+
+func CreateWebhookDelivery(d *model.WebhookDelivery) error {
+	return errors.WithStack(db.Create(d).Error)
+}
+
+func UpdateWebhookDelivery(d *model.WebhookDelivery) error {
+	return errors.WithStack(db.Save(d).Error)
+}
+
+func GetWebhookDeliveryById(id uint) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	if err := db.First(&d, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get webhook delivery")
+	}
+	return &d, nil
+}
+
+// GetWebhookDeliveries get the deliveries of the webhook, all webhooks if webhookId is 0
+func GetWebhookDeliveries(webhookId uint, pageIndex, pageSize int) (deliveries []model.WebhookDelivery, count int64, err error) {
+	query := model.WebhookDelivery{WebhookID: webhookId}
+	if err := db.Model(&model.WebhookDelivery{}).Where(query).Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get webhook deliveries count")
+	}
+	if err := db.Where(query).Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&deliveries).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find webhook deliveries")
+	}
+	return deliveries, count, nil
+}
+
+// GetWebhookDeliveriesToRetry get the deliveries which should be retried before the given time
+func GetWebhookDeliveriesToRetry(t time.Time) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	if err := db.Where(columnName("next_retry")+" <= ?", t).Find(&deliveries).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find webhook deliveries to retry")
+	}
+	return deliveries, nil
+}
+
+func DeleteWebhookDeliveriesBefore(t time.Time) error {
+	return errors.WithStack(db.Where(columnName("created")+" < ?", t).Delete(&model.WebhookDelivery{}).Error)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
 type Driver interface {
@@ -42,6 +43,38 @@ type GetRooter interface {
 	GetRoot(ctx context.Context) (model.Obj, error)
 }
 
+// Trash is implemented by storages which have a native trash,
+// the removed objects are kept there instead of the .alist-trash dir
+type Trash interface {
+	// TrashObj move the object to the native trash,
+	// return an id which is used to restore or purge it later
+	TrashObj(ctx context.Context, obj model.Obj) (string, error)
+	// RestoreTrash move the object back to where it was removed from
+	RestoreTrash(ctx context.Context, id string) error
+	// PurgeTrash delete the object from the native trash permanently
+	PurgeTrash(ctx context.Context, id string) error
+}
+
+// HardLink is implemented by storages which can make two files share the same content
+type HardLink interface {
+	// HardLink replace dstObj with a hard link to srcObj
+	HardLink(ctx context.Context, srcObj, dstObj model.Obj) error
+}
+
+// IndexChanges is implemented by storages which offer a change feed, so that
+// the search index can be refreshed without walking the whole storage
+type IndexChanges interface {
+	// GetIndexChanges return the actual paths of the dirs whose children changed since the cursor,
+	// and the cursor for the next call. The empty cursor asks for the current cursor only.
+	GetIndexChanges(ctx context.Context, cursor string) (dirs []string, nextCursor string, err error)
+}
+
+type WithDetails interface {
+	// GetDetails get the capacity of the storage
+	// return errs.NotSupport if the storage does not have a limited capacity
+	GetDetails(ctx context.Context) (*model.StorageDetails, error)
+}
+
 type Getter interface {
 	// Get file by path, the path haven't been joined with root path
 	Get(ctx context.Context, path string) (model.Obj, error)
@@ -103,6 +136,16 @@ type Put interface {
 	Put(ctx context.Context, dstDir model.Obj, file model.FileStreamer, up UpdateProgress) error
 }
 
+// PutByHash is implemented by storages which support rapid upload, that the file is created
+// without uploading its content if the storage already has a file with the same hashes
+type PutByHash interface {
+	// PutHashTypes return the types of the hashes needed by PutByHash
+	PutHashTypes() []*utils.HashType
+	// PutByHash create the file by its hashes, the returned obj may be nil if the storage doesn't return it.
+	// Return errs.RapidUploadMissed if the storage has no such file, then the content will be uploaded by Put
+	PutByHash(ctx context.Context, dstDir model.Obj, name string, size int64, hashes utils.HashInfo) (model.Obj, error)
+}
+
 type PutURL interface {
 	// PutURL directly put a URL into the storage
 	// Applicable to index-based drivers like URL-Tree or drivers that support uploading files as URLs
@@ -4,4 +4,6 @@ import "errors"
 
 var (
 	EmptyToken = errors.New("empty token")
+	// RapidUploadMissed is returned by driver.PutByHash if the storage has no file with the same hashes
+	RapidUploadMissed = errors.New("no file with the same hashes for rapid upload")
 )
@@ -0,0 +1,11 @@
+package errs
+
+import "errors"
+
+var (
+	ShareNotFound             = errors.New("share not found")
+	ShareExpired              = errors.New("share has expired")
+	ShareDisabled             = errors.New("share is disabled")
+	ShareDownloadLimitReached = errors.New("share download limit reached")
+	WrongSharePassword        = errors.New("share password is incorrect")
+)
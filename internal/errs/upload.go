@@ -0,0 +1,9 @@
+package errs
+
+import "errors"
+
+var (
+	UploadSessionNotFound = errors.New("upload session not found")
+	UploadSessionBusy     = errors.New("upload session is being written")
+	UploadOffsetMismatch  = errors.New("upload offset mismatch")
+)
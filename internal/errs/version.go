@@ -0,0 +1,7 @@
+package errs
+
+import "errors"
+
+var (
+	FileVersionNotFound = errors.New("file version not found")
+)
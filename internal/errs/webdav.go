@@ -0,0 +1,8 @@
+package errs
+
+import "errors"
+
+var (
+	WebdavLockNotFound = errors.New("webdav lock not found")
+	WebdavLocked       = errors.New("the resource is locked")
+)
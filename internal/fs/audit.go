@@ -0,0 +1,107 @@
+package fs
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	log "github.com/sirupsen/logrus"
+)
+
+// the audit logs are buffered and written to the db in batches,
+// so that recording never blocks the operations
+var (
+	auditOnce  sync.Once
+	auditQueue chan model.AuditLog
+)
+
+const (
+	auditQueueSize     = 4096
+	auditBatchSize     = 100
+	auditFlushInterval = 2 * time.Second
+)
+
+// audit record the action of the user who made the request.
+// Only the requests from the servers (which put the protocol into the context) are recorded,
+// the internal calls such as tasks are not.
+func audit(ctx context.Context, action, srcPath, dstPath string, err error) {
+	protocol, _ := ctx.Value(conf.ProtocolKey).(string)
+	if protocol == "" || !setting.GetBool(conf.AuditLogEnabled) {
+		return
+	}
+	l := model.AuditLog{
+		Time:     time.Now(),
+		Protocol: protocol,
+		Action:   action,
+		SrcPath:  srcPath,
+		DstPath:  dstPath,
+		Success:  err == nil,
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok && user != nil {
+		l.UserID, l.Username = user.ID, user.Username
+	}
+	if ip, ok := ctx.Value(conf.ClientIPKey).(string); ok {
+		if host, _, e := net.SplitHostPort(ip); e == nil {
+			ip = host
+		}
+		l.IP = ip
+	}
+	if err != nil {
+		l.Error = err.Error()
+	}
+	auditOnce.Do(func() {
+		auditQueue = make(chan model.AuditLog, auditQueueSize)
+		go writeAuditLogs()
+	})
+	select {
+	case auditQueue <- l:
+	default:
+		log.Warnf("audit log queue is full, drop: %+v", l)
+	}
+}
+
+// AuditDownload record the download of the file, it's called by the servers when the content is served
+// rather than by Link, which is also called for the previews and the HEAD requests
+func AuditDownload(ctx context.Context, path string, err error) {
+	audit(ctx, model.AuditDownload, path, "", err)
+}
+
+func writeAuditLogs() {
+	ticker := time.NewTicker(auditFlushInterval)
+	defer ticker.Stop()
+	logs := make([]model.AuditLog, 0, auditBatchSize)
+	flush := func() {
+		if len(logs) == 0 {
+			return
+		}
+		if err := op.CreateAuditLogs(logs); err != nil {
+			log.Errorf("failed write audit logs: %+v", err)
+		}
+		logs = make([]model.AuditLog, 0, auditBatchSize)
+	}
+	for {
+		select {
+		case l := <-auditQueue:
+			logs = append(logs, l)
+			if len(logs) >= auditBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// PruneAuditLogs delete the audit logs older than the retention days
+func PruneAuditLogs() error {
+	days := setting.GetInt(conf.AuditLogRetentionDays, 90)
+	if days <= 0 {
+		return nil
+	}
+	return op.DeleteAuditLogsBefore(time.Now().AddDate(0, 0, -days))
+}
@@ -0,0 +1,217 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+// ArchiveCompressTask pack the objects under the src dir into an archive in the dst dir, the paths are mount paths
+type ArchiveCompressTask struct {
+	task.TaskExtension
+	model.ArchiveCompressArgs
+	Status     string `json:"-"`
+	SrcDirPath string `json:"src_dir_path"`
+	// the slash separated paths under the src dir of all the objs to pack, the dirs are not walked into,
+	// so that the objs which the creator can't access are left out by the caller
+	Names       []string `json:"names"`
+	DstDirPath  string   `json:"dst_dir_path"`
+	ArchiveName string   `json:"archive_name"`
+}
+
+func (t *ArchiveCompressTask) GetName() string {
+	// only the top level objs are shown, the names include all the objs under them
+	names := utils.SliceFilter(t.Names, func(name string) bool {
+		return !strings.Contains(name, "/")
+	})
+	return fmt.Sprintf("compress [%s](%s) to [%s](%s)", t.SrcDirPath, strings.Join(names, ", "), t.DstDirPath, t.ArchiveName)
+}
+
+func (t *ArchiveCompressTask) GetStatus() string {
+	return t.Status
+}
+
+type compressEntry struct {
+	// the mount path of the obj
+	path string
+	// the slash separated path in the archive
+	name string
+	obj  model.Obj
+}
+
+func (t *ArchiveCompressTask) Run() error {
+	t.ReinitCtx()
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	compressor, err := tool.GetCompressor(t.Format)
+	if err != nil {
+		return err
+	}
+	t.Status = "walking src objects"
+	var (
+		entries []compressEntry
+		total   int64
+	)
+	for _, name := range t.Names {
+		p := stdpath.Join(t.SrcDirPath, name)
+		obj, err := get(t.Ctx(), p)
+		if err != nil {
+			// a missing file makes the archive incomplete, so the errors are not ignored
+			return errors.WithMessagef(err, "failed get [%s]", p)
+		}
+		entries = append(entries, compressEntry{path: p, name: name, obj: obj})
+		if !obj.IsDir() {
+			total += obj.GetSize()
+		}
+	}
+	t.SetTotalBytes(total)
+	// the drivers need the size before uploading, so the archive is written to a temp file first
+	tmp, err := os.CreateTemp(conf.Conf.TempDir, "archive-*")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	if err = t.compress(compressor, entries, tmp, total); err != nil {
+		return err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return errors.WithStack(err)
+	}
+	dstPath := stdpath.Join(t.DstDirPath, t.ArchiveName)
+	if err = CheckQuota(t.Ctx(), dstPath, size); err != nil {
+		return err
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(t.DstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst storage")
+	}
+	t.Status = "uploading archive"
+	file := &stream.FileStream{
+		Ctx: t.Ctx(),
+		Obj: &model.Object{
+			Name:     t.ArchiveName,
+			Size:     size,
+			Modified: time.Now(),
+		},
+		Reader:   tmp,
+		Mimetype: utils.GetMimeType(t.ArchiveName),
+	}
+	// packing takes the first half of the progress, and uploading takes the rest
+	err = op.Put(t.Ctx(), dstStorage, dstDirActualPath, file, func(p float64) {
+		t.SetProgress(50 + p/2)
+	})
+	if err != nil {
+		return err
+	}
+	t.Status = fmt.Sprintf("done, %d objects packed", len(entries))
+	return nil
+}
+
+func (t *ArchiveCompressTask) compress(compressor tool.Compressor, entries []compressEntry, w io.Writer, total int64) error {
+	aw, err := compressor.NewWriter(w, t.ArchiveCompressArgs)
+	if err != nil {
+		return err
+	}
+	var done int64
+	for _, e := range entries {
+		if utils.IsCanceled(t.Ctx()) {
+			return t.Ctx().Err()
+		}
+		t.Status = "compressing " + e.path
+		if e.obj.IsDir() {
+			err = aw.Add(e.name, e.obj, nil)
+		} else {
+			err = t.addFile(aw, e)
+		}
+		if err != nil {
+			return errors.WithMessagef(err, "failed compress [%s]", e.path)
+		}
+		done += e.obj.GetSize()
+		if total > 0 {
+			t.SetProgress(float64(done) * 50 / float64(total))
+		}
+	}
+	return aw.Close()
+}
+
+func (t *ArchiveCompressTask) addFile(aw tool.ArchiveWriter, e compressEntry) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(e.path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	link, _, err := op.Link(t.Ctx(), storage, actualPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return errors.WithMessage(err, "failed get link")
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Obj: e.obj, Ctx: t.Ctx()}, link)
+	if err != nil {
+		return errors.WithMessage(err, "failed get stream")
+	}
+	defer ss.Close()
+	return aw.Add(e.name, e.obj, ss)
+}
+
+var ArchiveCompressTaskManager *tache.Manager[*ArchiveCompressTask]
+
+func archiveCompress(ctx context.Context, srcDirPath string, names []string, dstDirPath, archiveName string, args model.ArchiveCompressArgs) (task.TaskExtensionInfo, error) {
+	if len(names) == 0 {
+		return nil, errors.New("no objects to compress")
+	}
+	for _, name := range names {
+		if name == "" || strings.HasPrefix(name, "/") || stdpath.Clean(name) != name || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, errors.Errorf("invalid name [%s]", name)
+		}
+	}
+	if _, err := tool.GetCompressor(args.Format); err != nil {
+		return nil, err
+	}
+	if args.Password != "" && args.Format != ".zip" {
+		return nil, errors.WithMessage(errs.NotSupport, "only zip archives can be encrypted")
+	}
+	if _, _, err := op.GetStorageAndActualPath(dstDirPath); err != nil {
+		return nil, errors.WithMessage(err, "failed get dst storage")
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t := &ArchiveCompressTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+		},
+		ArchiveCompressArgs: args,
+		SrcDirPath:          srcDirPath,
+		Names:               names,
+		DstDirPath:          dstDirPath,
+		ArchiveName:         archiveName,
+	}
+	if ctx.Value(conf.NoTaskKey) != nil {
+		t.SetCtx(ctx)
+		return nil, t.Run()
+	}
+	ArchiveCompressTaskManager.Add(t)
+	return t, nil
+}
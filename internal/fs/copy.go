@@ -56,6 +56,14 @@ func (t *CopyTask) Run() error {
 	return copyBetween2Storages(t, t.srcStorage, t.dstStorage, t.SrcObjPath, t.DstDirPath)
 }
 
+func (t *CopyTask) OnSucceeded() {
+	task.HandleEventHook(t, true)
+}
+
+func (t *CopyTask) OnFailed() {
+	task.HandleEventHook(t, false)
+}
+
 var CopyTaskManager *tache.Manager[*CopyTask]
 
 // Copy if in the same storage, call move method
@@ -90,8 +98,9 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 				return nil, errors.WithMessagef(err, "failed get [%s] link", srcObjPath)
 			}
 			fs := stream.FileStream{
-				Obj: srcObj,
-				Ctx: ctx,
+				Obj:         srcObj,
+				Ctx:         ctx,
+				TrustedHash: true,
 			}
 			// any link provided is seekable
 			ss, err := stream.NewSeekableStream(fs, link)
@@ -167,8 +176,9 @@ func copyFileBetween2Storages(tsk *CopyTask, srcStorage, dstStorage driver.Drive
 		return errors.WithMessagef(err, "failed get [%s] link", srcFilePath)
 	}
 	fs := stream.FileStream{
-		Obj: srcFile,
-		Ctx: tsk.Ctx(),
+		Obj:         srcFile,
+		Ctx:         tsk.Ctx(),
+		TrustedHash: true,
 	}
 	// any link provided is seekable
 	ss, err := stream.NewSeekableStream(fs, link)
@@ -0,0 +1,434 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/xhofe/tache"
+)
+
+const (
+	// DedupIndex record the hashes of the files
+	DedupIndex = "index"
+	// DedupRemove remove the duplicate files, keep the oldest one
+	DedupRemove = "remove"
+	// DedupHardLink replace the duplicate files with hard links to the oldest one
+	DedupHardLink = "hardlink"
+)
+
+// the hashes are compared in this order, the columns of model.FileHash are named after them
+var dedupHashTypes = []*utils.HashType{utils.SHA256, utils.SHA1, utils.MD5}
+
+// the hashes of the files in these storages are computed if the driver does not provide one,
+// since reading the whole file from them is cheap
+var hashableDrivers = []string{"Local", "SMB", "SFTP"}
+
+type DedupTask struct {
+	task.TaskExtension
+	Status string `json:"-"` //don't save status to save space
+	Action string `json:"action"`
+	Path   string `json:"path"`
+}
+
+func (t *DedupTask) GetName() string {
+	return fmt.Sprintf("dedup %s [%s]", t.Action, t.Path)
+}
+
+func (t *DedupTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *DedupTask) Run() error {
+	t.ReinitCtx()
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	switch t.Action {
+	case DedupIndex:
+		return t.index()
+	case DedupRemove, DedupHardLink:
+		return t.dedup()
+	default:
+		return errors.Errorf("unknown dedup action: %s", t.Action)
+	}
+}
+
+var DedupTaskManager *tache.Manager[*DedupTask]
+
+func (t *DedupTask) index() error {
+	ctx := t.Ctx()
+	root, err := get(ctx, t.Path)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s]", t.Path)
+	}
+	hashes, err := op.GetFileHashesUnder(t.Path)
+	if err != nil {
+		return err
+	}
+	indexed := make(map[string]model.FileHash, len(hashes))
+	for _, h := range hashes {
+		indexed[h.Path] = h
+	}
+	seen := make(map[string]struct{})
+	var files, updated int
+	err = WalkFS(ctx, -1, t.Path, root, func(reqPath string, info model.Obj) error {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		if info.IsDir() {
+			return nil
+		}
+		files++
+		t.Status = fmt.Sprintf("indexed %d files, %d updated", files, updated)
+		old, ok := indexed[reqPath]
+		if ok && old.Size == info.GetSize() && old.Modified.Unix() == info.ModTime().Unix() {
+			seen[reqPath] = struct{}{}
+			return nil
+		}
+		h, err := getFileHash(ctx, reqPath, info)
+		if err != nil {
+			log.Warnf("failed get hash of %s: %+v", reqPath, err)
+			return nil
+		}
+		if h == nil {
+			return nil
+		}
+		if err = op.SaveFileHash(h); err != nil {
+			return err
+		}
+		seen[reqPath] = struct{}{}
+		updated++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	var stale []uint
+	for p, h := range indexed {
+		if _, ok := seen[p]; !ok {
+			stale = append(stale, h.ID)
+		}
+	}
+	if err = op.DeleteFileHashesByIds(stale); err != nil {
+		return err
+	}
+	t.Status = fmt.Sprintf("done, indexed %d files, %d updated, %d removed", files, updated, len(stale))
+	t.SetProgress(100)
+	return nil
+}
+
+// getFileHash get the hashes of the file provided by the driver, or compute them
+// if the storage is hashable, return nil if neither is possible
+func getFileHash(ctx context.Context, path string, obj model.Obj) (*model.FileHash, error) {
+	h := &model.FileHash{
+		Path:     path,
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		Updated:  time.Now(),
+	}
+	hashInfo := obj.GetHash()
+	setFileHash(h, hashInfo)
+	if h.MD5 != "" || h.SHA1 != "" || h.SHA256 != "" {
+		return h, nil
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.SliceContains(hashableDrivers, storage.Config().Name) {
+		return nil, nil
+	}
+	link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get link")
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Obj: obj, Ctx: ctx}, link)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get stream")
+	}
+	defer ss.Close()
+	hasher := utils.NewMultiHasher(dedupHashTypes)
+	if _, err = utils.CopyWithBuffer(hasher, ss); err != nil {
+		return nil, errors.WithMessage(err, "failed read file")
+	}
+	setFileHash(h, *hasher.GetHashInfo())
+	return h, nil
+}
+
+func setFileHash(h *model.FileHash, hashInfo utils.HashInfo) {
+	h.MD5 = strings.ToLower(hashInfo.GetHash(utils.MD5))
+	h.SHA1 = strings.ToLower(hashInfo.GetHash(utils.SHA1))
+	h.SHA256 = strings.ToLower(hashInfo.GetHash(utils.SHA256))
+}
+
+type dedupGroup struct {
+	model.DedupGroup
+	files []model.FileHash
+}
+
+// getDedupGroups group the indexed files under the dir which have the same content,
+// two files are compared by the strongest type of hash both of them have,
+// so the files of the storages providing different hashes are still compared,
+// and a weak hash never puts the files with different strong hashes into the same group
+func getDedupGroups(dirPath string) ([]*dedupGroup, error) {
+	// the files sharing a hash of any type with another file of the same size
+	candidates := make(map[string]model.FileHash)
+	for _, ht := range dedupHashTypes {
+		dups, err := op.GetDupHashes(ht.Name, dirPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(dups) == 0 {
+			continue
+		}
+		hashes := make([]string, 0, len(dups))
+		for _, d := range dups {
+			hashes = append(hashes, d.Hash)
+		}
+		files, err := op.GetFileHashesByHashes(ht.Name, dirPath, hashes)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			candidates[f.Path] = f
+		}
+	}
+	files := make([]model.FileHash, 0, len(candidates))
+	for _, f := range candidates {
+		files = append(files, f)
+	}
+	// the files with the stronger hashes come first, so they decide the groups
+	sort.Slice(files, func(i, j int) bool {
+		si, sj := hashTypeIndex(strongestHashType(&files[i])), hashTypeIndex(strongestHashType(&files[j]))
+		if si != sj {
+			return si < sj
+		}
+		return files[i].Path < files[j].Path
+	})
+	var all []*dedupGroup
+	for _, f := range files {
+		var joined bool
+		for _, g := range all {
+			if g.Size == f.Size && allFiles(g.files, func(o model.FileHash) bool {
+				return sameFileHash(&o, &f)
+			}) {
+				g.files = append(g.files, f)
+				joined = true
+				break
+			}
+		}
+		if !joined {
+			all = append(all, &dedupGroup{
+				DedupGroup: model.DedupGroup{Size: f.Size},
+				files:      []model.FileHash{f},
+			})
+		}
+	}
+	var groups []*dedupGroup
+	for _, g := range all {
+		if len(g.files) < 2 {
+			continue
+		}
+		// report the strongest type of hash all the files have
+		for _, ht := range dedupHashTypes {
+			if allFiles(g.files, func(f model.FileHash) bool {
+				return fileHashOf(&f, ht) != ""
+			}) {
+				g.HashType, g.Hash = ht.Name, fileHashOf(&g.files[0], ht)
+				break
+			}
+		}
+		sort.Slice(g.files, func(i, j int) bool {
+			return g.files[i].Path < g.files[j].Path
+		})
+		g.Paths = make([]string, 0, len(g.files))
+		for _, f := range g.files {
+			g.Paths = append(g.Paths, f.Path)
+		}
+		g.Wasted = g.Size * int64(len(g.files)-1)
+		groups = append(groups, g)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Wasted > groups[j].Wasted
+	})
+	return groups, nil
+}
+
+// sameFileHash compare the files by the strongest type of hash both of them have,
+// the files without any type of hash in common are not the same
+func sameFileHash(a, b *model.FileHash) bool {
+	for _, ht := range dedupHashTypes {
+		ha, hb := fileHashOf(a, ht), fileHashOf(b, ht)
+		if ha != "" && hb != "" {
+			return ha == hb
+		}
+	}
+	return false
+}
+
+func hashTypeIndex(ht *utils.HashType) int {
+	for i, t := range dedupHashTypes {
+		if t == ht {
+			return i
+		}
+	}
+	return len(dedupHashTypes)
+}
+
+// allFiles check whether all the files match
+func allFiles(files []model.FileHash, match func(f model.FileHash) bool) bool {
+	for _, f := range files {
+		if !match(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// strongestHashType the first type of dedupHashTypes the file has a hash of
+func strongestHashType(f *model.FileHash) *utils.HashType {
+	for _, ht := range dedupHashTypes {
+		if fileHashOf(f, ht) != "" {
+			return ht
+		}
+	}
+	return nil
+}
+
+func fileHashOf(f *model.FileHash, ht *utils.HashType) string {
+	switch ht {
+	case utils.MD5:
+		return f.MD5
+	case utils.SHA1:
+		return f.SHA1
+	default:
+		return f.SHA256
+	}
+}
+
+// DedupReport list the groups of the duplicate files under the dir which have been indexed,
+// the groups taking the most redundant space come first
+func DedupReport(dirPath string) ([]model.DedupGroup, error) {
+	groups, err := getDedupGroups(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.DedupGroup, 0, len(groups))
+	for _, g := range groups {
+		res = append(res, g.DedupGroup)
+	}
+	return res, nil
+}
+
+func (t *DedupTask) dedup() error {
+	ctx := t.Ctx()
+	t.Status = "getting duplicate files"
+	groups, err := getDedupGroups(t.Path)
+	if err != nil {
+		return err
+	}
+	var done, skipped int
+	for i, g := range groups {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		// only the duplicate files in the same storage are handled
+		sameStorage := make(map[string][]model.FileHash)
+		for _, f := range g.files {
+			storage, _, err := op.GetStorageAndActualPath(f.Path)
+			if err != nil {
+				continue
+			}
+			mp := storage.GetStorage().MountPath
+			sameStorage[mp] = append(sameStorage[mp], f)
+		}
+		for _, files := range sameStorage {
+			if len(files) < 2 {
+				continue
+			}
+			n, err := t.dedupFiles(files)
+			if err != nil {
+				log.Warnf("failed dedup %s: %+v", files[0].Path, err)
+			}
+			done += n
+			skipped += len(files) - 1 - n
+		}
+		t.Status = fmt.Sprintf("%s %d files, %d skipped", t.Action, done, skipped)
+		t.SetProgress(float64(i+1) * 100 / float64(len(groups)))
+	}
+	t.Status = fmt.Sprintf("done, %s %d files, %d skipped", t.Action, done, skipped)
+	return nil
+}
+
+// dedupFiles keep the oldest one of the files which are in the same storage,
+// remove or hard link the others, return the number of handled files
+func (t *DedupTask) dedupFiles(files []model.FileHash) (int, error) {
+	ctx := t.Ctx()
+	storage, _, err := op.GetStorageAndActualPath(files[0].Path)
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := storage.(driver.HardLink); !ok && t.Action == DedupHardLink {
+		return 0, errors.Errorf("storage [%s] does not support hard link", storage.GetStorage().MountPath)
+	}
+	// skip the files changed since indexed
+	files = utils.SliceFilter(files, func(f model.FileHash) bool {
+		obj, err := get(ctx, f.Path)
+		return err == nil && obj.GetSize() == f.Size && obj.ModTime().Unix() == f.Modified.Unix()
+	})
+	if len(files) < 2 {
+		return 0, nil
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Modified.Before(files[j].Modified)
+	})
+	keep := files[0]
+	_, keepActualPath, _ := op.GetStorageAndActualPath(keep.Path)
+	n := 0
+	for _, f := range files[1:] {
+		if t.Action == DedupRemove {
+			err = remove(ctx, f.Path)
+			if err == nil {
+				err = op.DeleteFileHashByPath(f.Path)
+			}
+		} else {
+			_, actualPath, _ := op.GetStorageAndActualPath(f.Path)
+			err = op.HardLink(ctx, storage, keepActualPath, actualPath)
+		}
+		if err != nil {
+			return n, errors.WithMessagef(err, "failed %s %s", t.Action, f.Path)
+		}
+		n++
+	}
+	return n, nil
+}
+
+func _dedup(ctx context.Context, path, action string) (*DedupTask, error) {
+	if !utils.SliceContains([]string{DedupIndex, DedupRemove, DedupHardLink}, action) {
+		return nil, errors.Errorf("unknown dedup action: %s", action)
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t := &DedupTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+		},
+		Action: action,
+		Path:   utils.FixAndCleanPath(path),
+	}
+	DedupTaskManager.Add(t)
+	return t, nil
+}
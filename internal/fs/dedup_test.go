@@ -0,0 +1,191 @@
+package fs_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+// setupDedup mount a local storage on a temp dir with the files, the files are
+// older in the order of the names, return the root dir of the storage
+func setupDedup(t *testing.T, mountPath string, files map[string]string) string {
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.WriteFile(p, []byte(content), 0o666); err != nil {
+			t.Fatal(err)
+		}
+		modified := time.Date(2024, 1, 1, 0, 0, int(name[0]), 0, time.UTC)
+		if err := os.Chtimes(p, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: mountPath,
+		Addition:  `{"root_folder_path":"` + root + `"}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	return root
+}
+
+func runDedup(t *testing.T, action, path string) {
+	task := &fs.DedupTask{Action: action, Path: path}
+	task.SetCtx(context.Background())
+	if err := task.Run(); err != nil {
+		t.Fatalf("failed to run dedup %s: %+v", action, err)
+	}
+}
+
+func TestDedupGroups(t *testing.T) {
+	modified := time.Now()
+	hashes := []model.FileHash{
+		{Path: "/groups/a", Size: 1, MD5: "m1", SHA256: "s1"},
+		{Path: "/groups/b", Size: 1, MD5: "m2", SHA256: "s1"},
+		// same md5 as a, but a different sha256
+		{Path: "/groups/c", Size: 1, MD5: "m1", SHA256: "s2"},
+		// only md5, compared with c by md5, since b has a different one they don't join a and b
+		{Path: "/groups/d", Size: 1, MD5: "m1"},
+		{Path: "/groups/e", Size: 1, MD5: "m1"},
+		// only sha1, which no other file has
+		{Path: "/groups/g", Size: 1, SHA1: "h1"},
+		// the wildcard in the dir must not match
+		{Path: "/groupsX/f", Size: 1, MD5: "m1"},
+	}
+	for i := range hashes {
+		hashes[i].Modified = modified
+		if err := op.SaveFileHash(&hashes[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	groups, err := fs.DedupReport("/group_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 0 {
+		t.Errorf("expected no groups under /group_, got %+v", groups)
+	}
+	groups, err = fs.DedupReport("/groups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{
+		"s1": {"/groups/a", "/groups/b"},
+		"m1": {"/groups/c", "/groups/d", "/groups/e"},
+	}
+	if len(groups) != len(expected) {
+		t.Fatalf("expected %d groups, got %+v", len(expected), groups)
+	}
+	for _, g := range groups {
+		paths, ok := expected[g.Hash]
+		if !ok || !utils.SliceEqual(g.Paths, paths) {
+			t.Errorf("unexpected group %+v", g)
+		}
+	}
+}
+
+func TestDedupRemove(t *testing.T) {
+	root := setupDedup(t, "/dedup_remove", map[string]string{
+		"a": "same",
+		"b": "same",
+		"c": "diff",
+	})
+	runDedup(t, fs.DedupIndex, "/dedup_remove")
+	runDedup(t, fs.DedupRemove, "/dedup_remove")
+	for name, exists := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, err := os.Stat(filepath.Join(root, name)); (err == nil) != exists {
+			t.Errorf("expected %s exists: %v, got err: %v", name, exists, err)
+		}
+	}
+}
+
+func TestDedupCanceled(t *testing.T) {
+	root := setupDedup(t, "/dedup_canceled", map[string]string{
+		"a": "same",
+		"b": "same",
+	})
+	runDedup(t, fs.DedupIndex, "/dedup_canceled")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	task := &fs.DedupTask{Action: fs.DedupRemove, Path: "/dedup_canceled"}
+	task.SetCtx(ctx)
+	if err := task.Run(); err == nil {
+		t.Error("expected the canceled dedup to fail")
+	}
+	if _, err := os.Stat(filepath.Join(root, "b")); err != nil {
+		t.Errorf("expected b to be kept, got %v", err)
+	}
+}
+
+func TestDedupHardLink(t *testing.T) {
+	root := setupDedup(t, "/dedup_link", map[string]string{
+		"a": "same",
+		"b": "same",
+	})
+	runDedup(t, fs.DedupIndex, "/dedup_link")
+	runDedup(t, fs.DedupHardLink, "/dedup_link")
+	a, err := os.Stat(filepath.Join(root, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.Stat(filepath.Join(root, "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(a, b) {
+		t.Fatalf("expected b to be a hard link to a")
+	}
+
+	// overwriting one of the linked files must not change the other
+	storage, err := op.GetStorageByMountPath("/dedup_link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("changed")
+	file := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     "b",
+			Size:     int64(len(content)),
+			Modified: time.Now(),
+		},
+		Reader: io.NopCloser(bytes.NewReader(content)),
+	}
+	if err = op.Put(context.Background(), storage, "/", file, nil); err != nil {
+		t.Fatalf("failed to put: %+v", err)
+	}
+	for name, expected := range map[string]string{"a": "same", "b": "changed"} {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Errorf("expected %s to be %q, got %q", name, expected, data)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	log "github.com/sirupsen/logrus"
 	"io"
+	stdpath "path"
 
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
@@ -23,6 +24,9 @@ type ListArgs struct {
 }
 
 func List(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error) {
+	if err := checkHiddenPath(ctx, path); err != nil {
+		return nil, err
+	}
 	res, err := list(ctx, path, args)
 	if err != nil {
 		if !args.NoLog {
@@ -38,6 +42,9 @@ type GetArgs struct {
 }
 
 func Get(ctx context.Context, path string, args *GetArgs) (model.Obj, error) {
+	if err := checkHiddenPath(ctx, path); err != nil {
+		return nil, err
+	}
 	res, err := get(ctx, path)
 	if err != nil {
 		if !args.NoLog {
@@ -49,6 +56,9 @@ func Get(ctx context.Context, path string, args *GetArgs) (model.Obj, error) {
 }
 
 func Link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
+	if err := checkHiddenPath(ctx, path); err != nil {
+		return nil, nil, err
+	}
 	res, file, err := link(ctx, path, args)
 	if err != nil {
 		log.Errorf("failed link %s: %+v", path, err)
@@ -58,7 +68,11 @@ func Link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, m
 }
 
 func MakeDir(ctx context.Context, path string, lazyCache ...bool) error {
+	if err := checkHiddenPath(ctx, path); err != nil {
+		return err
+	}
 	err := makeDir(ctx, path, lazyCache...)
+	audit(ctx, model.AuditMakeDir, path, "", err)
 	if err != nil {
 		log.Errorf("failed make dir %s: %+v", path, err)
 	}
@@ -66,7 +80,11 @@ func MakeDir(ctx context.Context, path string, lazyCache ...bool) error {
 }
 
 func Move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) error {
+	if err := checkHiddenPath(ctx, srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath))); err != nil {
+		return err
+	}
 	err := move(ctx, srcPath, dstDirPath, lazyCache...)
+	audit(ctx, model.AuditMove, srcPath, dstDirPath, err)
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
 	}
@@ -74,15 +92,60 @@ func Move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) er
 }
 
 func Copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool) (task.TaskExtensionInfo, error) {
+	if err := checkHiddenPath(ctx, srcObjPath, stdpath.Join(dstDirPath, stdpath.Base(srcObjPath))); err != nil {
+		return nil, err
+	}
 	res, err := _copy(ctx, srcObjPath, dstDirPath, lazyCache...)
+	audit(ctx, model.AuditCopy, srcObjPath, dstDirPath, err)
 	if err != nil {
 		log.Errorf("failed copy %s to %s: %+v", srcObjPath, dstDirPath, err)
 	}
 	return res, err
 }
 
+// Sync make the dst dir the same as the src dir by a sync task
+func Sync(ctx context.Context, srcDirPath, dstDirPath string, args SyncArgs) (task.TaskExtensionInfo, error) {
+	if err := checkHiddenPath(ctx, srcDirPath, dstDirPath); err != nil {
+		return nil, err
+	}
+	t, err := _sync(ctx, srcDirPath, dstDirPath, args)
+	audit(ctx, model.AuditSync, srcDirPath, dstDirPath, err)
+	if err != nil {
+		log.Errorf("failed sync %s to %s: %+v", srcDirPath, dstDirPath, err)
+		return nil, err
+	}
+	return t, nil
+}
+
+// PlanSync return the operations that Sync would do, without doing them
+func PlanSync(ctx context.Context, srcDirPath, dstDirPath string, args SyncArgs) ([]SyncOp, error) {
+	ops, err := planSyncByPath(ctx, srcDirPath, dstDirPath, args)
+	if err != nil {
+		log.Errorf("failed plan sync %s to %s: %+v", srcDirPath, dstDirPath, err)
+	}
+	return ops, err
+}
+
+// Dedup index the hashes of the files under the dir, or remove or hard link
+// the duplicate ones of them in the same storage by a dedup task
+func Dedup(ctx context.Context, path, action string) (task.TaskExtensionInfo, error) {
+	if err := checkHiddenPath(ctx, path); err != nil {
+		return nil, err
+	}
+	t, err := _dedup(ctx, path, action)
+	if err != nil {
+		log.Errorf("failed dedup %s %s: %+v", action, path, err)
+		return nil, err
+	}
+	return t, nil
+}
+
 func Rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) error {
+	if err := checkHiddenPath(ctx, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName)); err != nil {
+		return err
+	}
 	err := rename(ctx, srcPath, dstName, lazyCache...)
+	audit(ctx, model.AuditRename, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName), err)
 	if err != nil {
 		log.Errorf("failed rename %s to %s: %+v", srcPath, dstName, err)
 	}
@@ -90,7 +153,11 @@ func Rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 }
 
 func Remove(ctx context.Context, path string) error {
+	if err := checkHiddenPath(ctx, path); err != nil {
+		return err
+	}
 	err := remove(ctx, path)
+	audit(ctx, model.AuditRemove, path, "", err)
 	if err != nil {
 		log.Errorf("failed remove %s: %+v", path, err)
 	}
@@ -98,7 +165,11 @@ func Remove(ctx context.Context, path string) error {
 }
 
 func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer, lazyCache ...bool) error {
+	if err := checkHiddenPath(ctx, stdpath.Join(dstDirPath, file.GetName())); err != nil {
+		return err
+	}
 	err := putDirectly(ctx, dstDirPath, file, lazyCache...)
+	audit(ctx, model.AuditUpload, "", stdpath.Join(dstDirPath, file.GetName()), err)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
@@ -106,7 +177,11 @@ func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 }
 
 func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (task.TaskExtensionInfo, error) {
+	if err := checkHiddenPath(ctx, stdpath.Join(dstDirPath, file.GetName())); err != nil {
+		return nil, err
+	}
 	t, err := putAsTask(ctx, dstDirPath, file)
+	audit(ctx, model.AuditUpload, "", stdpath.Join(dstDirPath, file.GetName()), err)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
@@ -114,6 +189,9 @@ func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 }
 
 func ArchiveMeta(ctx context.Context, path string, args model.ArchiveMetaArgs) (*model.ArchiveMetaProvider, error) {
+	if err := checkHiddenPath(ctx, path); err != nil {
+		return nil, err
+	}
 	meta, err := archiveMeta(ctx, path, args)
 	if err != nil {
 		log.Errorf("failed get archive meta %s: %+v", path, err)
@@ -122,6 +200,9 @@ func ArchiveMeta(ctx context.Context, path string, args model.ArchiveMetaArgs) (
 }
 
 func ArchiveList(ctx context.Context, path string, args model.ArchiveListArgs) ([]model.Obj, error) {
+	if err := checkHiddenPath(ctx, path); err != nil {
+		return nil, err
+	}
 	objs, err := archiveList(ctx, path, args)
 	if err != nil {
 		log.Errorf("failed list archive [%s]%s: %+v", path, args.InnerPath, err)
@@ -130,14 +211,37 @@ func ArchiveList(ctx context.Context, path string, args model.ArchiveListArgs) (
 }
 
 func ArchiveDecompress(ctx context.Context, srcObjPath, dstDirPath string, args model.ArchiveDecompressArgs, lazyCache ...bool) (task.TaskExtensionInfo, error) {
+	if err := checkHiddenPath(ctx, srcObjPath, dstDirPath); err != nil {
+		return nil, err
+	}
 	t, err := archiveDecompress(ctx, srcObjPath, dstDirPath, args, lazyCache...)
+	audit(ctx, model.AuditDecompress, srcObjPath, dstDirPath, err)
 	if err != nil {
 		log.Errorf("failed decompress [%s]%s: %+v", srcObjPath, args.InnerPath, err)
 	}
 	return t, err
 }
 
+func ArchiveCompress(ctx context.Context, srcDirPath string, names []string, dstDirPath, archiveName string, args model.ArchiveCompressArgs) (task.TaskExtensionInfo, error) {
+	paths := []string{stdpath.Join(dstDirPath, archiveName)}
+	for _, name := range names {
+		paths = append(paths, stdpath.Join(srcDirPath, name))
+	}
+	if err := checkHiddenPath(ctx, paths...); err != nil {
+		return nil, err
+	}
+	t, err := archiveCompress(ctx, srcDirPath, names, dstDirPath, archiveName, args)
+	audit(ctx, model.AuditCompress, srcDirPath, stdpath.Join(dstDirPath, archiveName), err)
+	if err != nil {
+		log.Errorf("failed compress %+v in %s to %s: %+v", names, srcDirPath, archiveName, err)
+	}
+	return t, err
+}
+
 func ArchiveDriverExtract(ctx context.Context, path string, args model.ArchiveInnerArgs) (*model.Link, model.Obj, error) {
+	if err := checkHiddenPath(ctx, path); err != nil {
+		return nil, nil, err
+	}
 	l, obj, err := archiveDriverExtract(ctx, path, args)
 	if err != nil {
 		log.Errorf("failed extract [%s]%s: %+v", path, args.InnerPath, err)
@@ -146,6 +250,9 @@ func ArchiveDriverExtract(ctx context.Context, path string, args model.ArchiveIn
 }
 
 func ArchiveInternalExtract(ctx context.Context, path string, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	if err := checkHiddenPath(ctx, path); err != nil {
+		return nil, 0, err
+	}
 	l, obj, err := archiveInternalExtract(ctx, path, args)
 	if err != nil {
 		log.Errorf("failed extract [%s]%s: %+v", path, args.InnerPath, err)
@@ -164,7 +271,18 @@ func GetStorage(path string, args *GetStoragesArgs) (driver.Driver, error) {
 	return storageDriver, nil
 }
 
+func GetStorageDetails(ctx context.Context, path string, refresh ...bool) (*model.StorageDetails, error) {
+	storage, _, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	return op.GetStorageDetails(ctx, storage, refresh...)
+}
+
 func Other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
+	if err := checkHiddenPath(ctx, args.Path); err != nil {
+		return nil, err
+	}
 	res, err := other(ctx, args)
 	if err != nil {
 		log.Errorf("failed remove %s: %+v", args.Path, err)
@@ -173,6 +291,9 @@ func Other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
 }
 
 func PutURL(ctx context.Context, path, dstName, urlStr string) error {
+	if err := checkHiddenPath(ctx, stdpath.Join(path, dstName)); err != nil {
+		return err
+	}
 	storage, dstDirActualPath, err := op.GetStorageAndActualPath(path)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
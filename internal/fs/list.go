@@ -3,6 +3,7 @@ package fs
 import (
 	"context"
 
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -35,6 +36,12 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 				return nil, errors.WithMessage(err, "failed get objs")
 			}
 		}
+		if utils.PathEqual(actualPath, "/") {
+			// the trash and versions are only accessible through their own api
+			_objs = utils.SliceFilter(_objs, func(obj model.Obj) bool {
+				return obj.GetName() != conf.TrashDirName && obj.GetName() != conf.VersionsDirName
+			})
+		}
 	}
 
 	om := model.NewObjMerge()
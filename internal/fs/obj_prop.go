@@ -0,0 +1,37 @@
+package fs
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	log "github.com/sirupsen/logrus"
+)
+
+// the custom properties of the objects are kept by their paths,
+// so they are moved, renamed and removed with the objects
+
+// GetObjProps get the custom properties of the object of the path
+func GetObjProps(path string) ([]model.ObjProp, error) {
+	return op.GetObjProps(path)
+}
+
+// GetObjPropsInDir get the custom properties of the objects in the dir, not including the ones in the sub dirs
+func GetObjPropsInDir(dir string) ([]model.ObjProp, error) {
+	return op.GetObjPropsInDir(dir)
+}
+
+// SaveObjProps replace the custom properties of the object of the path
+func SaveObjProps(path string, props []model.ObjProp) error {
+	return op.SaveObjProps(path, props)
+}
+
+func moveObjProps(srcPath, dstPath string) {
+	if err := op.MoveObjProps(srcPath, dstPath); err != nil {
+		log.Warnf("failed move the props of %s to %s: %+v", srcPath, dstPath, err)
+	}
+}
+
+func removeObjProps(path string) {
+	if err := op.DeleteObjProps(path); err != nil {
+		log.Warnf("failed delete the props of %s: %+v", path, err)
+	}
+}
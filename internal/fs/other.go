@@ -2,6 +2,7 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
 
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -29,7 +30,13 @@ func move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) er
 	if srcStorage.GetStorage() != dstStorage.GetStorage() {
 		return errors.WithStack(errs.MoveBetweenTwoStorages)
 	}
-	return op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath, lazyCache...)
+	if err = op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath, lazyCache...); err != nil {
+		return err
+	}
+	moveObjProps(srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
+	moveS3ObjectMetas(srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
+	moveFileVersions(srcStorage, srcActualPath, stdpath.Join(dstDirActualPath, stdpath.Base(srcActualPath)))
+	return nil
 }
 
 func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) error {
@@ -37,7 +44,13 @@ func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return op.Rename(ctx, storage, srcActualPath, dstName, lazyCache...)
+	if err = op.Rename(ctx, storage, srcActualPath, dstName, lazyCache...); err != nil {
+		return err
+	}
+	moveObjProps(srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
+	moveS3ObjectMetas(srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
+	moveFileVersions(storage, srcActualPath, stdpath.Join(stdpath.Dir(srcActualPath), dstName))
+	return nil
 }
 
 func remove(ctx context.Context, path string) error {
@@ -45,7 +58,26 @@ func remove(ctx context.Context, path string) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return op.Remove(ctx, storage, actualPath)
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		// if object not found, it's ok
+		if errs.IsObjectNotFound(err) {
+			return nil
+		}
+		return errors.WithMessage(err, "failed get object")
+	}
+	// the versions are moved to the trash with the object
+	if shouldTrash(ctx, actualPath) {
+		err = trash(ctx, storage, path, actualPath)
+	} else if err = op.Remove(ctx, storage, actualPath); err == nil {
+		removeFileVersions(ctx, storage, actualPath)
+	}
+	if err == nil {
+		removeObjProps(path)
+		removeS3ObjectMetas(path)
+		op.HandleObjEventHook(ctx, model.EventRemove, path, obj)
+	}
+	return err
 }
 
 func other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
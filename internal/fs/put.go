@@ -10,6 +10,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/pkg/errors"
 	"github.com/xhofe/tache"
+	stdpath "path"
 	"time"
 )
 
@@ -46,6 +47,9 @@ func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 	if storage.Config().NoUpload {
 		return nil, errors.WithStack(errs.UploadNotSupported)
 	}
+	if err := CheckQuota(ctx, stdpath.Join(dstDirPath, file.GetName()), file.GetSize()); err != nil {
+		return nil, err
+	}
 	if file.NeedStore() {
 		_, err := file.CacheFullInTempFile()
 		if err != nil {
@@ -77,5 +81,8 @@ func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	if storage.Config().NoUpload {
 		return errors.WithStack(errs.UploadNotSupported)
 	}
+	if err := CheckQuota(ctx, stdpath.Join(dstDirPath, file.GetName()), file.GetSize()); err != nil {
+		return err
+	}
 	return op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
 }
@@ -0,0 +1,80 @@
+package fs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+var reconcileMu sync.Mutex
+
+// ReconcileQuotaUsage walk the quota paths to correct the usage tracked on every change,
+// the changes made outside alist are only counted by this. If all is false, only the paths
+// never reconciled are walked.
+func ReconcileQuotaUsage(ctx context.Context, all bool) {
+	// skip if the previous reconciling is still walking
+	if !reconcileMu.TryLock() {
+		return
+	}
+	defer reconcileMu.Unlock()
+	usages, err := op.GetQuotaUsages()
+	if err != nil {
+		log.Errorf("failed get quota usages: %+v", err)
+		return
+	}
+	for i := range usages {
+		if utils.IsCanceled(ctx) {
+			return
+		}
+		if !all && usages[i].Reconciled != nil {
+			continue
+		}
+		bytes, files, err := walkUsage(ctx, usages[i].Path)
+		if err != nil {
+			log.Warnf("failed count the usage of %s: %+v", usages[i].Path, err)
+			continue
+		}
+		now := time.Now()
+		usages[i].Bytes, usages[i].Files, usages[i].Reconciled = bytes, files, &now
+		if err := op.UpdateQuotaUsage(&usages[i]); err != nil {
+			log.Errorf("failed update quota usage of %s: %+v", usages[i].Path, err)
+		}
+	}
+}
+
+func walkUsage(ctx context.Context, path string) (bytes, files int64, err error) {
+	obj, err := Get(ctx, path, &GetArgs{NoLog: true})
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			return 0, 0, nil
+		}
+		return 0, 0, err
+	}
+	err = WalkFS(ctx, -1, path, obj, func(reqPath string, info model.Obj) error {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		if !info.IsDir() {
+			bytes += info.GetSize()
+			files++
+		}
+		return nil
+	})
+	return bytes, files, err
+}
+
+// CheckQuota check if the user in ctx could put a file of the size to the path,
+// the size of the existing file at the path is deducted as it will be overwritten
+func CheckQuota(ctx context.Context, path string, size int64) error {
+	files := int64(1)
+	if exist, err := Get(ctx, path, &GetArgs{NoLog: true}); err == nil && !exist.IsDir() {
+		size, files = size-exist.GetSize(), 0
+	}
+	return op.CheckQuota(ctx, path, size, files)
+}
@@ -0,0 +1,20 @@
+package fs
+
+import (
+	"github.com/alist-org/alist/v3/internal/op"
+	log "github.com/sirupsen/logrus"
+)
+
+// the metadata kept by the s3 server follows the objects, so that it is not served for other objects
+
+func moveS3ObjectMetas(srcPath, dstPath string) {
+	if err := op.MoveS3ObjectMetas(srcPath, dstPath); err != nil {
+		log.Warnf("failed move the s3 metadata of %s to %s: %+v", srcPath, dstPath, err)
+	}
+}
+
+func removeS3ObjectMetas(path string) {
+	if err := op.DeleteS3ObjectMetasUnder(path); err != nil {
+		log.Warnf("failed delete the s3 metadata of %s: %+v", path, err)
+	}
+}
@@ -0,0 +1,334 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/xhofe/tache"
+)
+
+type SyncArgs struct {
+	// Delete the objects in dst which don't exist in src
+	Delete bool `json:"delete"`
+}
+
+const (
+	SyncOpMakeDir = "mkdir"
+	SyncOpCopy    = "copy"
+	SyncOpDelete  = "delete"
+)
+
+// SyncOp is an operation to make dst the same as src, the paths are mount paths
+type SyncOp struct {
+	Type    string `json:"type"`
+	SrcPath string `json:"src_path,omitempty"` // the src file to copy
+	DstPath string `json:"dst_path"`           // the dir to make, the dir to copy to or the object to delete
+	Size    int64  `json:"size"`
+}
+
+// SyncTask make the dst dir the same as the src dir, the sync is one-way:
+// the changes in dst are overwritten by src (or deleted if Delete is set), and never copied back to src.
+// The recurring syncs are scheduled by the scheduled jobs.
+type SyncTask struct {
+	task.TaskExtension
+	Status       string        `json:"-"` //don't save status to save space
+	SrcDirPath   string        `json:"src_path"`
+	DstDirPath   string        `json:"dst_path"`
+	Delete       bool          `json:"delete"`
+	srcStorage   driver.Driver `json:"-"`
+	dstStorage   driver.Driver `json:"-"`
+	SrcStorageMp string        `json:"src_storage_mp"`
+	DstStorageMp string        `json:"dst_storage_mp"`
+}
+
+func (t *SyncTask) GetName() string {
+	return fmt.Sprintf("sync [%s](%s) to [%s](%s)", t.SrcStorageMp, t.SrcDirPath, t.DstStorageMp, t.DstDirPath)
+}
+
+func (t *SyncTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *SyncTask) Run() error {
+	t.ReinitCtx()
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	var err error
+	if t.srcStorage == nil {
+		if t.srcStorage, err = op.GetStorageByMountPath(t.SrcStorageMp); err != nil {
+			return errors.WithMessage(err, "failed get src storage")
+		}
+	}
+	if t.dstStorage == nil {
+		if t.dstStorage, err = op.GetStorageByMountPath(t.DstStorageMp); err != nil {
+			return errors.WithMessage(err, "failed get dst storage")
+		}
+	}
+	t.Status = "comparing src and dst"
+	ops, err := planSyncOps(t.Ctx(), t.srcStorage, t.dstStorage, t.SrcDirPath, t.DstDirPath, SyncArgs{Delete: t.Delete})
+	if err != nil {
+		return err
+	}
+	var copies []*CopyTask
+	for i, o := range ops {
+		if utils.IsCanceled(t.Ctx()) {
+			cancelCopies(copies)
+			return nil
+		}
+		t.Status = fmt.Sprintf("%s %s", o.Type, o.DstPath)
+		copyTask, err := execSyncOp(t, o)
+		if err != nil {
+			cancelCopies(copies)
+			return errors.WithMessagef(err, "failed %s %s", o.Type, o.DstPath)
+		}
+		if copyTask != nil {
+			copies = append(copies, copyTask)
+		}
+		// the copies are done by the copy tasks, which are waited for below
+		t.SetProgress(float64(i+1) * 50 / float64(len(ops)))
+	}
+	if err = t.waitCopies(copies); err != nil {
+		return err
+	}
+	t.SetProgress(100)
+	t.Status = fmt.Sprintf("done, %d operations", len(ops))
+	return nil
+}
+
+func isCopyFinished(c *CopyTask) bool {
+	return utils.SliceContains([]tache.State{tache.StateSucceeded, tache.StateFailed, tache.StateCanceled}, c.GetState())
+}
+
+func cancelCopies(copies []*CopyTask) {
+	for _, c := range copies {
+		if !isCopyFinished(c) {
+			c.Cancel()
+		}
+	}
+}
+
+// waitCopies wait for the copy tasks added by the sync, the sync fails if any of them doesn't succeed
+func (t *SyncTask) waitCopies(copies []*CopyTask) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		finished, failed := 0, 0
+		for _, c := range copies {
+			if isCopyFinished(c) {
+				finished++
+				if c.GetState() != tache.StateSucceeded {
+					failed++
+				}
+			}
+		}
+		if finished == len(copies) {
+			if failed > 0 {
+				return errors.Errorf("failed copy %d of %d files", failed, len(copies))
+			}
+			return nil
+		}
+		t.Status = fmt.Sprintf("copying, %d of %d files done", finished, len(copies))
+		t.SetProgress(50 + float64(finished)*50/float64(len(copies)))
+		select {
+		case <-t.Ctx().Done():
+			cancelCopies(copies)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+var SyncTaskManager *tache.Manager[*SyncTask]
+
+// execSyncOp do the operation, the copy task is returned if the operation is a copy
+func execSyncOp(t *SyncTask, o SyncOp) (*CopyTask, error) {
+	switch o.Type {
+	case SyncOpMakeDir:
+		return nil, makeDir(t.Ctx(), o.DstPath)
+	case SyncOpDelete:
+		return nil, remove(t.Ctx(), o.DstPath)
+	case SyncOpCopy:
+		srcStorage, srcFileActualPath, err := op.GetStorageAndActualPath(o.SrcPath)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed get src storage")
+		}
+		dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(o.DstPath)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed get dst storage")
+		}
+		// the files are copied by copy tasks, so that each of them can be retried
+		copyTask := &CopyTask{
+			TaskExtension: task.TaskExtension{
+				Creator: t.GetCreator(),
+			},
+			srcStorage:   srcStorage,
+			dstStorage:   dstStorage,
+			SrcObjPath:   srcFileActualPath,
+			DstDirPath:   dstDirActualPath,
+			SrcStorageMp: srcStorage.GetStorage().MountPath,
+			DstStorageMp: dstStorage.GetStorage().MountPath,
+		}
+		CopyTaskManager.Add(copyTask)
+		return copyTask, nil
+	default:
+		return nil, errors.Errorf("unknown sync operation: %s", o.Type)
+	}
+}
+
+// planSync compare the src dir and the dst dir recursively, and append the operations to ops
+func planSync(ctx context.Context, srcStorage, dstStorage driver.Driver, srcDirPath, dstDirPath string, args SyncArgs, dstNotExist bool, ops *[]SyncOp) error {
+	srcObjs, err := op.List(ctx, srcStorage, srcDirPath, model.ListArgs{Refresh: true})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list src [%s] objs", srcDirPath)
+	}
+	dstObjs := make(map[string]model.Obj)
+	if !dstNotExist {
+		objs, err := op.List(ctx, dstStorage, dstDirPath, model.ListArgs{Refresh: true})
+		if err != nil && !errs.IsObjectNotFound(err) {
+			return errors.WithMessagef(err, "failed list dst [%s] objs", dstDirPath)
+		}
+		for _, obj := range objs {
+			// the trash and versions dirs of the dst are neither compared nor deleted
+			if !op.IsHiddenPath(stdpath.Join(dstDirPath, obj.GetName())) {
+				dstObjs[obj.GetName()] = obj
+			}
+		}
+	}
+	srcMp := srcStorage.GetStorage().MountPath
+	dstMp := dstStorage.GetStorage().MountPath
+	for _, srcObj := range srcObjs {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		name := srcObj.GetName()
+		srcPath := stdpath.Join(srcDirPath, name)
+		dstPath := stdpath.Join(dstDirPath, name)
+		// the trash and versions dirs of the src are not copied, and those of the dst are not overwritten
+		if op.IsHiddenPath(srcPath) || op.IsHiddenPath(dstPath) {
+			continue
+		}
+		dstObj, exist := dstObjs[name]
+		delete(dstObjs, name)
+		if exist && dstObj.IsDir() != srcObj.IsDir() {
+			if !args.Delete {
+				log.Warnf("skip sync %s, the type of dst object is different", stdpath.Join(srcMp, srcPath))
+				continue
+			}
+			*ops = append(*ops, SyncOp{Type: SyncOpDelete, DstPath: stdpath.Join(dstMp, dstPath)})
+			exist = false
+		}
+		if srcObj.IsDir() {
+			if !exist {
+				*ops = append(*ops, SyncOp{Type: SyncOpMakeDir, DstPath: stdpath.Join(dstMp, dstPath)})
+			}
+			if err = planSync(ctx, srcStorage, dstStorage, srcPath, dstPath, args, !exist, ops); err != nil {
+				return err
+			}
+			continue
+		}
+		if !exist || isFileChanged(srcObj, dstObj) {
+			*ops = append(*ops, SyncOp{
+				Type:    SyncOpCopy,
+				SrcPath: stdpath.Join(srcMp, srcPath),
+				DstPath: stdpath.Join(dstMp, dstDirPath),
+				Size:    srcObj.GetSize(),
+			})
+		}
+	}
+	if args.Delete {
+		for name, dstObj := range dstObjs {
+			*ops = append(*ops, SyncOp{
+				Type:    SyncOpDelete,
+				DstPath: stdpath.Join(dstMp, dstDirPath, name),
+				Size:    dstObj.GetSize(),
+			})
+		}
+	}
+	return nil
+}
+
+// isFileChanged compare the hash if both objects have the same type of hash,
+// otherwise compare the size and modified time
+func isFileChanged(src, dst model.Obj) bool {
+	if src.GetSize() != dst.GetSize() {
+		return true
+	}
+	dstHash := dst.GetHash()
+	for ht, srcHash := range src.GetHash().All() {
+		if h := dstHash.GetHash(ht); h != "" && srcHash != "" {
+			return h != srcHash
+		}
+	}
+	return src.ModTime().After(dst.ModTime())
+}
+
+func getSyncStorages(srcDirPath, dstDirPath string) (srcStorage, dstStorage driver.Driver, srcDirActualPath, dstDirActualPath string, err error) {
+	srcStorage, srcDirActualPath, err = op.GetStorageAndActualPath(srcDirPath)
+	if err != nil {
+		return nil, nil, "", "", errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, dstDirActualPath, err = op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return nil, nil, "", "", errors.WithMessage(err, "failed get dst storage")
+	}
+	return
+}
+
+func planSyncOps(ctx context.Context, srcStorage, dstStorage driver.Driver, srcDirPath, dstDirPath string, args SyncArgs) ([]SyncOp, error) {
+	ops := make([]SyncOp, 0)
+	_, err := op.GetUnwrap(ctx, dstStorage, dstDirPath)
+	dstNotExist := errs.IsObjectNotFound(err)
+	if dstNotExist {
+		ops = append(ops, SyncOp{Type: SyncOpMakeDir, DstPath: stdpath.Join(dstStorage.GetStorage().MountPath, dstDirPath)})
+	}
+	err = planSync(ctx, srcStorage, dstStorage, srcDirPath, dstDirPath, args, dstNotExist, &ops)
+	return ops, err
+}
+
+func planSyncByPath(ctx context.Context, srcDirPath, dstDirPath string, args SyncArgs) ([]SyncOp, error) {
+	srcStorage, dstStorage, srcDirActualPath, dstDirActualPath, err := getSyncStorages(srcDirPath, dstDirPath)
+	if err != nil {
+		return nil, err
+	}
+	return planSyncOps(ctx, srcStorage, dstStorage, srcDirActualPath, dstDirActualPath, args)
+}
+
+func _sync(ctx context.Context, srcDirPath, dstDirPath string, args SyncArgs) (*SyncTask, error) {
+	srcStorage, dstStorage, srcDirActualPath, dstDirActualPath, err := getSyncStorages(srcDirPath, dstDirPath)
+	if err != nil {
+		return nil, err
+	}
+	srcDir, err := op.GetUnwrap(ctx, srcStorage, srcDirActualPath)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed get src [%s] dir", srcDirPath)
+	}
+	if !srcDir.IsDir() {
+		return nil, errors.WithStack(errs.NotFolder)
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t := &SyncTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+		},
+		srcStorage:   srcStorage,
+		dstStorage:   dstStorage,
+		SrcDirPath:   srcDirActualPath,
+		DstDirPath:   dstDirActualPath,
+		Delete:       args.Delete,
+		SrcStorageMp: srcStorage.GetStorage().MountPath,
+		DstStorageMp: dstStorage.GetStorage().MountPath,
+	}
+	SyncTaskManager.Add(t)
+	return t, nil
+}
@@ -0,0 +1,62 @@
+package fs_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+// setupShowHidden mount a local storage which lists the hidden dirs on a temp dir with the dirs
+func setupShowHidden(t *testing.T, mountPath string, dirs ...string) {
+	root := t.TempDir()
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: mountPath,
+		Addition:  `{"root_folder_path":"` + root + `","show_hidden":true}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+}
+
+func TestPlanSyncHiddenDirs(t *testing.T) {
+	setupShowHidden(t, "/sync_src", "a", filepath.Join(conf.VersionsDirName, "key"))
+	setupShowHidden(t, "/sync_dst", "b", filepath.Join(conf.TrashDirName, "key"))
+	ops, err := fs.PlanSync(context.Background(), "/sync_src", "/sync_dst", fs.SyncArgs{Delete: true})
+	if err != nil {
+		t.Fatalf("failed to plan sync: %+v", err)
+	}
+	expected := map[string]fs.SyncOp{
+		fs.SyncOpMakeDir: {Type: fs.SyncOpMakeDir, DstPath: "/sync_dst/a"},
+		fs.SyncOpDelete:  {Type: fs.SyncOpDelete, DstPath: "/sync_dst/b"},
+	}
+	if len(ops) != len(expected) {
+		t.Fatalf("expected %d operations, got %+v", len(expected), ops)
+	}
+	for _, o := range ops {
+		e := expected[o.Type]
+		if o.SrcPath != e.SrcPath || o.DstPath != e.DstPath {
+			t.Errorf("unexpected operation %+v", o)
+		}
+	}
+}
+
+func TestSyncMissingStorage(t *testing.T) {
+	setupDedup(t, "/sync_missing_dst", nil)
+	task := &fs.SyncTask{SrcStorageMp: "/sync_missing_src", DstStorageMp: "/sync_missing_dst"}
+	task.SetCtx(context.Background())
+	if err := task.Run(); err == nil {
+		t.Fatal("expected the sync from a missing storage to fail")
+	}
+}
@@ -0,0 +1,193 @@
+package fs
+
+import (
+	"context"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the removed objects are moved to /.alist-trash/<random key>/<name> of their storage,
+// or to the native trash if the storage implements driver.Trash
+
+// checkHiddenPath the trash and versions dirs are only accessible through their own api,
+// which set conf.HiddenKey to the ctx, so neither of the paths may be in them
+func checkHiddenPath(ctx context.Context, paths ...string) error {
+	if ctx.Value(conf.HiddenKey) != nil {
+		return nil
+	}
+	for _, path := range paths {
+		if op.IsHiddenMountPath(path) {
+			return errors.WithStack(errs.ObjectNotFound)
+		}
+	}
+	return nil
+}
+
+func shouldTrash(ctx context.Context, actualPath string) bool {
+	if ctx.Value(conf.NoTrashKey) != nil || op.IsHiddenPath(actualPath) {
+		return false
+	}
+	return setting.GetBool(conf.TrashEnabled)
+}
+
+func canTrash(storage driver.Driver) bool {
+	if _, ok := storage.(driver.Trash); ok {
+		return true
+	}
+	_, okMove := storage.(driver.Move)
+	_, okMoveResult := storage.(driver.MoveResult)
+	_, okMkdir := storage.(driver.Mkdir)
+	_, okMkdirResult := storage.(driver.MkdirResult)
+	return (okMove || okMoveResult) && (okMkdir || okMkdirResult)
+}
+
+func trash(ctx context.Context, storage driver.Driver, path, actualPath string) error {
+	if !canTrash(storage) {
+		log.Debugf("storage [%s] can't keep the removed objects, delete %s directly", storage.GetStorage().MountPath, path)
+		if err := op.Remove(ctx, storage, actualPath); err != nil {
+			return err
+		}
+		removeFileVersions(ctx, storage, actualPath)
+		return nil
+	}
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			log.Debugf("%s have been removed", path)
+			return nil
+		}
+		return errors.WithMessage(err, "failed to get object")
+	}
+	item := &model.TrashItem{
+		Path:  path,
+		Name:  obj.GetName(),
+		Size:  obj.GetSize(),
+		IsDir: obj.IsDir(),
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok {
+		item.UserID = user.ID
+	}
+	trashDir := stdpath.Join("/", conf.TrashDirName, random.String(16))
+	if _, ok := storage.(driver.Trash); ok {
+		item.NativeID, err = op.TrashObj(ctx, storage, actualPath)
+		if err != nil {
+			return err
+		}
+	} else {
+		if err = op.MakeDir(ctx, storage, trashDir); err != nil {
+			return errors.WithMessage(err, "failed to make trash dir")
+		}
+		if err = op.Move(ctx, storage, actualPath, trashDir); err != nil {
+			return errors.WithMessage(err, "failed to move object to trash")
+		}
+		item.TrashPath = stdpath.Join(storage.GetStorage().MountPath, trashDir, obj.GetName())
+	}
+	// the versions are kept by the path in the trash dir, even if the object is in the native trash,
+	// so they are restored or purged with the object
+	item.VersionsPath = stdpath.Join(trashDir, obj.GetName())
+	moveFileVersions(storage, actualPath, item.VersionsPath)
+	return op.CreateTrashItem(item)
+}
+
+// RestoreTrash move the object in the trash back to where it was removed from
+func RestoreTrash(ctx context.Context, item *model.TrashItem) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(item.Path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if _, err = op.Get(ctx, storage, actualPath); err == nil {
+		return errors.WithStack(errs.RestoreConflict)
+	}
+	if item.NativeID != "" {
+		err = op.RestoreTrash(ctx, storage, item.NativeID, actualPath)
+	} else {
+		err = restoreFromTrashDir(ctx, storage, item, actualPath)
+	}
+	if err != nil {
+		log.Errorf("failed restore %s: %+v", item.Path, err)
+		return err
+	}
+	if item.VersionsPath != "" {
+		moveFileVersions(storage, item.VersionsPath, actualPath)
+	}
+	return op.DeleteTrashItemById(item.ID)
+}
+
+func restoreFromTrashDir(ctx context.Context, storage driver.Driver, item *model.TrashItem, actualPath string) error {
+	trashStorage, trashActualPath, err := op.GetStorageAndActualPath(item.TrashPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get trash storage")
+	}
+	if trashStorage.GetStorage() != storage.GetStorage() {
+		return errors.WithStack(errs.MoveBetweenTwoStorages)
+	}
+	dstDirPath := stdpath.Dir(actualPath)
+	if err = op.MakeDir(ctx, storage, dstDirPath); err != nil {
+		return errors.WithMessage(err, "failed to make dst dir")
+	}
+	if err = op.Move(ctx, storage, trashActualPath, dstDirPath); err != nil {
+		return err
+	}
+	return op.Remove(ctx, storage, stdpath.Dir(trashActualPath))
+}
+
+// PurgeTrash delete the object in the trash permanently
+func PurgeTrash(ctx context.Context, item *model.TrashItem) error {
+	err := purgeTrash(ctx, item)
+	if err != nil && !errors.Is(err, errs.StorageNotFound) {
+		log.Errorf("failed purge %s: %+v", item.Path, err)
+		return err
+	}
+	return op.DeleteTrashItemById(item.ID)
+}
+
+func purgeTrash(ctx context.Context, item *model.TrashItem) error {
+	storage, _, err := op.GetStorageAndActualPath(item.Path)
+	if err != nil {
+		return err
+	}
+	if item.NativeID != "" {
+		err = op.PurgeTrash(ctx, storage, item.NativeID)
+	} else {
+		var trashActualPath string
+		storage, trashActualPath, err = op.GetStorageAndActualPath(item.TrashPath)
+		if err != nil {
+			return err
+		}
+		err = op.Remove(ctx, storage, stdpath.Dir(trashActualPath))
+	}
+	if err == nil && item.VersionsPath != "" {
+		removeFileVersions(ctx, storage, item.VersionsPath)
+	}
+	return err
+}
+
+// PurgeExpiredTrash purge the objects which have been in the trash longer than the retention days
+func PurgeExpiredTrash(ctx context.Context) {
+	days := setting.GetInt(conf.TrashRetentionDays, 30)
+	if days <= 0 {
+		return
+	}
+	items, err := op.GetTrashItemsDeletedBefore(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Errorf("failed get expired trash items: %+v", err)
+		return
+	}
+	for i := range items {
+		if utils.IsCanceled(ctx) {
+			return
+		}
+		_ = PurgeTrash(ctx, &items[i])
+	}
+}
@@ -0,0 +1,119 @@
+package fs_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+)
+
+func TestHiddenDirs(t *testing.T) {
+	root := setupDedup(t, "/hidden", map[string]string{"a": "a"})
+	for _, dir := range []string{conf.TrashDirName, conf.VersionsDirName} {
+		if err := os.MkdirAll(filepath.Join(root, dir, "key"), 0o777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	for _, dir := range []string{conf.TrashDirName, conf.VersionsDirName} {
+		if err := fs.Remove(ctx, "/hidden/"+dir); !errs.IsObjectNotFound(err) {
+			t.Errorf("expected object not found removing %s, got %v", dir, err)
+		}
+		if err := fs.Rename(ctx, "/hidden/"+dir, "x"); !errs.IsObjectNotFound(err) {
+			t.Errorf("expected object not found renaming %s, got %v", dir, err)
+		}
+		if err := fs.Move(ctx, "/hidden/a", "/hidden/"+dir+"/key"); !errs.IsObjectNotFound(err) {
+			t.Errorf("expected object not found moving into %s, got %v", dir, err)
+		}
+		if _, err := os.Stat(filepath.Join(root, dir, "key")); err != nil {
+			t.Errorf("expected %s to be kept, got %v", dir, err)
+		}
+	}
+	if err := fs.Rename(ctx, "/hidden/a", conf.TrashDirName); !errs.IsObjectNotFound(err) {
+		t.Errorf("expected object not found renaming to the trash dir, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a")); err != nil {
+		t.Errorf("expected a to be kept, got %v", err)
+	}
+}
+
+func TestTrashVersions(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "f"), []byte("v1"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{
+		Driver:     "Local",
+		MountPath:  "/trash_versions",
+		Addition:   `{"root_folder_path":"` + root + `"}`,
+		Versioning: model.Versioning{EnableVersions: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	driver, err := op.GetStorageByMountPath("/trash_versions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("v2")
+	file := &stream.FileStream{
+		Obj:    &model.Object{Name: "f", Size: int64(len(content)), Modified: time.Now()},
+		Reader: io.NopCloser(bytes.NewReader(content)),
+	}
+	if err = op.Put(ctx, driver, "/", file, nil); err != nil {
+		t.Fatalf("failed to put: %+v", err)
+	}
+	countVersions := func() int {
+		versions, err := op.GetFileVersions(driver, "/f")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(versions)
+	}
+	if n := countVersions(); n != 1 {
+		t.Fatalf("expected 1 version before removing, got %d", n)
+	}
+
+	if err = op.SaveSettingItem(&model.SettingItem{Key: conf.TrashEnabled, Value: "true", Type: conf.TypeBool}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = op.DeleteSettingItemByKey(conf.TrashEnabled)
+	})
+	if err = fs.Remove(ctx, "/trash_versions/f"); err != nil {
+		t.Fatalf("failed to remove: %+v", err)
+	}
+	if n := countVersions(); n != 0 {
+		t.Fatalf("expected the versions to be moved to the trash, got %d", n)
+	}
+	items, _, err := op.GetTrashItems(1, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var item *model.TrashItem
+	for i := range items {
+		if items[i].Path == "/trash_versions/f" {
+			item = &items[i]
+		}
+	}
+	if item == nil {
+		t.Fatalf("expected the trash item of f, got %+v", items)
+	}
+	if err = fs.RestoreTrash(ctx, item); err != nil {
+		t.Fatalf("failed to restore: %+v", err)
+	}
+	if n := countVersions(); n != 1 {
+		t.Errorf("expected the version to be restored with the file, got %d", n)
+	}
+}
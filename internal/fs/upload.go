@@ -0,0 +1,246 @@
+package fs
+
+import (
+	"context"
+	"io"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the data of the resumable uploads are kept in <temp dir>/upload-sessions/<id> until they are complete,
+// and the offsets are kept in the db, so the uploads can be resumed after restarting
+
+var (
+	writingSessions   = make(map[string]struct{})
+	writingSessionsMu sync.Mutex
+)
+
+// lockUploadSession make sure a session is written by only one request, return false if it is being written
+func lockUploadSession(id string) bool {
+	writingSessionsMu.Lock()
+	defer writingSessionsMu.Unlock()
+	if _, ok := writingSessions[id]; ok {
+		return false
+	}
+	writingSessions[id] = struct{}{}
+	return true
+}
+
+func unlockUploadSession(id string) {
+	writingSessionsMu.Lock()
+	defer writingSessionsMu.Unlock()
+	delete(writingSessions, id)
+}
+
+func uploadSessionDir() string {
+	return filepath.Join(conf.Conf.TempDir, conf.UploadSessionDirName)
+}
+
+func uploadSessionFile(id string) string {
+	return filepath.Join(uploadSessionDir(), id)
+}
+
+func uploadSessionExpiry() time.Duration {
+	return time.Duration(setting.GetInt(conf.UploadSessionExpiry, 24)) * time.Hour
+}
+
+// UploadSessionExpires return the time when the session will be deleted if it is not written, zero if never
+func UploadSessionExpires(s *model.UploadSession) time.Time {
+	expiry := uploadSessionExpiry()
+	if expiry <= 0 {
+		return time.Time{}
+	}
+	return s.Updated.Add(expiry)
+}
+
+// CreateUploadSession check the destination of the file and create an empty session for it
+func CreateUploadSession(ctx context.Context, s *model.UploadSession) error {
+	if err := checkHiddenPath(ctx, s.Path); err != nil {
+		return err
+	}
+	storage, _, err := op.GetStorageAndActualPath(s.Path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if storage.Config().NoUpload {
+		return errors.WithStack(errs.UploadNotSupported)
+	}
+	if err = CheckQuota(ctx, s.Path, s.Size); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(uploadSessionDir(), 0o777); err != nil {
+		return errors.Wrap(err, "failed create upload session dir")
+	}
+	s.ID = random.String(32)
+	s.Offset = 0
+	s.Updated = time.Now()
+	f, err := os.Create(uploadSessionFile(s.ID))
+	if err != nil {
+		return errors.Wrap(err, "failed create upload session file")
+	}
+	_ = f.Close()
+	if err = op.CreateUploadSession(s); err != nil {
+		_ = os.Remove(uploadSessionFile(s.ID))
+		return err
+	}
+	return nil
+}
+
+// GetUploadSession get the session which has not expired
+func GetUploadSession(id string) (*model.UploadSession, error) {
+	s, err := op.GetUploadSessionById(id)
+	if err != nil {
+		return nil, err
+	}
+	if expires := UploadSessionExpires(s); !expires.IsZero() && expires.Before(time.Now()) {
+		return nil, errors.WithStack(errs.UploadSessionNotFound)
+	}
+	return s, nil
+}
+
+// WriteUploadSession append the data to the session at offset, which must be the size of the received data.
+// The data received before an error are kept, so the client can resume from the new offset of the session.
+func WriteUploadSession(s *model.UploadSession, offset int64, r io.Reader) error {
+	if !lockUploadSession(s.ID) {
+		return errors.WithStack(errs.UploadSessionBusy)
+	}
+	defer unlockUploadSession(s.ID)
+	// the offset may be changed by another request before locked
+	latest, err := op.GetUploadSessionById(s.ID)
+	if err != nil {
+		return err
+	}
+	*s = *latest
+	if offset != s.Offset {
+		return errors.WithStack(errs.UploadOffsetMismatch)
+	}
+	f, err := os.OpenFile(uploadSessionFile(s.ID), os.O_WRONLY, 0o666)
+	if err != nil {
+		return errors.Wrap(err, "failed open upload session file")
+	}
+	defer f.Close()
+	// drop the data written after the offset was saved, if the server was stopped while writing
+	if err = f.Truncate(s.Offset); err != nil {
+		return errors.Wrap(err, "failed truncate upload session file")
+	}
+	if _, err = f.Seek(s.Offset, io.SeekStart); err != nil {
+		return errors.Wrap(err, "failed seek upload session file")
+	}
+	n, copyErr := utils.CopyWithBuffer(f, io.LimitReader(r, s.Size-s.Offset))
+	s.Offset += n
+	s.Updated = time.Now()
+	if err = op.UpdateUploadSession(s); err != nil {
+		return err
+	}
+	return errors.WithStack(copyErr)
+}
+
+// FinishUploadSession put the complete file to its destination. The session is removed once the file is put
+// or handed to an upload task, otherwise it is kept to be finished again.
+func FinishUploadSession(ctx context.Context, s *model.UploadSession) (task.TaskExtensionInfo, error) {
+	if s.Offset != s.Size {
+		return nil, errors.Errorf("upload session is incomplete: %d/%d", s.Offset, s.Size)
+	}
+	if !lockUploadSession(s.ID) {
+		return nil, errors.WithStack(errs.UploadSessionBusy)
+	}
+	defer unlockUploadSession(s.ID)
+	f, err := os.Open(uploadSessionFile(s.ID))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed open upload session file")
+	}
+	dir, name := stdpath.Split(s.Path)
+	file := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     s.Size,
+			Modified: s.Modified,
+			HashInfo: utils.FromString(s.HashInfo),
+		},
+		Mimetype:     s.Mimetype,
+		WebPutAsTask: s.AsTask,
+	}
+	if s.AsTask {
+		// the file is removed by the task after it is put
+		file.SetTmpFile(f)
+		t, err := PutAsTask(ctx, dir, file)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		if err = op.DeleteUploadSessionById(s.ID); err != nil {
+			log.Errorf("failed delete upload session %s: %+v", s.ID, err)
+		}
+		return t, nil
+	}
+	file.Reader = f
+	file.Add(f)
+	if err = PutDirectly(ctx, dir, file, true); err != nil {
+		return nil, err
+	}
+	return nil, removeUploadSession(s.ID)
+}
+
+// TerminateUploadSession remove the session and its data
+func TerminateUploadSession(s *model.UploadSession) error {
+	if !lockUploadSession(s.ID) {
+		return errors.WithStack(errs.UploadSessionBusy)
+	}
+	defer unlockUploadSession(s.ID)
+	return removeUploadSession(s.ID)
+}
+
+func removeUploadSession(id string) error {
+	if err := os.Remove(uploadSessionFile(id)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed remove upload session file")
+	}
+	return op.DeleteUploadSessionById(id)
+}
+
+// PurgeExpiredUploadSessions remove the sessions which have not been written for a long time
+func PurgeExpiredUploadSessions() {
+	expiry := uploadSessionExpiry()
+	if expiry <= 0 {
+		return
+	}
+	before := time.Now().Add(-expiry)
+	sessions, err := op.GetUploadSessionsUpdatedBefore(before)
+	if err != nil {
+		log.Errorf("failed get expired upload sessions: %+v", err)
+		return
+	}
+	for i := range sessions {
+		if err = TerminateUploadSession(&sessions[i]); err != nil {
+			log.Errorf("failed remove expired upload session %s: %+v", sessions[i].ID, err)
+		}
+	}
+	// the data left by the upload tasks which were interrupted by restarting
+	entries, err := os.ReadDir(uploadSessionDir())
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(before) {
+			continue
+		}
+		if _, err = op.GetUploadSessionById(entry.Name()); errors.Is(err, errs.UploadSessionNotFound) {
+			_ = os.Remove(filepath.Join(uploadSessionDir(), entry.Name()))
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package fs
+
+import (
+	"context"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetFileVersions(ctx context.Context, path string) ([]model.FileVersion, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	return op.GetFileVersions(storage, actualPath)
+}
+
+// GetFileVersionPath get the mount path of the version's content
+func GetFileVersionPath(path string, id uint) (string, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get storage")
+	}
+	v, err := op.GetFileVersion(storage, actualPath, id)
+	if err != nil {
+		return "", err
+	}
+	return stdpath.Join(storage.GetStorage().MountPath, v.VersionPath), nil
+}
+
+func RestoreFileVersion(ctx context.Context, path string, id uint) error {
+	if err := checkHiddenPath(ctx, path); err != nil {
+		return err
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	err = op.RestoreFileVersion(ctx, storage, actualPath, id)
+	if err != nil {
+		log.Errorf("failed restore version %d of %s: %+v", id, path, err)
+	}
+	return err
+}
+
+func moveFileVersions(storage driver.Driver, srcActualPath, dstActualPath string) {
+	if err := op.MoveFileVersions(storage, srcActualPath, dstActualPath); err != nil {
+		log.Warnf("failed move the versions of %s to %s: %+v", srcActualPath, dstActualPath, err)
+	}
+}
+
+func removeFileVersions(ctx context.Context, storage driver.Driver, actualPath string) {
+	if err := op.RemoveFileVersions(ctx, storage, actualPath); err != nil {
+		log.Warnf("failed remove the versions of %s: %+v", actualPath, err)
+	}
+}
@@ -0,0 +1,183 @@
+package media
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/dhowden/tag"
+	"github.com/pkg/errors"
+)
+
+// readTags read the id3, flac, mp4 and ogg tags, the files without tags are not errors
+func readTags(rs io.ReadSeeker, info *model.MediaInfo) error {
+	m, err := tag.ReadFrom(rs)
+	if err != nil {
+		if errors.Is(err, tag.ErrNoTagsFound) {
+			return nil
+		}
+		return errors.WithMessage(err, "failed read tags")
+	}
+	info.Title = m.Title()
+	info.Artist = m.Artist()
+	info.Album = m.Album()
+	info.Genre = m.Genre()
+	info.Year = m.Year()
+	info.Track, _ = m.Track()
+	return nil
+}
+
+// id3Size return the size of the id3v2 tag at the beginning, 0 if there is no tag
+func id3Size(ra io.ReaderAt) int64 {
+	header := make([]byte, 10)
+	if _, err := ra.ReadAt(header, 0); err != nil || string(header[0:3]) != "ID3" {
+		return 0
+	}
+	// the size is a syncsafe integer of 7 bits per byte, excluding the header and footer
+	size := int64(header[6])<<21 | int64(header[7])<<14 | int64(header[8])<<7 | int64(header[9])
+	size += 10
+	if header[5]&0x10 != 0 {
+		size += 10
+	}
+	return size
+}
+
+// extractFlac read the stream info block, which is the first metadata block
+func extractFlac(ra io.ReaderAt, size int64, info *model.MediaInfo) error {
+	start := id3Size(ra)
+	buf := make([]byte, 42)
+	if _, err := ra.ReadAt(buf, start); err != nil {
+		return errors.WithMessage(err, "failed read flac stream info")
+	}
+	if string(buf[0:4]) != "fLaC" || buf[4]&0x7F != 0 {
+		return errors.New("invalid flac")
+	}
+	// the sample rate in 20 bits, channels - 1 in 3 bits, bits per sample - 1 in 5 bits and total samples in 36 bits
+	v := binary.BigEndian.Uint64(buf[18:26])
+	info.SampleRate = int(v >> 44)
+	info.Channels = int(v>>41&0x7) + 1
+	if samples := v & (1<<36 - 1); info.SampleRate > 0 {
+		info.Duration = float64(samples) / float64(info.SampleRate)
+	}
+	info.AudioCodec = "flac"
+	return nil
+}
+
+// the bitrates in kbps of mpeg 1 and mpeg 2/2.5 layer 3
+var mp3Bitrates = [2][16]int{
+	{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+	{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+}
+
+// the sample rates of mpeg 1, 2 and 2.5
+var mp3SampleRates = [3][3]int{
+	{44100, 48000, 32000},
+	{22050, 24000, 16000},
+	{11025, 12000, 8000},
+}
+
+// mp3SearchSize is the size of data after the id3 tag searched for the first frame
+const mp3SearchSize = 64 * 1024
+
+// extractMp3 read the first frame of layer 3, the duration is read from the xing or vbri header of the vbr files,
+// and calculated by the bitrate of the cbr files
+func extractMp3(ra io.ReaderAt, size int64, info *model.MediaInfo) error {
+	start := id3Size(ra)
+	buf := make([]byte, mp3SearchSize)
+	n, err := ra.ReadAt(buf, start)
+	if n == 0 && err != nil {
+		return errors.WithMessage(err, "failed read mp3 frame")
+	}
+	buf = buf[:n]
+	for i := 0; i+4 <= len(buf); i++ {
+		if buf[i] != 0xFF || buf[i+1]&0xE0 != 0xE0 {
+			continue
+		}
+		version, layer := buf[i+1]>>3&0x3, buf[i+1]>>1&0x3
+		bitrateIndex, rateIndex := buf[i+2]>>4, buf[i+2]>>2&0x3
+		// only layer 3, and the reserved values are not frames
+		if layer != 1 || version == 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+			continue
+		}
+		mpeg1 := version == 3
+		versionIndex, samplesPerFrame := 1, 576
+		if mpeg1 {
+			versionIndex, samplesPerFrame = 0, 1152
+		}
+		rateRow := map[byte]int{3: 0, 2: 1, 0: 2}[version]
+		info.SampleRate = mp3SampleRates[rateRow][rateIndex]
+		info.Channels = 2
+		mono := buf[i+3]>>6 == 3
+		if mono {
+			info.Channels = 1
+		}
+		info.AudioCodec = "mp3"
+		// the xing header is after the side info, and the vbri header is always at 32 bytes
+		sideInfo := 32
+		switch {
+		case mpeg1 && mono, !mpeg1 && !mono:
+			sideInfo = 17
+		case !mpeg1 && mono:
+			sideInfo = 9
+		}
+		if frames := vbrFrames(buf[i:], sideInfo); frames > 0 {
+			info.Duration = float64(frames) * float64(samplesPerFrame) / float64(info.SampleRate)
+		} else {
+			bitrate := mp3Bitrates[versionIndex][bitrateIndex] * 1000
+			info.Duration = float64(size-start-int64(i)) * 8 / float64(bitrate)
+		}
+		return nil
+	}
+	return errors.New("mp3 frame not found")
+}
+
+// vbrFrames return the count of frames in the xing or vbri header, 0 if not found
+func vbrFrames(frame []byte, sideInfo int) uint32 {
+	if xing := 4 + sideInfo; len(frame) >= xing+12 {
+		tag := string(frame[xing : xing+4])
+		// the frames field is present if the lowest bit of the flags is set
+		if (tag == "Xing" || tag == "Info") && frame[xing+7]&0x1 != 0 {
+			return binary.BigEndian.Uint32(frame[xing+8 : xing+12])
+		}
+	}
+	if vbri := 4 + 32; len(frame) >= vbri+18 && string(frame[vbri:vbri+4]) == "VBRI" {
+		return binary.BigEndian.Uint32(frame[vbri+14 : vbri+18])
+	}
+	return 0
+}
+
+// extractWav read the fmt and the size of the data chunks
+func extractWav(ra io.ReaderAt, size int64, info *model.MediaInfo) error {
+	buf := make([]byte, 16)
+	if _, err := ra.ReadAt(buf[:12], 0); err != nil {
+		return errors.WithMessage(err, "failed read wav header")
+	}
+	if string(buf[0:4]) != "RIFF" || string(buf[8:12]) != "WAVE" {
+		return errors.New("invalid wav")
+	}
+	var byteRate uint32
+	for off := int64(12); off+8 <= size; {
+		if _, err := ra.ReadAt(buf[:8], off); err != nil {
+			return errors.WithMessage(err, "failed read wav chunk")
+		}
+		id, chunkSize := string(buf[0:4]), int64(binary.LittleEndian.Uint32(buf[4:8]))
+		switch id {
+		case "fmt ":
+			if _, err := ra.ReadAt(buf, off+8); err != nil {
+				return errors.WithMessage(err, "failed read wav format")
+			}
+			info.AudioCodec = "pcm"
+			info.Channels = int(binary.LittleEndian.Uint16(buf[2:4]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(buf[4:8]))
+			byteRate = binary.LittleEndian.Uint32(buf[8:12])
+		case "data":
+			if byteRate > 0 {
+				info.Duration = float64(chunkSize) / float64(byteRate)
+			}
+			return nil
+		}
+		// the chunks are aligned to 2 bytes
+		off += 8 + chunkSize + chunkSize%2
+	}
+	return nil
+}
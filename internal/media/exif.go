@@ -0,0 +1,263 @@
+package media
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	tagImageWidth         = 0x0100
+	tagImageLength        = 0x0101
+	tagMake               = 0x010F
+	tagModel              = 0x0110
+	tagOrientation        = 0x0112
+	tagDateTime           = 0x0132
+	tagExifIFD            = 0x8769
+	tagGPSIFD             = 0x8825
+	tagDateTimeOriginal   = 0x9003
+	tagOffsetTimeOriginal = 0x9011
+	tagPixelXDimension    = 0xA002
+	tagPixelYDimension    = 0xA003
+	tagGPSLatitudeRef     = 0x0001
+	tagGPSLatitude        = 0x0002
+	tagGPSLongitudeRef    = 0x0003
+	tagGPSLongitude       = 0x0004
+
+	exifTimeLayout = "2006:01:02 15:04:05"
+	// avoid looping on the broken ifds
+	maxIFDEntries = 1024
+)
+
+// the sizes of the tiff field types
+var typeSizes = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+type ifdEntry struct {
+	typ   uint16
+	count uint32
+	// the value if it fits in 4 bytes, or the offset of it
+	raw []byte
+}
+
+// tiff read the ifds of the tiff structure, which begins at base, the offsets in it are relative to base
+type tiff struct {
+	ra    io.ReaderAt
+	base  int64
+	order binary.ByteOrder
+}
+
+// parseTIFF read the exif of the tiff structure at base, it is used by the tiff images and the exif of jpeg and heif
+func parseTIFF(ra io.ReaderAt, base int64, info *model.MediaInfo) error {
+	header := make([]byte, 8)
+	if _, err := ra.ReadAt(header, base); err != nil {
+		return errors.WithMessage(err, "failed read tiff header")
+	}
+	t := &tiff{ra: ra, base: base}
+	switch string(header[0:4]) {
+	case "II*\x00":
+		t.order = binary.LittleEndian
+	case "MM\x00*":
+		t.order = binary.BigEndian
+	default:
+		return errors.New("invalid tiff header")
+	}
+	ifd0, err := t.readIFD(t.order.Uint32(header[4:8]))
+	if err != nil {
+		return err
+	}
+	info.Make = t.string(ifd0[tagMake])
+	info.Model = t.string(ifd0[tagModel])
+	info.Orientation = int(t.uint(ifd0[tagOrientation]))
+	info.Width = int(t.uint(ifd0[tagImageWidth]))
+	info.Height = int(t.uint(ifd0[tagImageLength]))
+	taken := t.string(ifd0[tagDateTime])
+	if e, ok := ifd0[tagExifIFD]; ok {
+		exif, err := t.readIFD(t.uint(e))
+		if err != nil {
+			return err
+		}
+		if original := t.string(exif[tagDateTimeOriginal]); original != "" {
+			taken = original
+		}
+		if w, h := t.uint(exif[tagPixelXDimension]), t.uint(exif[tagPixelYDimension]); w > 0 && h > 0 {
+			info.Width, info.Height = int(w), int(h)
+		}
+		info.Taken = parseExifTime(taken, t.string(exif[tagOffsetTimeOriginal]))
+	} else {
+		info.Taken = parseExifTime(taken, "")
+	}
+	if e, ok := ifd0[tagGPSIFD]; ok {
+		gps, err := t.readIFD(t.uint(e))
+		if err != nil {
+			return err
+		}
+		info.Latitude = t.coordinate(gps[tagGPSLatitude], t.string(gps[tagGPSLatitudeRef]), "S")
+		info.Longitude = t.coordinate(gps[tagGPSLongitude], t.string(gps[tagGPSLongitudeRef]), "W")
+	}
+	return nil
+}
+
+func (t *tiff) readIFD(offset uint32) (map[uint16]*ifdEntry, error) {
+	buf := make([]byte, 2)
+	if _, err := t.ra.ReadAt(buf, t.base+int64(offset)); err != nil {
+		return nil, errors.WithMessage(err, "failed read ifd")
+	}
+	n := t.order.Uint16(buf)
+	if n > maxIFDEntries {
+		return nil, errors.New("too many ifd entries")
+	}
+	buf = make([]byte, int(n)*12)
+	if _, err := t.ra.ReadAt(buf, t.base+int64(offset)+2); err != nil {
+		return nil, errors.WithMessage(err, "failed read ifd")
+	}
+	entries := make(map[uint16]*ifdEntry, n)
+	for i := 0; i < int(n); i++ {
+		e := buf[i*12 : (i+1)*12]
+		entries[t.order.Uint16(e[0:2])] = &ifdEntry{
+			typ:   t.order.Uint16(e[2:4]),
+			count: t.order.Uint32(e[4:8]),
+			raw:   e[8:12],
+		}
+	}
+	return entries, nil
+}
+
+// value return the bytes of the value of the entry, nil is returned if it can't be read
+func (t *tiff) value(e *ifdEntry) []byte {
+	if e == nil {
+		return nil
+	}
+	size := typeSizes[e.typ] * e.count
+	if size <= 4 {
+		return e.raw[:size]
+	}
+	// the values are small, except the ones we never read
+	if size > 1<<16 {
+		return nil
+	}
+	buf := make([]byte, size)
+	if _, err := t.ra.ReadAt(buf, t.base+int64(t.order.Uint32(e.raw))); err != nil {
+		return nil
+	}
+	return buf
+}
+
+func (t *tiff) string(e *ifdEntry) string {
+	if e == nil || (e.typ != 2 && e.typ != 7) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(t.value(e)), "\x00"))
+}
+
+func (t *tiff) uint(e *ifdEntry) uint32 {
+	if e == nil || e.count == 0 {
+		return 0
+	}
+	v := t.value(e)
+	switch {
+	case e.typ == 3 && len(v) >= 2:
+		return uint32(t.order.Uint16(v))
+	case e.typ == 4 && len(v) >= 4:
+		return t.order.Uint32(v)
+	}
+	return 0
+}
+
+// coordinate convert the degrees, minutes and seconds in rationals to the decimal degrees
+func (t *tiff) coordinate(e *ifdEntry, ref, negative string) *float64 {
+	if e == nil || e.typ != 5 || e.count != 3 {
+		return nil
+	}
+	v := t.value(e)
+	if len(v) < 24 {
+		return nil
+	}
+	var res float64
+	for i, unit := range []float64{1, 60, 3600} {
+		num, den := t.order.Uint32(v[i*8:i*8+4]), t.order.Uint32(v[i*8+4:i*8+8])
+		if den == 0 {
+			if num == 0 {
+				continue
+			}
+			return nil
+		}
+		res += float64(num) / float64(den) / unit
+	}
+	if strings.EqualFold(ref, negative) {
+		res = -res
+	}
+	return &res
+}
+
+// parseExifTime parse the time of the exif, which has no time zone unless the offset is recorded,
+// it is taken as utc in that case
+func parseExifTime(s, offset string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	loc := time.UTC
+	if zone, err := time.Parse("-07:00", offset); err == nil {
+		loc = zone.Location()
+	}
+	taken, err := time.ParseInLocation(exifTimeLayout, s, loc)
+	if err != nil || taken.Year() < 1900 {
+		return nil
+	}
+	return &taken
+}
+
+// extractJpeg read the exif in the app1 segment and the size in the sof segment
+func extractJpeg(ra io.ReaderAt, size int64, info *model.MediaInfo) error {
+	buf := make([]byte, 10)
+	if _, err := ra.ReadAt(buf[:2], 0); err != nil || buf[0] != 0xFF || buf[1] != 0xD8 {
+		return errors.New("invalid jpeg")
+	}
+	var width, height int
+	for off := int64(2); off+4 <= size; {
+		if _, err := ra.ReadAt(buf[:4], off); err != nil {
+			return errors.WithMessage(err, "failed read jpeg segment")
+		}
+		if buf[0] != 0xFF {
+			return errors.Errorf("invalid jpeg marker at %d", off)
+		}
+		marker := buf[1]
+		if marker == 0xFF {
+			// fill byte
+			off++
+			continue
+		}
+		if marker == 0xD9 || marker == 0xDA {
+			// the image data begins
+			break
+		}
+		length := int64(binary.BigEndian.Uint16(buf[2:4]))
+		switch {
+		case marker == 0xE1 && length > 8:
+			if _, err := ra.ReadAt(buf[:6], off+4); err != nil {
+				return errors.WithMessage(err, "failed read jpeg segment")
+			}
+			if string(buf[:6]) == "Exif\x00\x00" {
+				if err := parseTIFF(ra, off+10, info); err != nil {
+					return err
+				}
+			}
+		case marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC:
+			// start of frame: precision, height, width
+			if _, err := ra.ReadAt(buf[:5], off+4); err != nil {
+				return errors.WithMessage(err, "failed read jpeg segment")
+			}
+			height = int(binary.BigEndian.Uint16(buf[1:3]))
+			width = int(binary.BigEndian.Uint16(buf[3:5]))
+		}
+		off += 2 + length
+	}
+	// the size in the frame is more reliable than the one in the exif
+	if width > 0 && height > 0 {
+		info.Width, info.Height = width, height
+	}
+	return nil
+}
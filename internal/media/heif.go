@@ -0,0 +1,178 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// extractHeif read the size from the ispe properties and the exif from the item of type Exif
+func extractHeif(ra io.ReaderAt, size int64, info *model.MediaInfo) error {
+	meta, err := findBox(ra, 0, size, "meta")
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		return errors.New("meta not found")
+	}
+	// meta is a full box, the children begin after the version and flags
+	var exifID uint32
+	var iloc []byte
+	err = readBoxes(ra, meta.data+4, meta.end, func(b box) (bool, error) {
+		switch b.typ {
+		case "iinf":
+			data, err := readBox(ra, &b, maxMp4BoxSize)
+			if err != nil {
+				return false, err
+			}
+			exifID = findExifItem(data)
+		case "iloc":
+			data, err := readBox(ra, &b, maxMp4BoxSize)
+			if err != nil {
+				return false, err
+			}
+			iloc = data
+		case "iprp":
+			// the largest one is the primary image, the others are the thumbnails and tiles
+			return true, readBoxes(ra, b.data, b.end, func(b box) (bool, error) {
+				if b.typ != "ipco" {
+					return true, nil
+				}
+				return false, readBoxes(ra, b.data, b.end, func(b box) (bool, error) {
+					if b.typ != "ispe" {
+						return true, nil
+					}
+					data, err := readBox(ra, &b, maxMp4BoxSize)
+					if err != nil || len(data) < 12 {
+						return false, err
+					}
+					w, h := int(binary.BigEndian.Uint32(data[4:8])), int(binary.BigEndian.Uint32(data[8:12]))
+					if w*h > info.Width*info.Height {
+						info.Width, info.Height = w, h
+					}
+					return true, nil
+				})
+			})
+		}
+		return true, nil
+	})
+	if err != nil || exifID == 0 || iloc == nil {
+		return err
+	}
+	offset, ok := findItemOffset(iloc, exifID)
+	if !ok {
+		return nil
+	}
+	// the exif item begins with the offset of the tiff header after it
+	buf := make([]byte, 4)
+	if _, err = ra.ReadAt(buf, offset); err != nil {
+		return errors.WithMessage(err, "failed read exif item")
+	}
+	width, height := info.Width, info.Height
+	if err = parseTIFF(ra, offset+4+int64(binary.BigEndian.Uint32(buf)), info); err != nil {
+		return err
+	}
+	if width > 0 && height > 0 {
+		info.Width, info.Height = width, height
+	}
+	return nil
+}
+
+// findExifItem return the id of the item of type Exif in iinf, 0 if not found
+func findExifItem(iinf []byte) uint32 {
+	if len(iinf) < 6 {
+		return 0
+	}
+	start := int64(6)
+	if iinf[0] > 0 {
+		start = 8
+	}
+	var id uint32
+	_ = readBoxes(bytes.NewReader(iinf), start, int64(len(iinf)), func(b box) (bool, error) {
+		if b.typ != "infe" {
+			return true, nil
+		}
+		infe := iinf[b.data:b.end]
+		// version 2 has 16 bits item id and version 3 has 32 bits, the older ones have no item type
+		switch {
+		case len(infe) >= 12 && infe[0] == 2 && string(infe[8:12]) == "Exif":
+			id = uint32(binary.BigEndian.Uint16(infe[4:6]))
+		case len(infe) >= 14 && infe[0] == 3 && string(infe[10:14]) == "Exif":
+			id = binary.BigEndian.Uint32(infe[4:8])
+		}
+		return id == 0, nil
+	})
+	return id
+}
+
+// findItemOffset return the offset of the first extent of the item in iloc, only the items in the file are supported
+func findItemOffset(iloc []byte, id uint32) (int64, bool) {
+	r := &byteCursor{data: iloc}
+	version := r.uint(1)
+	r.skip(3)
+	sizes := r.uint(1)
+	offsetSize, lengthSize := int(sizes>>4), int(sizes&0xF)
+	sizes = r.uint(1)
+	baseOffsetSize, indexSize := int(sizes>>4), 0
+	if version == 1 || version == 2 {
+		indexSize = int(sizes & 0xF)
+	}
+	itemIDSize, countSize := 2, 2
+	if version == 2 {
+		itemIDSize, countSize = 4, 4
+	}
+	count := r.uint(countSize)
+	for i := uint64(0); i < count && !r.failed; i++ {
+		itemID := r.uint(itemIDSize)
+		method := uint64(0)
+		if version == 1 || version == 2 {
+			method = r.uint(2) & 0xF
+		}
+		r.skip(2)
+		base := r.uint(baseOffsetSize)
+		extents := r.uint(2)
+		var first int64 = -1
+		for j := uint64(0); j < extents && !r.failed; j++ {
+			r.skip(indexSize)
+			offset := r.uint(offsetSize)
+			r.skip(lengthSize)
+			if j == 0 {
+				first = int64(base + offset)
+			}
+		}
+		if uint32(itemID) == id {
+			return first, !r.failed && method == 0 && first >= 0
+		}
+	}
+	return 0, false
+}
+
+// byteCursor read the big endian numbers of variable sizes, failed is set once it reads beyond the data
+type byteCursor struct {
+	data   []byte
+	pos    int
+	failed bool
+}
+
+func (c *byteCursor) uint(size int) uint64 {
+	if c.pos+size > len(c.data) {
+		c.failed = true
+		return 0
+	}
+	var v uint64
+	for _, b := range c.data[c.pos : c.pos+size] {
+		v = v<<8 | uint64(b)
+	}
+	c.pos += size
+	return v
+}
+
+func (c *byteCursor) skip(size int) {
+	c.pos += size
+	if c.pos > len(c.data) {
+		c.failed = true
+	}
+}
@@ -0,0 +1,80 @@
+package media
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// box is a box of the iso base media file format used by mp4, mov and heif,
+// the offsets are in the whole file
+type box struct {
+	typ string
+	// the offset of the content after the header
+	data int64
+	end  int64
+}
+
+// readBoxes iterate the boxes between start and end until fn returns false
+func readBoxes(ra io.ReaderAt, start, end int64, fn func(b box) (bool, error)) error {
+	buf := make([]byte, 8)
+	for off := start; off+8 <= end; {
+		if _, err := ra.ReadAt(buf, off); err != nil {
+			return errors.WithMessage(err, "failed read box header")
+		}
+		size := int64(binary.BigEndian.Uint32(buf[0:4]))
+		b := box{typ: string(buf[4:8]), data: off + 8}
+		switch size {
+		case 0:
+			// extends to the end
+			size = end - off
+		case 1:
+			if _, err := ra.ReadAt(buf, off+8); err != nil {
+				return errors.WithMessage(err, "failed read box size")
+			}
+			size = int64(binary.BigEndian.Uint64(buf))
+			b.data += 8
+		}
+		if size < b.data-off || size > end-off {
+			return errors.Errorf("invalid box %q at %d", b.typ, off)
+		}
+		b.end = off + size
+		next, err := fn(b)
+		if err != nil || !next {
+			return err
+		}
+		off = b.end
+	}
+	return nil
+}
+
+// findBox descend the path of the box types from the boxes between start and end, nil is returned if not found
+func findBox(ra io.ReaderAt, start, end int64, path ...string) (*box, error) {
+	var found *box
+	err := readBoxes(ra, start, end, func(b box) (bool, error) {
+		if b.typ != path[0] {
+			return true, nil
+		}
+		if len(path) == 1 {
+			found = &b
+			return false, nil
+		}
+		var err error
+		found, err = findBox(ra, b.data, b.end, path[1:]...)
+		return false, err
+	})
+	return found, err
+}
+
+// readBox read the content of the box, the boxes larger than the limit are rejected
+func readBox(ra io.ReaderAt, b *box, limit int64) ([]byte, error) {
+	if b.end-b.data > limit {
+		return nil, errors.Errorf("box %q is too large", b.typ)
+	}
+	buf := make([]byte, b.end-b.data)
+	if _, err := ra.ReadAt(buf, b.data); err != nil {
+		return nil, errors.WithMessagef(err, "failed read box %q", b.typ)
+	}
+	return buf, nil
+}
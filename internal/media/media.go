@@ -0,0 +1,178 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
+)
+
+type extractor struct {
+	format string
+	// extract only read the needed parts of the file by ReadAt
+	extract func(ra io.ReaderAt, size int64, info *model.MediaInfo) error
+	// the tags are read by seeking
+	tags bool
+}
+
+var extractors = map[string]extractor{
+	"jpg":  {format: "jpeg", extract: extractJpeg},
+	"jpeg": {format: "jpeg", extract: extractJpeg},
+	"tif":  {format: "tiff", extract: extractTiff},
+	"tiff": {format: "tiff", extract: extractTiff},
+	"dng":  {format: "dng", extract: extractTiff},
+	"nef":  {format: "nef", extract: extractTiff},
+	"arw":  {format: "arw", extract: extractTiff},
+	"cr2":  {format: "cr2", extract: extractTiff},
+	"heic": {format: "heif", extract: extractHeif},
+	"heif": {format: "heif", extract: extractHeif},
+	"avif": {format: "avif", extract: extractHeif},
+	"png":  {format: "png", extract: extractImageConfig},
+	"gif":  {format: "gif", extract: extractImageConfig},
+	"webp": {format: "webp", extract: extractImageConfig},
+	"bmp":  {format: "bmp", extract: extractImageConfig},
+	"mp4":  {format: "mp4", extract: extractMp4},
+	"m4v":  {format: "mp4", extract: extractMp4},
+	"mov":  {format: "mov", extract: extractMp4},
+	"3gp":  {format: "3gp", extract: extractMp4},
+	"m4a":  {format: "m4a", extract: extractMp4, tags: true},
+	"mkv":  {format: "matroska", extract: extractMkv},
+	"webm": {format: "webm", extract: extractMkv},
+	"mp3":  {format: "mp3", extract: extractMp3, tags: true},
+	"flac": {format: "flac", extract: extractFlac, tags: true},
+	"wav":  {format: "wav", extract: extractWav},
+	"ogg":  {format: "ogg", tags: true},
+	"opus": {format: "opus", tags: true},
+}
+
+var mediaG singleflight.Group[*model.MediaInfo]
+
+// Supported check whether the media info of the file can be extracted
+func Supported(name string) bool {
+	_, ok := extractors[utils.Ext(name)]
+	return ok
+}
+
+// Get return the media info of the file of the path, which is extracted on the first access and cached in the db
+func Get(ctx context.Context, path string) (*model.MediaInfo, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get file")
+	}
+	return GetByObj(ctx, storage, actualPath, path, obj)
+}
+
+// GetByObj is the same as Get, but the obj has been got
+func GetByObj(ctx context.Context, storage driver.Driver, actualPath, path string, obj model.Obj) (*model.MediaInfo, error) {
+	if obj.IsDir() || !Supported(obj.GetName()) {
+		return nil, errors.WithStack(errs.NotSupport)
+	}
+	meta, err := db.GetMediaMeta(path)
+	if err != nil {
+		return nil, err
+	}
+	if meta != nil && meta.Size == obj.GetSize() && meta.Modified.Unix() == obj.ModTime().Unix() {
+		var info model.MediaInfo
+		if err = json.Unmarshal([]byte(meta.Info), &info); err == nil {
+			return &info, nil
+		}
+	}
+	info, err, _ := mediaG.Do(path, func() (*model.MediaInfo, error) {
+		info, err := extract(ctx, storage, actualPath, obj)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(info)
+		if err != nil {
+			return nil, err
+		}
+		err = db.SaveMediaMeta(&model.MediaMeta{
+			Path:     path,
+			Size:     obj.GetSize(),
+			Modified: obj.ModTime(),
+			Info:     string(data),
+		})
+		if err != nil {
+			log.Warnf("failed save media info of %s: %+v", path, err)
+		}
+		return info, nil
+	})
+	return info, err
+}
+
+// extract read the media info of the file, the errors of parsing are logged instead of returned,
+// so that the broken files are not read again until they change
+func extract(ctx context.Context, storage driver.Driver, actualPath string, obj model.Obj) (*model.MediaInfo, error) {
+	link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get link")
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Obj: obj, Ctx: ctx}, link)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get stream")
+	}
+	defer ss.Close()
+	reader, err := stream.NewReadAtSeeker(ss, 0)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get reader")
+	}
+	if r, ok := reader.(*stream.RangeReadReadAtSeeker); ok {
+		r.InitHeadCache()
+	}
+	e := extractors[utils.Ext(obj.GetName())]
+	info := &model.MediaInfo{Format: e.format}
+	if e.extract != nil {
+		if err = e.extract(reader, obj.GetSize(), info); err != nil {
+			log.Warnf("failed extract media info of %s: %+v", obj.GetName(), err)
+		}
+	}
+	if e.tags {
+		if _, err = reader.Seek(0, io.SeekStart); err == nil {
+			err = readTags(reader, info)
+		}
+		if err != nil {
+			log.Warnf("failed read tags of %s: %+v", obj.GetName(), err)
+		}
+	}
+	// the width and height of the displayed image
+	if info.Orientation >= 5 && info.Orientation <= 8 {
+		info.Width, info.Height = info.Height, info.Width
+	}
+	return info, nil
+}
+
+func extractTiff(ra io.ReaderAt, size int64, info *model.MediaInfo) error {
+	return parseTIFF(ra, 0, info)
+}
+
+func extractImageConfig(ra io.ReaderAt, size int64, info *model.MediaInfo) error {
+	c, _, err := image.DecodeConfig(io.NewSectionReader(ra, 0, size))
+	if err != nil {
+		return errors.WithMessage(err, "failed decode image config")
+	}
+	info.Width, info.Height = c.Width, c.Height
+	return nil
+}
@@ -0,0 +1,116 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+// exifJpeg build a jpeg with the exif of make, orientation, the original time and gps, and a frame of 40x30
+func exifJpeg() []byte {
+	le := binary.LittleEndian
+	var tiff bytes.Buffer
+	tiff.WriteString("II*\x00")
+	_ = binary.Write(&tiff, le, uint32(8))
+	// ifd0 at 8: make, orientation, exif ifd, gps ifd
+	const ifd0Len = 2 + 4*12 + 4
+	makeOff := uint32(8 + ifd0Len)
+	exifOff := makeOff + 6
+	exifLen := uint32(2 + 2*12 + 4)
+	timeOff := exifOff + exifLen
+	gpsOff := timeOff + 20
+	gpsLen := uint32(2 + 4*12 + 4)
+	latOff := gpsOff + gpsLen
+	entry := func(tag, typ uint16, count, value uint32) {
+		_ = binary.Write(&tiff, le, tag)
+		_ = binary.Write(&tiff, le, typ)
+		_ = binary.Write(&tiff, le, count)
+		_ = binary.Write(&tiff, le, value)
+	}
+	_ = binary.Write(&tiff, le, uint16(4))
+	entry(tagMake, 2, 6, makeOff)
+	entry(tagOrientation, 3, 1, 6)
+	entry(tagExifIFD, 4, 1, exifOff)
+	entry(tagGPSIFD, 4, 1, gpsOff)
+	_ = binary.Write(&tiff, le, uint32(0))
+	tiff.WriteString("Maker\x00")
+	_ = binary.Write(&tiff, le, uint16(2))
+	entry(tagDateTimeOriginal, 2, 20, timeOff)
+	entry(tagOffsetTimeOriginal, 2, 4, binary.LittleEndian.Uint32([]byte("+08\x00")))
+	_ = binary.Write(&tiff, le, uint32(0))
+	tiff.WriteString("2024:05:06 07:08:09\x00")
+	_ = binary.Write(&tiff, le, uint16(4))
+	entry(tagGPSLatitudeRef, 2, 2, uint32('N'))
+	entry(tagGPSLatitude, 5, 3, latOff)
+	entry(tagGPSLongitudeRef, 2, 2, uint32('W'))
+	entry(tagGPSLongitude, 5, 3, latOff)
+	_ = binary.Write(&tiff, le, uint32(0))
+	// 30 degrees 15 minutes 0 seconds
+	for _, v := range []uint32{30, 1, 15, 1, 0, 1} {
+		_ = binary.Write(&tiff, le, v)
+	}
+
+	var b bytes.Buffer
+	b.Write([]byte{0xFF, 0xD8, 0xFF, 0xE1})
+	_ = binary.Write(&b, binary.BigEndian, uint16(2+6+tiff.Len()))
+	b.WriteString("Exif\x00\x00")
+	b.Write(tiff.Bytes())
+	// sof0: precision 8, height 30, width 40, 1 component
+	b.Write([]byte{0xFF, 0xC0, 0x00, 0x0B, 0x08, 0x00, 0x1E, 0x00, 0x28, 0x01, 0x01, 0x11, 0x00})
+	b.Write([]byte{0xFF, 0xDA})
+	return b.Bytes()
+}
+
+func TestExtractJpeg(t *testing.T) {
+	data := exifJpeg()
+	var info model.MediaInfo
+	if err := extractJpeg(bytes.NewReader(data), int64(len(data)), &info); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if info.Width != 40 || info.Height != 30 || info.Make != "Maker" || info.Orientation != 6 {
+		t.Errorf("unexpected info %+v", info)
+	}
+	// the time in the offset is "+08", which is not a valid offset, so it is utc
+	if want := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC); info.Taken == nil || !info.Taken.Equal(want) {
+		t.Errorf("expect taken %v, got %v", want, info.Taken)
+	}
+	if info.Latitude == nil || math.Abs(*info.Latitude-30.25) > 1e-9 {
+		t.Errorf("unexpected latitude %v", info.Latitude)
+	}
+	if info.Longitude == nil || math.Abs(*info.Longitude+30.25) > 1e-9 {
+		t.Errorf("unexpected longitude %v", info.Longitude)
+	}
+}
+
+func mp4Box(typ string, children ...[]byte) []byte {
+	content := bytes.Join(children, nil)
+	b := make([]byte, 8, 8+len(content))
+	binary.BigEndian.PutUint32(b, uint32(8+len(content)))
+	copy(b[4:], typ)
+	return append(b, content...)
+}
+
+func TestExtractMp4(t *testing.T) {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], 61500)
+	hdlr := make([]byte, 24)
+	copy(hdlr[8:], "vide")
+	entry := make([]byte, 78)
+	binary.BigEndian.PutUint16(entry[24:], 1920)
+	binary.BigEndian.PutUint16(entry[26:], 1080)
+	stsd := append([]byte{0, 0, 0, 0, 0, 0, 0, 1}, mp4Box("avc1", entry)...)
+	trak := mp4Box("trak", mp4Box("mdia", mp4Box("hdlr", hdlr), mp4Box("minf", mp4Box("stbl", mp4Box("stsd", stsd)))))
+	data := append(mp4Box("ftyp", []byte("isom")), mp4Box("moov", mp4Box("mvhd", mvhd), trak)...)
+	var info model.MediaInfo
+	if err := extractMp4(bytes.NewReader(data), int64(len(data)), &info); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if info.Duration != 61.5 || info.Width != 1920 || info.Height != 1080 || info.VideoCodec != "avc1" {
+		t.Errorf("unexpected info %+v", info)
+	}
+}
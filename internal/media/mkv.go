@@ -0,0 +1,243 @@
+package media
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	ebmlHeaderID        = 0x1A45DFA3
+	segmentID           = 0x18538067
+	infoID              = 0x1549A966
+	tracksID            = 0x1654AE6B
+	clusterID           = 0x1F43B675
+	timestampScaleID    = 0x2AD7B1
+	durationID          = 0x4489
+	dateUTCID           = 0x4461
+	trackEntryID        = 0xAE
+	trackTypeID         = 0x83
+	codecID             = 0x86
+	videoID             = 0xE0
+	audioID             = 0xE1
+	pixelWidthID        = 0xB0
+	pixelHeightID       = 0xBA
+	samplingFrequencyID = 0xB5
+	channelsID          = 0x9F
+	maxEbmlElementSize  = 1 << 20
+	// avoid looping on the broken files
+	maxSegmentChildren = 1024
+)
+
+// the epoch of DateUTC in matroska
+var matroskaEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type ebmlElement struct {
+	id   uint64
+	data int64
+	// -1 if the size is unknown
+	size int64
+}
+
+// readElement read the header of the element at off
+func readElement(ra io.ReaderAt, off int64) (*ebmlElement, error) {
+	buf := make([]byte, 12)
+	n, err := ra.ReadAt(buf, off)
+	if n == 0 && err != nil {
+		return nil, errors.WithMessage(err, "failed read ebml element")
+	}
+	id, idLen := readVint(buf[:n], false)
+	if idLen == 0 {
+		return nil, errors.Errorf("invalid ebml id at %d", off)
+	}
+	size, sizeLen := readVint(buf[idLen:n], true)
+	if sizeLen == 0 {
+		return nil, errors.Errorf("invalid ebml size at %d", off)
+	}
+	e := &ebmlElement{id: id, data: off + int64(idLen+sizeLen), size: int64(size)}
+	// all the bits of the value are set
+	if size == 1<<(7*sizeLen)-1 {
+		e.size = -1
+	}
+	return e, nil
+}
+
+// readVint read the variable size integer, the length marker is removed from the sizes but kept in the ids
+func readVint(b []byte, isSize bool) (uint64, int) {
+	if len(b) == 0 || b[0] == 0 {
+		return 0, 0
+	}
+	length := 1
+	for mask := byte(0x80); b[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 || length > len(b) {
+		return 0, 0
+	}
+	v := uint64(b[0])
+	if isSize {
+		v &= uint64(0xFF >> length)
+	}
+	for _, c := range b[1:length] {
+		v = v<<8 | uint64(c)
+	}
+	return v, length
+}
+
+// readChildren iterate the elements in the data, which is the content of a master element
+func readChildren(data []byte, fn func(id uint64, value []byte)) {
+	for len(data) > 0 {
+		id, idLen := readVint(data, false)
+		if idLen == 0 {
+			return
+		}
+		size, sizeLen := readVint(data[idLen:], true)
+		if sizeLen == 0 || uint64(len(data)-idLen-sizeLen) < size {
+			return
+		}
+		start := idLen + sizeLen
+		fn(id, data[start:start+int(size)])
+		data = data[start+int(size):]
+	}
+}
+
+func ebmlUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func ebmlFloat(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+// extractMkv read the duration from the segment info and the codecs and sizes from the tracks,
+// which are before the clusters in most of the files
+func extractMkv(ra io.ReaderAt, size int64, info *model.MediaInfo) error {
+	header, err := readElement(ra, 0)
+	if err != nil {
+		return err
+	}
+	if header.id != ebmlHeaderID || header.size < 0 {
+		return errors.New("invalid ebml header")
+	}
+	segment, err := readElement(ra, header.data+header.size)
+	if err != nil {
+		return err
+	}
+	if segment.id != segmentID {
+		return errors.New("segment not found")
+	}
+	end := size
+	if segment.size >= 0 && segment.data+segment.size < end {
+		end = segment.data + segment.size
+	}
+	var foundInfo, foundTracks bool
+	off := segment.data
+	for i := 0; i < maxSegmentChildren && off < end && !(foundInfo && foundTracks); i++ {
+		e, err := readElement(ra, off)
+		if err != nil {
+			return err
+		}
+		if e.id == clusterID || e.size < 0 {
+			break
+		}
+		if e.id == infoID || e.id == tracksID {
+			if e.size > maxEbmlElementSize {
+				return errors.New("ebml element is too large")
+			}
+			data := make([]byte, e.size)
+			if _, err = ra.ReadAt(data, e.data); err != nil {
+				return errors.WithMessage(err, "failed read ebml element")
+			}
+			if e.id == infoID {
+				foundInfo = true
+				parseMkvInfo(data, info)
+			} else {
+				foundTracks = true
+				parseMkvTracks(data, info)
+			}
+		}
+		off = e.data + e.size
+	}
+	return nil
+}
+
+func parseMkvInfo(data []byte, info *model.MediaInfo) {
+	scale := uint64(1000000)
+	var duration float64
+	readChildren(data, func(id uint64, value []byte) {
+		switch id {
+		case timestampScaleID:
+			if v := ebmlUint(value); v > 0 {
+				scale = v
+			}
+		case durationID:
+			duration = ebmlFloat(value)
+		case dateUTCID:
+			if len(value) == 8 {
+				taken := matroskaEpoch.Add(time.Duration(int64(binary.BigEndian.Uint64(value))))
+				info.Taken = &taken
+			}
+		}
+	})
+	// the duration is in the unit of the timestamp scale, which is in nanoseconds
+	info.Duration = duration * float64(scale) / 1e9
+}
+
+func parseMkvTracks(data []byte, info *model.MediaInfo) {
+	readChildren(data, func(id uint64, entry []byte) {
+		if id != trackEntryID {
+			return
+		}
+		var trackType uint64
+		var codec string
+		var video, audio []byte
+		readChildren(entry, func(id uint64, value []byte) {
+			switch id {
+			case trackTypeID:
+				trackType = ebmlUint(value)
+			case codecID:
+				codec = string(value)
+			case videoID:
+				video = value
+			case audioID:
+				audio = value
+			}
+		})
+		switch {
+		case trackType == 1 && info.VideoCodec == "":
+			info.VideoCodec = codec
+			readChildren(video, func(id uint64, value []byte) {
+				switch id {
+				case pixelWidthID:
+					info.Width = int(ebmlUint(value))
+				case pixelHeightID:
+					info.Height = int(ebmlUint(value))
+				}
+			})
+		case trackType == 2 && info.AudioCodec == "":
+			info.AudioCodec = codec
+			readChildren(audio, func(id uint64, value []byte) {
+				switch id {
+				case samplingFrequencyID:
+					info.SampleRate = int(ebmlFloat(value))
+				case channelsID:
+					info.Channels = int(ebmlUint(value))
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package media
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// the boxes in moov are small, except the sample tables which are not read
+const maxMp4BoxSize = 1 << 20
+
+// the epoch of the times in mp4
+var mp4Epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// extractMp4 read the duration from mvhd, and the codecs and sizes of the tracks
+func extractMp4(ra io.ReaderAt, size int64, info *model.MediaInfo) error {
+	moov, err := findBox(ra, 0, size, "moov")
+	if err != nil {
+		return err
+	}
+	if moov == nil {
+		return errors.New("moov not found")
+	}
+	return readBoxes(ra, moov.data, moov.end, func(b box) (bool, error) {
+		switch b.typ {
+		case "mvhd":
+			data, err := readBox(ra, &b, maxMp4BoxSize)
+			if err != nil {
+				return false, err
+			}
+			parseMvhd(data, info)
+		case "trak":
+			if err := parseTrak(ra, &b, info); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+func parseMvhd(data []byte, info *model.MediaInfo) {
+	var created, timescale, duration uint64
+	switch {
+	case len(data) >= 32 && data[0] == 1:
+		created = binary.BigEndian.Uint64(data[4:12])
+		timescale = uint64(binary.BigEndian.Uint32(data[20:24]))
+		duration = binary.BigEndian.Uint64(data[24:32])
+	case len(data) >= 20:
+		created = uint64(binary.BigEndian.Uint32(data[4:8]))
+		timescale = uint64(binary.BigEndian.Uint32(data[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(data[16:20]))
+	default:
+		return
+	}
+	if timescale > 0 {
+		info.Duration = float64(duration) / float64(timescale)
+	}
+	if created > 0 {
+		taken := mp4Epoch.Add(time.Duration(created) * time.Second)
+		info.Taken = &taken
+	}
+}
+
+func parseTrak(ra io.ReaderAt, trak *box, info *model.MediaInfo) error {
+	hdlr, err := findBox(ra, trak.data, trak.end, "mdia", "hdlr")
+	if err != nil || hdlr == nil {
+		return err
+	}
+	data, err := readBox(ra, hdlr, maxMp4BoxSize)
+	if err != nil || len(data) < 12 {
+		return err
+	}
+	handler := string(data[8:12])
+	if handler != "vide" && handler != "soun" {
+		return nil
+	}
+	stsd, err := findBox(ra, trak.data, trak.end, "mdia", "minf", "stbl", "stsd")
+	if err != nil || stsd == nil {
+		return err
+	}
+	data, err = readBox(ra, stsd, maxMp4BoxSize)
+	if err != nil {
+		return err
+	}
+	// version and flags, entry count, then the first sample entry
+	if len(data) < 16 {
+		return nil
+	}
+	entry := data[8:]
+	codec := strings.TrimSpace(string(entry[4:8]))
+	switch handler {
+	case "vide":
+		if info.VideoCodec != "" {
+			return nil
+		}
+		info.VideoCodec = codec
+		// the header, 6 reserved bytes, data reference index and 16 bytes pre defined, then width and height
+		if len(entry) >= 36 {
+			info.Width = int(binary.BigEndian.Uint16(entry[32:34]))
+			info.Height = int(binary.BigEndian.Uint16(entry[34:36]))
+		}
+	case "soun":
+		if info.AudioCodec != "" {
+			return nil
+		}
+		info.AudioCodec = codec
+		// the header, 6 reserved bytes, data reference index and 8 reserved bytes, then the channels,
+		// sample size, 4 reserved bytes and the sample rate in 16.16
+		if len(entry) >= 36 {
+			info.Channels = int(binary.BigEndian.Uint16(entry[24:26]))
+			info.SampleRate = int(binary.BigEndian.Uint16(entry[32:34]))
+		}
+	}
+	return nil
+}
@@ -51,3 +51,30 @@ type ArchiveMetaProvider struct {
 	DriverProviding bool
 	Expiration      *time.Duration
 }
+
+// ArchiveIndex records the entries of a stream archive, e.g. .tar.xz, which can only be listed by
+// decompressing the whole archive, so that the later listing and extracting don't rescan it.
+// It is persisted with the size, modified time and hash of the archive, and rebuilt once they change
+type ArchiveIndex struct {
+	Size     int64               `json:"size"`
+	Modified time.Time           `json:"modified"`
+	Hash     string              `json:"hash"`
+	Entries  []ArchiveIndexEntry `json:"entries"`
+	// Frames is the seek table of the seekable zstd archives
+	Frames []ArchiveIndexFrame `json:"frames,omitempty"`
+}
+
+type ArchiveIndexEntry struct {
+	// Name is the slash separated path in the archive, without the leading and trailing slash
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	IsDir    bool      `json:"is_dir,omitempty"`
+	// Offset is the position of the content in the decompressed stream
+	Offset int64 `json:"offset"`
+}
+
+type ArchiveIndexFrame struct {
+	CompressedOffset   int64 `json:"c"`
+	DecompressedOffset int64 `json:"d"`
+}
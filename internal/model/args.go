@@ -76,6 +76,13 @@ type ArchiveDecompressArgs struct {
 	PutIntoNewDir bool
 }
 
+type ArchiveCompressArgs struct {
+	// the extension of the archive, e.g. .zip, .tar.gz
+	Format string
+	// encrypt the entries with AES-256, only supported by zip
+	Password string
+}
+
 type RangeReadCloserIF interface {
 	RangeRead(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error)
 	utils.ClosersIF
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+const (
+	AuditMakeDir    = "mkdir"
+	AuditMove       = "move"
+	AuditCopy       = "copy"
+	AuditRename     = "rename"
+	AuditRemove     = "remove"
+	AuditUpload     = "upload"
+	AuditDownload   = "download"
+	AuditDecompress = "decompress"
+	AuditCompress   = "compress"
+	AuditSync       = "sync"
+)
+
+type AuditLog struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	Time     time.Time `json:"time" gorm:"index"`
+	UserID   uint      `json:"user_id"`
+	Username string    `json:"username" gorm:"index;size:128"`
+	Protocol string    `json:"protocol" gorm:"size:16"`
+	Action   string    `json:"action" gorm:"index;size:16"`
+	SrcPath  string    `json:"src_path"`
+	DstPath  string    `json:"dst_path"`
+	IP       string    `json:"ip"`
+	Success  bool      `json:"success"`
+	Error    string    `json:"error"`
+}
+
+type AuditLogQuery struct {
+	Username string     `json:"username" form:"username"`
+	Protocol string     `json:"protocol" form:"protocol"`
+	Action   string     `json:"action" form:"action"`
+	Path     string     `json:"path" form:"path"` // prefix of the src or dst path
+	IP       string     `json:"ip" form:"ip"`
+	Success  *bool      `json:"success" form:"success"`
+	Start    *time.Time `json:"start" form:"start"`
+	End      *time.Time `json:"end" form:"end"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// FileHash is the hashes of a file recorded by the dedup index
+type FileHash struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	Path     string    `json:"path" gorm:"uniqueIndex;size:512"` // mount path
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	MD5      string    `json:"md5" gorm:"index;size:32"`
+	SHA1     string    `json:"sha1" gorm:"index;size:40"`
+	SHA256   string    `json:"sha256" gorm:"index;size:64"`
+	Updated  time.Time `json:"updated"`
+}
+
+// DupHash is a hash shared by more than one file of the same size
+type DupHash struct {
+	Hash  string
+	Size  int64
+	Count int64
+}
+
+// DedupGroup is a group of files which have the same content
+type DedupGroup struct {
+	HashType string   `json:"hash_type"`
+	Hash     string   `json:"hash"`
+	Size     int64    `json:"size"`
+	Paths    []string `json:"paths"`
+	// Wasted is the size taken by the redundant copies
+	Wasted int64 `json:"wasted"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type FileVersion struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	StorageID   uint      `json:"-" gorm:"index:idx_file_version"`
+	Path        string    `json:"-" gorm:"index:idx_file_version"` // the actual path of the file in the storage
+	VersionPath string    `json:"-"`                               // the actual path of this version in the storage
+	Size        int64     `json:"size"`
+	Modified    time.Time `json:"modified"` // the modified time of the overwritten content
+	Created     time.Time `json:"created"`  // when it was overwritten
+	CreatorID   uint      `json:"creator_id"`
+}
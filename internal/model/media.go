@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// MediaInfo is the metadata read from the images, audios and videos, the zero fields are unknown
+type MediaInfo struct {
+	// the container or image format, e.g. jpeg, mp4, flac
+	Format string `json:"format"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	// in seconds
+	Duration   float64 `json:"duration,omitempty"`
+	VideoCodec string  `json:"video_codec,omitempty"`
+	AudioCodec string  `json:"audio_codec,omitempty"`
+	SampleRate int     `json:"sample_rate,omitempty"`
+	Channels   int     `json:"channels,omitempty"`
+	// exif
+	Make        string     `json:"make,omitempty"`
+	Model       string     `json:"model,omitempty"`
+	Taken       *time.Time `json:"taken,omitempty"`
+	Orientation int        `json:"orientation,omitempty"`
+	Latitude    *float64   `json:"latitude,omitempty"`
+	Longitude   *float64   `json:"longitude,omitempty"`
+	// the tags of the audios
+	Title  string `json:"title,omitempty"`
+	Artist string `json:"artist,omitempty"`
+	Album  string `json:"album,omitempty"`
+	Genre  string `json:"genre,omitempty"`
+	Year   int    `json:"year,omitempty"`
+	Track  int    `json:"track,omitempty"`
+}
+
+// MediaMeta caches the media info of a file, it is extracted again once the size or modified time changes
+type MediaMeta struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	Path     string    `json:"path" gorm:"uniqueIndex;size:512"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	// the json of MediaInfo
+	Info string `json:"info" gorm:"type:text"`
+}
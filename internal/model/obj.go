@@ -43,6 +43,8 @@ type FileStreamer interface {
 	//SetReader(io.Reader)
 	NeedStore() bool
 	IsForceStreamUpload() bool
+	// whether the hashes are taken from a storage, the hashes given by the client can't be trusted
+	IsTrustedHash() bool
 	GetExist() Obj
 	SetExist(Obj)
 	//for a non-seekable Stream, RangeRead supports peeking some data, and CacheFullInTempFile still works
@@ -0,0 +1,13 @@
+package model
+
+// ObjProp is a custom property of the object of the path, such as a dead property of webdav.
+// The name of the property is a namespace and a local name like xml.
+type ObjProp struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Path  string `json:"path" gorm:"index;size:512"`
+	Space string `json:"space"`
+	Name  string `json:"name"`
+	Lang  string `json:"lang"`
+	// Value is the inner xml of the property for webdav
+	Value string `json:"value" gorm:"type:text"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Quota limits the total size and count of the files under the path prefix for the user or role
+// it's set on. The files are not attributed to the users who put them, so it's a cap of the path:
+// the files put by anyone are counted, and the user can't put more once the path is full.
+type Quota struct {
+	Path     string `json:"path"`      // path prefix, e.g. "/team"
+	MaxBytes int64  `json:"max_bytes"` // 0 means unlimited
+	MaxFiles int64  `json:"max_files"` // 0 means unlimited
+}
+
+type Quotas []Quota
+
+func (q Quotas) Value() (driver.Value, error) {
+	if len(q) == 0 {
+		return "", nil
+	}
+	bs, err := json.Marshal([]Quota(q))
+	return string(bs), err
+}
+
+func (q *Quotas) Scan(value interface{}) error {
+	var bs []byte
+	switch v := value.(type) {
+	case []byte:
+		bs = v
+	case string:
+		bs = []byte(v)
+	case nil:
+	default:
+		return fmt.Errorf("cannot scan %T", value)
+	}
+	if len(bs) == 0 {
+		*q = nil
+		return nil
+	}
+	return json.Unmarshal(bs, (*[]Quota)(q))
+}
+
+// QuotaUsage is the usage under the path of the quotas, it's updated on every change through alist
+// and reconciled by walking the path periodically. The quotas of all the users and roles on the
+// same path share the usage.
+type QuotaUsage struct {
+	Path       string     `json:"path" gorm:"primaryKey;size:512"`
+	Bytes      int64      `json:"bytes"`
+	Files      int64      `json:"files"`
+	Reconciled *time.Time `json:"reconciled"` // nil means never reconciled
+}
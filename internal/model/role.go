@@ -21,6 +21,8 @@ type Role struct {
 	PermissionScopes []PermissionEntry `json:"permission_scopes" gorm:"-"`
 	// RawPermission is the JSON representation of PermissionScopes stored in DB.
 	RawPermission string `json:"-" gorm:"type:text"`
+	// Quotas limits the space under the paths for the users of the role.
+	Quotas Quotas `json:"quotas" gorm:"type:text"`
 }
 
 // BeforeSave GORM hook serializes PermissionScopes into RawPermission.
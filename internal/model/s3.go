@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// S3MultipartUpload is a multipart upload of the s3 server, the parts are kept in <temp dir>/s3-multipart/<id>
+// until the upload is completed or aborted
+type S3MultipartUpload struct {
+	ID        string `json:"id" gorm:"primaryKey;size:64"`
+	UserID    uint   `json:"user_id" gorm:"index"`
+	Bucket    string `json:"bucket" gorm:"index"`
+	ObjectKey string `json:"object_key"`
+	// the json of the metadata of the object, which is saved once the upload is completed
+	Meta    string    `json:"meta" gorm:"type:text"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated" gorm:"index"`
+}
+
+type S3MultipartPart struct {
+	UploadID string    `json:"upload_id" gorm:"primaryKey;size:64"`
+	Number   int       `json:"number" gorm:"primaryKey;autoIncrement:false"`
+	Size     int64     `json:"size"`
+	ETag     string    `json:"etag"`
+	Modified time.Time `json:"modified"`
+}
+
+// S3ObjectMeta keeps the content type and the user metadata of the object put by the s3 server
+type S3ObjectMeta struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Path string `json:"path" gorm:"uniqueIndex;size:512"`
+	// the json of the metadata headers
+	Meta string `json:"meta" gorm:"type:text"`
+}
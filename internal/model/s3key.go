@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// S3AccessKey is the access key of a user for the s3 server, the requests signed with it are run as the user
+type S3AccessKey struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	UserId          uint      `json:"-" gorm:"index"`
+	Title           string    `json:"title"`
+	AccessKeyId     string    `json:"access_key_id" gorm:"unique;size:64"`
+	SecretAccessKey string    `json:"-"`
+	AddedTime       time.Time `json:"added_time"`
+	LastUsedTime    time.Time `json:"last_used_time"`
+}
+
+func (k *S3AccessKey) UpdateLastUsedTime() {
+	k.LastUsedTime = time.Now()
+}
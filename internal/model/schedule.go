@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+const (
+	ScheduledJobCopy            = "copy"
+	ScheduledJobSync            = "sync"
+	ScheduledJobIndex           = "index"
+	ScheduledJobIndexRefresh    = "index_refresh"
+	ScheduledJobOfflineDownload = "offline_download"
+)
+
+type ScheduledJob struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Name      string     `json:"name" binding:"required"`
+	Type      string     `json:"type" binding:"required"` // copy, sync, index, index_refresh, offline_download
+	Cron      string     `json:"cron" binding:"required"` // cron expression, e.g. "0 3 * * *" or "@every 1h"
+	Args      string     `json:"args" gorm:"type:text"`   // json arguments of the job type
+	State     string     `json:"-" gorm:"type:text"`      // kept between runs, e.g. the seen urls of a rss feed
+	Disabled  bool       `json:"disabled"`
+	CreatorID uint       `json:"creator_id"`
+	LastRun   *time.Time `json:"last_run"`
+	NextRun   *time.Time `json:"next_run" gorm:"-"`
+	Created   time.Time  `json:"created"`
+}
+
+type ScheduledJobRun struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	JobID     uint      `json:"job_id" gorm:"index"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Success   bool      `json:"success"`
+	Message   string    `json:"message" gorm:"type:text"` // the added tasks or the error
+}
+
+// CopyJobArgs copy the objects to the dst dir
+type CopyJobArgs struct {
+	SrcDir string   `json:"src_dir"`
+	DstDir string   `json:"dst_dir"`
+	Names  []string `json:"names"` // copy the whole src dir if empty
+}
+
+// SyncJobArgs make the dst dir the same as the src dir
+type SyncJobArgs struct {
+	SrcDir string `json:"src_dir"`
+	DstDir string `json:"dst_dir"`
+	Delete bool   `json:"delete"`
+}
+
+// IndexJobArgs rebuild the search index of the paths
+type IndexJobArgs struct {
+	Paths    []string `json:"paths"` // rebuild the whole index if empty
+	MaxDepth int      `json:"max_depth"`
+}
+
+// IndexRefreshJobArgs refresh the search index of the dirs changed under the paths
+type IndexRefreshJobArgs struct {
+	Paths []string `json:"paths"` // refresh the whole index if empty
+}
+
+// OfflineDownloadJobArgs add the urls, or the new items of the rss feed, to offline download
+type OfflineDownloadJobArgs struct {
+	Urls         []string `json:"urls"`
+	Rss          string   `json:"rss"`
+	DstDir       string   `json:"dst_dir"`
+	Tool         string   `json:"tool"`
+	DeletePolicy string   `json:"delete_policy"`
+}
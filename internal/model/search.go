@@ -10,6 +10,9 @@ type IndexProgress struct {
 	IsDone       bool       `json:"is_done"`
 	LastDoneTime *time.Time `json:"last_done_time"`
 	Error        string     `json:"error"`
+	// the searcher and the version of its index layout the progress belongs to
+	Searcher string `json:"searcher"`
+	Version  int    `json:"version"`
 }
 
 type SearchReq struct {
@@ -17,14 +20,87 @@ type SearchReq struct {
 	Keywords string `json:"keywords"`
 	// 0 for all, 1 for dir, 2 for file
 	Scope int `json:"scope"`
+	SearchFilter
 	PageReq
 }
 
+// SearchFilter narrow down the search results, the empty fields are not limited
+type SearchFilter struct {
+	MinSize        *int64     `json:"min_size"`
+	MaxSize        *int64     `json:"max_size"`
+	ModifiedAfter  *time.Time `json:"modified_after"`
+	ModifiedBefore *time.Time `json:"modified_before"`
+	// extensions without dot, in lower case
+	Exts []string `json:"exts"`
+	// the obj types returned by utils.GetObjType
+	Types []int `json:"types"`
+	// mount paths of the storages
+	Storages []string `json:"storages"`
+	// the media filters only match the files indexed with the media info
+	MinWidth    *int       `json:"min_width"`
+	MinHeight   *int       `json:"min_height"`
+	MinDuration *float64   `json:"min_duration"`
+	MaxDuration *float64   `json:"max_duration"`
+	TakenAfter  *time.Time `json:"taken_after"`
+	TakenBefore *time.Time `json:"taken_before"`
+	// name, size or modified, name by default
+	OrderBy string `json:"order_by"`
+	// asc or desc, asc by default
+	OrderDirection string `json:"order_direction"`
+}
+
 type SearchNode struct {
-	Parent string `json:"parent" gorm:"index"`
-	Name   string `json:"name"`
-	IsDir  bool   `json:"is_dir"`
-	Size   int64  `json:"size"`
+	Parent   string    `json:"parent" gorm:"index"`
+	Name     string    `json:"name"`
+	IsDir    bool      `json:"is_dir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Ext      string    `json:"ext" gorm:"index;size:32"`
+	ObjType  int       `json:"obj_type" gorm:"index"`
+	Storage  string    `json:"storage" gorm:"index;size:255"`
+	// the media info, only indexed when search_media_info is on
+	Width    int        `json:"width,omitempty"`
+	Height   int        `json:"height,omitempty"`
+	Duration float64    `json:"duration,omitempty"`
+	Taken    *time.Time `json:"taken,omitempty"`
+	// the text extracted from the document, only indexed by the searchers which support content
+	Content string `json:"content,omitempty" gorm:"-"`
+	// the matched fragment of the content, only filled in the search results
+	Snippet *SearchSnippet `json:"snippet,omitempty" gorm:"-"`
+}
+
+type SearchSnippet struct {
+	Text string `json:"text"`
+	// the offsets are counted in characters of the text, the end is exclusive
+	Highlights []SnippetHighlight `json:"highlights"`
+}
+
+type SnippetHighlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// DirFingerprint is the summary of the children of an indexed dir,
+// the index of the dir is refreshed only when it changes
+type DirFingerprint struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Path string `json:"path" gorm:"uniqueIndex;size:512"`
+	// the latest modified time of the children
+	Modified time.Time `json:"modified"`
+	Count    int       `json:"count"`
+	// the sum of the size of the children
+	Size int64 `json:"size"`
+	// the modified time and the size of the dir itself in the listing of its parent,
+	// the dir is not listed again while they are unchanged
+	DirModified time.Time `json:"dir_modified"`
+	DirSize     int64     `json:"dir_size"`
+	// the cursor of the change feed, only kept for the mount paths of the storages
+	Cursor  string    `json:"-" gorm:"type:text"`
+	Updated time.Time `json:"updated"`
+}
+
+func (f *DirFingerprint) Equal(other *DirFingerprint) bool {
+	return other != nil && f.Count == other.Count && f.Size == other.Size && f.Modified.Equal(other.Modified)
 }
 
 func (p *SearchReq) Validate() error {
@@ -34,6 +110,12 @@ func (p *SearchReq) Validate() error {
 	if p.PerPage < 1 {
 		return fmt.Errorf("per_page can't < 1")
 	}
+	if p.OrderBy != "" && p.OrderBy != "name" && p.OrderBy != "size" && p.OrderBy != "modified" {
+		return fmt.Errorf("unsupported order_by: %s", p.OrderBy)
+	}
+	if p.OrderDirection != "" && p.OrderDirection != "asc" && p.OrderDirection != "desc" {
+		return fmt.Errorf("unsupported order_direction: %s", p.OrderDirection)
+	}
 	return nil
 }
 
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"gorm.io/gorm"
+)
+
+type Share struct {
+	ID        string `json:"id" gorm:"primaryKey;size:32"`
+	CreatorID uint   `json:"creator_id" gorm:"index"`
+	Path      string `json:"path"` // relative to the creator's base path
+	// Password is the hash of the password, empty means no password.
+	// It is the plain password if Salt is empty, which is kept by the old versions.
+	Password    string `json:"-"`
+	Salt        string `json:"-"`
+	HasPassword bool   `json:"has_password" gorm:"-"`
+	// MetaPassword is the password of the meta of the shared path given by the creator,
+	// which is not used for the metas under the path
+	MetaPassword string     `json:"-"`
+	Expires      *time.Time `json:"expires"`       // nil means never expire
+	MaxDownloads int        `json:"max_downloads"` // 0 means unlimited
+	Downloads    int        `json:"downloads"`
+	Remark       string     `json:"remark"`
+	Disabled     bool       `json:"disabled"`
+	Created      time.Time  `json:"created"`
+}
+
+func (s *Share) AfterFind(tx *gorm.DB) error {
+	s.HasPassword = s.Password != ""
+	return nil
+}
+
+// SetPassword set the hash of the password, an empty password means no password
+func (s *Share) SetPassword(pwd string) {
+	s.Salt, s.Password = "", ""
+	if pwd != "" {
+		s.Salt = random.String(16)
+		s.Password = TwoHashPwd(pwd, s.Salt)
+	}
+	s.HasPassword = s.Password != ""
+}
+
+func (s *Share) ValidatePassword(pwd string) bool {
+	if s.Password == "" {
+		return true
+	}
+	hash := pwd
+	if s.Salt != "" {
+		hash = TwoHashPwd(pwd, s.Salt)
+	}
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(s.Password)) == 1
+}
+
+func (s *Share) IsExpired() bool {
+	return s.Expires != nil && s.Expires.Before(time.Now())
+}
+
+func (s *Share) IsDownloadLimitReached() bool {
+	return s.MaxDownloads > 0 && s.Downloads >= s.MaxDownloads
+}
+
+const (
+	ShareActionList     = "list"
+	ShareActionDownload = "download"
+)
+
+type ShareAccessLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ShareID   string    `json:"share_id" gorm:"index;size:32"`
+	Action    string    `json:"action"`
+	Path      string    `json:"path"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+	Time      time.Time `json:"time"`
+}
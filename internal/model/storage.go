@@ -16,9 +16,11 @@ type Storage struct {
 	Modified        time.Time `json:"modified"`
 	Disabled        bool      `json:"disabled"` // if disabled
 	DisableIndex    bool      `json:"disable_index"`
+	IndexContent    bool      `json:"index_content"` // index the content of documents by the full text searchers
 	EnableSign      bool      `json:"enable_sign"`
 	Sort
 	Proxy
+	Versioning
 }
 
 type Sort struct {
@@ -34,6 +36,12 @@ type Proxy struct {
 	DownProxyUrl string `json:"down_proxy_url"`
 }
 
+type Versioning struct {
+	EnableVersions       bool `json:"enable_versions"`
+	MaxVersions          int  `json:"max_versions"`           // 0 means unlimited
+	VersionRetentionDays int  `json:"version_retention_days"` // 0 means keep forever
+}
+
 func (s *Storage) GetStorage() *Storage {
 	return s
 }
@@ -57,3 +65,23 @@ func (p Proxy) WebdavProxy() bool {
 func (p Proxy) WebdavNative() bool {
 	return !p.Webdav302() && !p.WebdavProxy()
 }
+
+// StorageDetails is the capacity of a storage, in bytes
+type StorageDetails struct {
+	TotalSpace int64 `json:"total_space"`
+	UsedSpace  int64 `json:"used_space"`
+	FreeSpace  int64 `json:"free_space"`
+}
+
+// NewStorageDetails calculate the free space from the total and used space
+func NewStorageDetails(total, used int64) *StorageDetails {
+	free := total - used
+	if free < 0 {
+		free = 0
+	}
+	return &StorageDetails{
+		TotalSpace: total,
+		UsedSpace:  used,
+		FreeSpace:  free,
+	}
+}
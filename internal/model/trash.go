@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+type TrashItem struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"index"` // the user who removed the object
+	Path         string    `json:"path"`                 // the original mount path of the object
+	TrashPath    string    `json:"-"`                    // the mount path of the object in the trash
+	NativeID     string    `json:"-"`                    // the id in the native trash of the storage, if supported
+	VersionsPath string    `json:"-"`                    // the actual path the versions of the object are kept by in the trash
+	Name         string    `json:"name"`
+	Size         int64     `json:"size"`
+	IsDir        bool      `json:"is_dir"`
+	Deleted      time.Time `json:"deleted" gorm:"index"`
+}
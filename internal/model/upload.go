@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// UploadSession is a resumable upload, whose received data is kept in the temp dir until it is complete
+type UploadSession struct {
+	ID       string    `json:"id" gorm:"primaryKey;size:64"`
+	UserID   uint      `json:"user_id" gorm:"index"`
+	Path     string    `json:"path"` // the mount path of the file to put
+	Size     int64     `json:"size"`
+	Offset   int64     `json:"offset"` // the size of the received data
+	Metadata string    `json:"metadata"`
+	Mimetype string    `json:"mimetype"`
+	Modified time.Time `json:"modified"`
+	HashInfo string    `json:"hash_info"`
+	AsTask   bool      `json:"as_task"`
+	Updated  time.Time `json:"updated" gorm:"index"` // the last time the data was written
+}
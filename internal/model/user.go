@@ -53,6 +53,7 @@ type User struct {
 	OtpSecret  string `json:"-"`
 	SsoID      string `json:"sso_id"` // unique by sso platform
 	Authn      string `gorm:"type:text" json:"-"`
+	Quotas     Quotas `json:"quotas" gorm:"type:text"`
 }
 
 func (u *User) IsGuest() bool {
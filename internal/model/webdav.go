@@ -0,0 +1,51 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// WebdavLock is an exclusive write lock of the webdav server, which is kept in the db,
+// so it is kept after restarting and shared by the instances using the same db
+type WebdavLock struct {
+	Token string `json:"token" gorm:"primaryKey;size:64"`
+	// Root is the locked path relative to the webdav root, a resource is locked by one lock at most
+	Root      string `json:"root" gorm:"uniqueIndex;size:512"`
+	ZeroDepth bool   `json:"zero_depth"`
+	OwnerXML  string `json:"owner_xml" gorm:"type:text"`
+	// Timeout is the seconds of the lock timeout, negative means infinite
+	Timeout int64      `json:"timeout"`
+	Expires *time.Time `json:"expires" gorm:"index"` // nil if never expires
+	Created time.Time  `json:"created"`
+	// HeldBy is the request holding the lock while it is confirmed, the hold is a lease renewed by
+	// the holder, so the locks held by a crashed instance are released at HeldUntil
+	HeldBy    string     `json:"-" gorm:"size:64"`
+	HeldUntil *time.Time `json:"-"`
+}
+
+func (l *WebdavLock) Expired(now time.Time) bool {
+	return l.Expires != nil && !now.Before(*l.Expires)
+}
+
+// Held check whether the lock is held by a request which is using it
+func (l *WebdavLock) Held(now time.Time) bool {
+	return l.HeldUntil != nil && now.Before(*l.HeldUntil)
+}
+
+// Covers check whether the resource of name is locked by the lock
+func (l *WebdavLock) Covers(name string) bool {
+	if name == l.Root {
+		return true
+	}
+	return !l.ZeroDepth && isDescendant(name, l.Root)
+}
+
+// Conflicts check whether the lock prevents creating the lock of another root,
+// which is locked by it, or contains it if the lock of the root has infinite depth
+func (l *WebdavLock) Conflicts(root string, zeroDepth bool) bool {
+	return l.Covers(root) || (!zeroDepth && isDescendant(l.Root, root))
+}
+
+func isDescendant(name, root string) bool {
+	return name != root && (root == "/" || strings.HasPrefix(name, root+"/"))
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	EventUpload        = "fs.upload"
+	EventRemove        = "fs.remove"
+	EventTaskSucceeded = "task.succeeded"
+	EventTaskFailed    = "task.failed"
+	EventStorageError  = "storage.error"
+	EventLogin         = "user.login"
+	EventPing          = "ping"
+)
+
+type Event struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data"`
+}
+
+type ObjEventData struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	IsDir bool   `json:"is_dir"`
+	User  string `json:"user"`
+}
+
+type TaskEventData struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Error string `json:"error"`
+	User  string `json:"user"`
+}
+
+type StorageEventData struct {
+	MountPath string `json:"mount_path"`
+	Driver    string `json:"driver"`
+	Status    string `json:"status"`
+}
+
+type LoginEventData struct {
+	User string `json:"user"`
+	IP   string `json:"ip"`
+}
+
+type Webhook struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	Name     string    `json:"name" binding:"required"`
+	URL      string    `json:"url" binding:"required"`
+	Secret   string    `json:"secret"` // used to sign the payload with HMAC-SHA256
+	Events   string    `json:"events"` // split by comma, empty means all events
+	Disabled bool      `json:"disabled"`
+	Created  time.Time `json:"created"`
+}
+
+func (w *Webhook) Subscribed(event string) bool {
+	if w.Events == "" || event == EventPing {
+		return true
+	}
+	for _, e := range strings.Split(w.Events, ",") {
+		if strings.TrimSpace(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDelivery struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	WebhookID  uint       `json:"webhook_id" gorm:"index"`
+	Event      string     `json:"event"`
+	Payload    string     `json:"payload" gorm:"type:text"`
+	Attempts   int        `json:"attempts"`
+	StatusCode int        `json:"status_code"`
+	Response   string     `json:"response" gorm:"type:text"` // truncated response body
+	Error      string     `json:"error"`
+	Success    bool       `json:"success"`
+	NextRetry  *time.Time `json:"next_retry" gorm:"index"` // nil if the delivery is done, succeeded or not
+	Created    time.Time  `json:"created"`
+	Updated    time.Time  `json:"updated"`
+}
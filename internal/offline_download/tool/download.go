@@ -177,4 +177,9 @@ func (t *DownloadTask) GetStatus() string {
 	return t.Status
 }
 
+// OnFailed notify the failure of download, the success is notified by the transfer task
+func (t *DownloadTask) OnFailed() {
+	task.HandleEventHook(t, false)
+}
+
 var DownloadTaskManager *tache.Manager[*DownloadTask]
@@ -52,6 +52,7 @@ func (t *TransferTask) GetStatus() string {
 }
 
 func (t *TransferTask) OnSucceeded() {
+	task.HandleEventHook(t, true)
 	if t.DeletePolicy == DeleteOnUploadSucceed || t.DeletePolicy == DeleteAlways {
 		if t.SrcStorage == nil {
 			removeStdTemp(t)
@@ -62,6 +63,7 @@ func (t *TransferTask) OnSucceeded() {
 }
 
 func (t *TransferTask) OnFailed() {
+	task.HandleEventHook(t, false)
 	if t.DeletePolicy == DeleteOnUploadFailed || t.DeletePolicy == DeleteAlways {
 		if t.SrcStorage == nil {
 			removeStdTemp(t)
@@ -252,8 +254,9 @@ func transferObjFile(t *TransferTask) error {
 		return errors.WithMessagef(err, "failed get [%s] link", t.SrcObjPath)
 	}
 	fs := stream.FileStream{
-		Obj: srcFile,
-		Ctx: t.Ctx(),
+		Obj:         srcFile,
+		Ctx:         t.Ctx(),
+		TrustedHash: true,
 	}
 	// any link provided is seekable
 	ss, err := stream.NewSeekableStream(fs, link)
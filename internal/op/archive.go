@@ -60,29 +60,16 @@ func GetArchiveToolAndStream(ctx context.Context, storage driver.Driver, path st
 	if err != nil {
 		return nil, nil, nil, errors.WithMessagef(err, "failed get [%s] link", path)
 	}
-	baseName, ext, found := strings.Cut(obj.GetName(), ".")
-	if !found {
+	baseName, _, _ := strings.Cut(obj.GetName(), ".")
+	partExt, t, err := getArchiveTool(obj.GetName())
+	if err != nil {
 		if l.MFile != nil {
 			_ = l.MFile.Close()
 		}
 		if l.RangeReadCloser != nil {
 			_ = l.RangeReadCloser.Close()
 		}
-		return nil, nil, nil, errors.Errorf("failed get archive tool: the obj does not have an extension.")
-	}
-	partExt, t, err := tool.GetArchiveTool("." + ext)
-	if err != nil {
-		var e error
-		partExt, t, e = tool.GetArchiveTool(stdpath.Ext(obj.GetName()))
-		if e != nil {
-			if l.MFile != nil {
-				_ = l.MFile.Close()
-			}
-			if l.RangeReadCloser != nil {
-				_ = l.RangeReadCloser.Close()
-			}
-			return nil, nil, nil, errors.WithMessagef(stderrors.Join(err, e), "failed get archive tool: %s", ext)
-		}
+		return nil, nil, nil, err
 	}
 	ss, err := stream.NewSeekableStream(stream.FileStream{Ctx: ctx, Obj: obj}, l)
 	if err != nil {
@@ -150,6 +137,10 @@ func getArchiveMeta(ctx context.Context, storage driver.Driver, path string, arg
 			return obj, archiveMetaProvider, err
 		}
 	}
+	obj, archiveMetaProvider, err := getArchiveMetaByIndex(ctx, storage, path, args)
+	if !errors.Is(err, errs.NotSupport) {
+		return obj, archiveMetaProvider, err
+	}
 	obj, t, ss, err := GetArchiveToolAndStream(ctx, storage, path, args.LinkArgs)
 	if err != nil {
 		return nil, nil, err
@@ -167,7 +158,7 @@ func getArchiveMeta(ctx context.Context, storage driver.Driver, path string, arg
 	if err != nil {
 		return nil, nil, err
 	}
-	archiveMetaProvider := &model.ArchiveMetaProvider{ArchiveMeta: meta, DriverProviding: false}
+	archiveMetaProvider = &model.ArchiveMetaProvider{ArchiveMeta: meta, DriverProviding: false}
 	if meta.GetTree() != nil {
 		archiveMetaProvider.Sort = &storage.GetStorage().Sort
 	}
@@ -248,6 +239,10 @@ func _listArchive(ctx context.Context, storage driver.Driver, path string, args
 			return obj, files, err
 		}
 	}
+	obj, files, err := listArchiveByIndex(ctx, storage, path, args)
+	if !errors.Is(err, errs.NotSupport) {
+		return obj, files, err
+	}
 	obj, t, ss, err := GetArchiveToolAndStream(ctx, storage, path, args.LinkArgs)
 	if err != nil {
 		return nil, nil, err
@@ -261,7 +256,7 @@ func _listArchive(ctx context.Context, storage driver.Driver, path string, args
 			log.Errorf("failed to close file streamer, %v", e)
 		}
 	}()
-	files, err := t.List(ss, args.ArchiveInnerArgs)
+	files, err = t.List(ss, args.ArchiveInnerArgs)
 	return obj, files, err
 }
 
@@ -459,6 +454,9 @@ func (s *streamWithParent) Close() error {
 }
 
 func InternalExtract(ctx context.Context, storage driver.Driver, path string, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	if rc, size, err := internalExtractByIndex(ctx, storage, path, args); !errors.Is(err, errs.NotSupport) {
+		return rc, size, err
+	}
 	_, t, ss, err := GetArchiveToolAndStream(ctx, storage, path, args.LinkArgs)
 	if err != nil {
 		return nil, 0, err
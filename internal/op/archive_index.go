@@ -0,0 +1,204 @@
+package op
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/archive/tool"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var archiveIndexG singleflight.Group[*model.ArchiveIndex]
+
+// getArchiveTool find the tool by the full extension first, e.g. .tar.gz, then by the last one
+func getArchiveTool(name string) (*tool.MultipartExtension, tool.Tool, error) {
+	_, ext, found := strings.Cut(name, ".")
+	if !found {
+		return nil, nil, errors.Errorf("failed get archive tool: the obj does not have an extension.")
+	}
+	partExt, t, err := tool.GetArchiveTool("." + ext)
+	if err != nil {
+		var e error
+		partExt, t, e = tool.GetArchiveTool(stdpath.Ext(name))
+		if e != nil {
+			return nil, nil, errors.WithMessagef(stderrors.Join(err, e), "failed get archive tool: %s", ext)
+		}
+	}
+	return partExt, t, nil
+}
+
+// getArchiveIndex return the index of the stream archive, which is built on the first access and persisted
+// in the archive index dir, and rebuilt once the size, modified time or hash of the archive changes.
+// errs.NotSupport is returned if the archive is not a stream archive
+func getArchiveIndex(ctx context.Context, storage driver.Driver, path string, args model.ArchiveArgs, refresh bool) (model.Obj, tool.Indexer, *model.ArchiveIndex, error) {
+	obj, err := GetUnwrap(ctx, storage, path)
+	if err != nil {
+		return nil, nil, nil, errors.WithMessage(err, "failed to get file")
+	}
+	if obj.IsDir() {
+		return nil, nil, nil, errors.WithStack(errs.NotFile)
+	}
+	partExt, t, err := getArchiveTool(obj.GetName())
+	if err != nil || partExt != nil {
+		return nil, nil, nil, errs.NotSupport
+	}
+	indexer, ok := t.(tool.Indexer)
+	if !ok || !indexer.CanIndex(obj.GetName()) {
+		return nil, nil, nil, errs.NotSupport
+	}
+	file := archiveIndexFile(storage, path)
+	hash := obj.GetHash().String()
+	if !refresh {
+		index, err := loadArchiveIndex(file)
+		if err != nil {
+			log.Warnf("failed load archive index of %s: %+v", path, err)
+		} else if index != nil && index.Size == obj.GetSize() && index.Modified.Unix() == obj.ModTime().Unix() && index.Hash == hash {
+			return obj, indexer, index, nil
+		}
+	}
+	index, err, _ := archiveIndexG.Do(file, func() (*model.ArchiveIndex, error) {
+		_, _, ss, err := GetArchiveToolAndStream(ctx, storage, path, args.LinkArgs)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			var e error
+			for _, s := range ss {
+				e = stderrors.Join(e, s.Close())
+			}
+			if e != nil {
+				log.Errorf("failed to close file streamer, %v", e)
+			}
+		}()
+		log.Infof("build archive index of %s", path)
+		index, err := indexer.BuildIndex(ss, args)
+		if err != nil {
+			return nil, err
+		}
+		index.Size = obj.GetSize()
+		index.Modified = obj.ModTime()
+		index.Hash = hash
+		if err = saveArchiveIndex(file, index); err != nil {
+			// the index still works for this time
+			log.Warnf("failed save archive index of %s: %+v", path, err)
+		}
+		return index, nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return obj, indexer, index, nil
+}
+
+func archiveIndexFile(storage driver.Driver, path string) string {
+	sum := md5.Sum([]byte(Key(storage, path)))
+	return filepath.Join(conf.Conf.ArchiveIndexDir, hex.EncodeToString(sum[:])+".json.gz")
+}
+
+// loadArchiveIndex return nil if the index has not been built
+func loadArchiveIndex(file string) (*model.ArchiveIndex, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+	var index model.ArchiveIndex
+	if err = json.NewDecoder(gr).Decode(&index); err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+func saveArchiveIndex(file string, index *model.ArchiveIndex) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o777); err != nil {
+		return err
+	}
+	// write to a temp file first, so that a broken index is never loaded
+	f, err := os.CreateTemp(filepath.Dir(file), "index-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	gw := gzip.NewWriter(f)
+	err = stderrors.Join(json.NewEncoder(gw).Encode(index), gw.Close(), f.Close())
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
+
+func getArchiveMetaByIndex(ctx context.Context, storage driver.Driver, path string, args model.ArchiveMetaArgs) (model.Obj, *model.ArchiveMetaProvider, error) {
+	obj, _, index, err := getArchiveIndex(ctx, storage, path, args.ArchiveArgs, args.Refresh)
+	if err != nil {
+		return nil, nil, err
+	}
+	archiveMetaProvider := &model.ArchiveMetaProvider{
+		ArchiveMeta:     tool.GetMetaByIndex(index),
+		Sort:            &storage.GetStorage().Sort,
+		DriverProviding: false,
+	}
+	if !storage.Config().NoCache {
+		Expiration := time.Minute * time.Duration(storage.GetStorage().CacheExpiration)
+		archiveMetaProvider.Expiration = &Expiration
+	}
+	return obj, archiveMetaProvider, nil
+}
+
+func listArchiveByIndex(ctx context.Context, storage driver.Driver, path string, args model.ArchiveListArgs) (model.Obj, []model.Obj, error) {
+	obj, _, index, err := getArchiveIndex(ctx, storage, path, args.ArchiveArgs, args.Refresh)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := getChildrenFromArchiveMeta(tool.GetMetaByIndex(index), args.InnerPath)
+	return obj, files, err
+}
+
+func internalExtractByIndex(ctx context.Context, storage driver.Driver, path string, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error) {
+	_, indexer, index, err := getArchiveIndex(ctx, storage, path, args.ArchiveArgs, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	entry, err := tool.FindIndexEntry(index, args.InnerPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	if entry.IsDir {
+		return nil, 0, errors.WithStack(errs.NotFile)
+	}
+	_, _, ss, err := GetArchiveToolAndStream(ctx, storage, path, args.LinkArgs)
+	if err != nil {
+		return nil, 0, err
+	}
+	rc, err := indexer.ExtractByIndex(ss, index, entry)
+	if err != nil {
+		for _, s := range ss {
+			err = stderrors.Join(err, s.Close())
+		}
+		return nil, 0, err
+	}
+	return &streamWithParent{rc: rc, parents: ss}, entry.Size, nil
+}
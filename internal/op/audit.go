@@ -0,0 +1,24 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func CreateAuditLogs(logs []model.AuditLog) error {
+	return db.CreateAuditLogs(logs)
+}
+
+func GetAuditLogs(q model.AuditLogQuery, pageIndex, pageSize int) ([]model.AuditLog, int64, error) {
+	return db.GetAuditLogs(q, pageIndex, pageSize)
+}
+
+func WalkAuditLogs(q model.AuditLogQuery, fn func([]model.AuditLog) error) error {
+	return db.WalkAuditLogs(q, fn)
+}
+
+func DeleteAuditLogsBefore(t time.Time) error {
+	return db.DeleteAuditLogsBefore(t)
+}
@@ -0,0 +1,66 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// HardLink replace the file at dstPath with a hard link to the file at srcPath,
+// both paths are actual paths in the storage
+func HardLink(ctx context.Context, storage driver.Driver, srcPath, dstPath string) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	s, ok := storage.(driver.HardLink)
+	if !ok {
+		return errs.NotImplement
+	}
+	srcPath, dstPath = utils.FixAndCleanPath(srcPath), utils.FixAndCleanPath(dstPath)
+	srcObj, err := GetUnwrap(ctx, storage, srcPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get src object")
+	}
+	dstObj, err := GetUnwrap(ctx, storage, dstPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get dst object")
+	}
+	if srcObj.IsDir() || dstObj.IsDir() {
+		return errors.WithStack(errs.NotFile)
+	}
+	err = s.HardLink(ctx, srcObj, dstObj)
+	if err == nil {
+		ClearCache(storage, stdpath.Dir(dstPath))
+	}
+	return errors.WithStack(err)
+}
+
+func GetFileHashesUnder(dirPath string) ([]model.FileHash, error) {
+	return db.GetFileHashesUnder(dirPath)
+}
+
+func SaveFileHash(h *model.FileHash) error {
+	return db.SaveFileHash(h)
+}
+
+func DeleteFileHashesByIds(ids []uint) error {
+	return db.DeleteFileHashesByIds(ids)
+}
+
+func DeleteFileHashByPath(path string) error {
+	return db.DeleteFileHashByPath(path)
+}
+
+func GetDupHashes(column, dirPath string) ([]model.DupHash, error) {
+	return db.GetDupHashes(column, dirPath)
+}
+
+func GetFileHashesByHashes(column, dirPath string, hashes []string) ([]model.FileHash, error) {
+	return db.GetFileHashesByHashes(column, dirPath, hashes)
+}
@@ -145,6 +145,24 @@ func getMainItems(config driver.Config) []driver.Item {
 		Default:  "false",
 		Required: true,
 	})
+	if !config.NoUpload {
+		items = append(items, []driver.Item{{
+			Name:    "enable_versions",
+			Type:    conf.TypeBool,
+			Default: "false",
+			Help:    "Keep the previous content when a file is overwritten",
+		}, {
+			Name:    "max_versions",
+			Type:    conf.TypeNumber,
+			Default: "10",
+			Help:    "The max number of versions kept for each file, 0 means unlimited",
+		}, {
+			Name:    "version_retention_days",
+			Type:    conf.TypeNumber,
+			Default: "30",
+			Help:    "Versions older than this will be removed, 0 means keep forever",
+		}}...)
+	}
 	return items
 }
 func getAdditionalItems(t reflect.Type, defaultRoot string) []driver.Item {
@@ -0,0 +1,192 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the overwritten contents are moved to /.alist-versions/<random key>/<name> of the storage
+
+func versioningEnabled(storage driver.Driver) bool {
+	if !storage.GetStorage().EnableVersions {
+		return false
+	}
+	_, okMove := storage.(driver.Move)
+	_, okMoveResult := storage.(driver.MoveResult)
+	_, okMkdir := storage.(driver.Mkdir)
+	_, okMkdirResult := storage.(driver.MkdirResult)
+	return (okMove || okMoveResult) && (okMkdir || okMkdirResult)
+}
+
+// keepVersion move the existing file to the versions dir before it is overwritten,
+// the version is not recorded until commitVersion is called
+func keepVersion(ctx context.Context, storage driver.Driver, path string, obj model.Obj) (*model.FileVersion, error) {
+	versionDir := stdpath.Join("/", conf.VersionsDirName, random.String(16))
+	if err := MakeDir(ctx, storage, versionDir); err != nil {
+		return nil, errors.WithMessage(err, "failed to make version dir")
+	}
+	if err := Move(ctx, storage, path, versionDir); err != nil {
+		return nil, errors.WithMessage(err, "failed to move the file to version dir")
+	}
+	v := &model.FileVersion{
+		StorageID:   storage.GetStorage().ID,
+		Path:        path,
+		VersionPath: stdpath.Join(versionDir, obj.GetName()),
+		Size:        obj.GetSize(),
+		Modified:    obj.ModTime(),
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok {
+		v.CreatorID = user.ID
+	}
+	return v, nil
+}
+
+// revertVersion move the file back when the overwriting failed
+func revertVersion(ctx context.Context, storage driver.Driver, v *model.FileVersion) {
+	if err := Move(ctx, storage, v.VersionPath, stdpath.Dir(v.Path)); err != nil {
+		log.Errorf("failed revert version of %s: %+v", v.Path, err)
+		return
+	}
+	if err := Remove(ctx, storage, stdpath.Dir(v.VersionPath)); err != nil {
+		log.Warnf("failed remove version dir %s: %+v", stdpath.Dir(v.VersionPath), err)
+	}
+}
+
+func commitVersion(ctx context.Context, storage driver.Driver, v *model.FileVersion) {
+	v.Created = time.Now()
+	if err := db.CreateFileVersion(v); err != nil {
+		log.Errorf("failed record version of %s: %+v", v.Path, err)
+		return
+	}
+	pruneFileVersions(ctx, storage, v.Path)
+}
+
+func removeVersion(ctx context.Context, storage driver.Driver, v *model.FileVersion) error {
+	if err := Remove(ctx, storage, stdpath.Dir(v.VersionPath)); err != nil {
+		return err
+	}
+	return db.DeleteFileVersionById(v.ID)
+}
+
+// pruneFileVersions remove the versions of the file which exceed the retention policy of the storage
+func pruneFileVersions(ctx context.Context, storage driver.Driver, path string) {
+	versions, err := db.GetFileVersions(storage.GetStorage().ID, path)
+	if err != nil {
+		log.Errorf("failed get versions of %s: %+v", path, err)
+		return
+	}
+	maxVersions := storage.GetStorage().MaxVersions
+	days := storage.GetStorage().VersionRetentionDays
+	expired := time.Now().AddDate(0, 0, -days)
+	for i := range versions {
+		if (maxVersions > 0 && i >= maxVersions) || (days > 0 && versions[i].Created.Before(expired)) {
+			if err := removeVersion(ctx, storage, &versions[i]); err != nil {
+				log.Errorf("failed remove version %d of %s: %+v", versions[i].ID, path, err)
+			}
+		}
+	}
+}
+
+// PruneExpiredFileVersions remove the versions which are older than the retention days of their storage
+func PruneExpiredFileVersions(ctx context.Context) {
+	for _, storage := range GetAllStorages() {
+		days := storage.GetStorage().VersionRetentionDays
+		if days <= 0 || storage.GetStorage().Status != WORK {
+			continue
+		}
+		versions, err := db.GetFileVersionsCreatedBefore(storage.GetStorage().ID, time.Now().AddDate(0, 0, -days))
+		if err != nil {
+			log.Errorf("failed get expired versions: %+v", err)
+			continue
+		}
+		for i := range versions {
+			if utils.IsCanceled(ctx) {
+				return
+			}
+			if err := removeVersion(ctx, storage, &versions[i]); err != nil {
+				log.Errorf("failed remove version %d of %s: %+v", versions[i].ID, versions[i].Path, err)
+			}
+		}
+	}
+}
+
+// MoveFileVersions move the versions of the file and the files under it with them,
+// since the versions are kept by the paths
+func MoveFileVersions(storage driver.Driver, srcPath, dstPath string) error {
+	return db.MoveFileVersions(storage.GetStorage().ID, srcPath, dstPath)
+}
+
+// RemoveFileVersions remove the versions of the file and the files under it
+func RemoveFileVersions(ctx context.Context, storage driver.Driver, path string) error {
+	versions, err := db.GetFileVersionsUnder(storage.GetStorage().ID, path)
+	if err != nil {
+		return err
+	}
+	for i := range versions {
+		if err := removeVersion(ctx, storage, &versions[i]); err != nil {
+			return errors.WithMessagef(err, "failed remove version %d of %s", versions[i].ID, versions[i].Path)
+		}
+	}
+	return nil
+}
+
+// GetFileVersions get the versions of the file, the newest first
+func GetFileVersions(storage driver.Driver, path string) ([]model.FileVersion, error) {
+	return db.GetFileVersions(storage.GetStorage().ID, utils.FixAndCleanPath(path))
+}
+
+// GetFileVersion get the version and make sure it belongs to the file
+func GetFileVersion(storage driver.Driver, path string, id uint) (*model.FileVersion, error) {
+	v, err := db.GetFileVersionById(id)
+	if err != nil || v.StorageID != storage.GetStorage().ID || v.Path != utils.FixAndCleanPath(path) {
+		return nil, errors.WithStack(errs.FileVersionNotFound)
+	}
+	return v, nil
+}
+
+// RestoreFileVersion restore the file to the version,
+// the current content is kept as a new version
+func RestoreFileVersion(ctx context.Context, storage driver.Driver, path string, id uint) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	path = utils.FixAndCleanPath(path)
+	v, err := GetFileVersion(storage, path, id)
+	if err != nil {
+		return err
+	}
+	var current *model.FileVersion
+	if obj, err := GetUnwrap(ctx, storage, path); err == nil {
+		current, err = keepVersion(ctx, storage, path, obj)
+		if err != nil {
+			return err
+		}
+	}
+	if err = Move(ctx, storage, v.VersionPath, stdpath.Dir(path)); err != nil {
+		if current != nil {
+			revertVersion(ctx, storage, current)
+		}
+		return errors.WithMessage(err, "failed to move the version back")
+	}
+	if err = Remove(ctx, storage, stdpath.Dir(v.VersionPath)); err != nil {
+		log.Warnf("failed remove version dir %s: %+v", stdpath.Dir(v.VersionPath), err)
+	}
+	if err = db.DeleteFileVersionById(v.ID); err != nil {
+		return err
+	}
+	if current != nil {
+		commitVersion(ctx, storage, current)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package op
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func GetDirFingerprint(path string) (*model.DirFingerprint, error) {
+	return db.GetDirFingerprint(path)
+}
+
+func SaveDirFingerprint(f *model.DirFingerprint) error {
+	return db.SaveDirFingerprint(f)
+}
+
+func DeleteDirFingerprints(path string) error {
+	return db.DeleteDirFingerprints(path)
+}
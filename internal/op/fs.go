@@ -2,6 +2,7 @@ package op
 
 import (
 	"context"
+	"io"
 	stdpath "path"
 	"slices"
 	"time"
@@ -375,6 +376,13 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 		return errors.WithMessage(err, "failed to get dst dir")
 	}
 	srcDirPath := stdpath.Dir(srcPath)
+	srcQuotaPaths := quotaPathsOf(storage, srcPath)
+	dstQuotaPaths := quotaPathsOf(storage, stdpath.Join(dstDirPath, srcObj.GetName()))
+	updateQuotaUsage, err := prepareQuotaUsage(ctx, storage, srcPath, srcObj,
+		quotaPathsDiff(srcQuotaPaths, dstQuotaPaths), quotaPathsDiff(dstQuotaPaths, srcQuotaPaths))
+	if err != nil {
+		return err
+	}
 
 	switch s := storage.(type) {
 	case driver.MoveResult:
@@ -397,8 +405,10 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 			}
 		}
 	default:
+		updateQuotaUsage(false)
 		return errs.NotImplement
 	}
+	updateQuotaUsage(err == nil)
 	return errors.WithStack(err)
 }
 
@@ -413,6 +423,13 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 	}
 	srcObj := model.UnwrapObj(srcRawObj)
 	srcDirPath := stdpath.Dir(srcPath)
+	srcQuotaPaths := quotaPathsOf(storage, srcPath)
+	dstQuotaPaths := quotaPathsOf(storage, stdpath.Join(srcDirPath, dstName))
+	updateQuotaUsage, err := prepareQuotaUsage(ctx, storage, srcPath, srcObj,
+		quotaPathsDiff(srcQuotaPaths, dstQuotaPaths), quotaPathsDiff(dstQuotaPaths, srcQuotaPaths))
+	if err != nil {
+		return err
+	}
 
 	switch s := storage.(type) {
 	case driver.RenameResult:
@@ -431,8 +448,10 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 			ClearCache(storage, srcDirPath)
 		}
 	default:
+		updateQuotaUsage(false)
 		return errs.NotImplement
 	}
+	updateQuotaUsage(err == nil)
 	return errors.WithStack(err)
 }
 
@@ -451,6 +470,11 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	if err != nil {
 		return errors.WithMessage(err, "failed to get dst dir")
 	}
+	updateQuotaUsage, err := prepareQuotaUsage(ctx, storage, srcPath, srcObj,
+		nil, quotaPathsOf(storage, stdpath.Join(dstDirPath, srcObj.GetName())))
+	if err != nil {
+		return err
+	}
 
 	switch s := storage.(type) {
 	case driver.CopyResult:
@@ -469,8 +493,10 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 			ClearCache(storage, dstDirPath)
 		}
 	default:
+		updateQuotaUsage(false)
 		return errs.NotImplement
 	}
+	updateQuotaUsage(err == nil)
 	return errors.WithStack(err)
 }
 
@@ -492,6 +518,10 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 		return errors.WithMessage(err, "failed to get object")
 	}
 	dirPath := stdpath.Dir(path)
+	updateQuotaUsage, err := prepareQuotaUsage(ctx, storage, path, rawObj, quotaPathsOf(storage, path), nil)
+	if err != nil {
+		return err
+	}
 
 	switch s := storage.(type) {
 	case driver.Remove:
@@ -504,8 +534,10 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 			}
 		}
 	default:
+		updateQuotaUsage(false)
 		return errs.NotImplement
 	}
+	updateQuotaUsage(err == nil)
 	return errors.WithStack(err)
 }
 
@@ -529,13 +561,32 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	dstPath := stdpath.Join(dstDirPath, file.GetName())
 	tempName := file.GetName() + ".alist_to_delete"
 	tempPath := stdpath.Join(dstDirPath, tempName)
+	var version *model.FileVersion
+	// the existing file which is overwritten in place, its usage is replaced by the new file
+	var overwritten model.Obj
 	fi, err := GetUnwrap(ctx, storage, dstPath)
+	// reserve the usage of the file in the quotas before uploading, the existing file is replaced
+	reserveBytes, reserveFiles := file.GetSize(), int64(1)
+	if err == nil && !fi.IsDir() {
+		reserveBytes, reserveFiles = reserveBytes-fi.GetSize(), 0
+	}
+	release, reserveErr := reserveQuota(ctx, quotaPathsOf(storage, dstPath), reserveBytes, reserveFiles)
+	if reserveErr != nil {
+		return reserveErr
+	}
+	defer release()
 	if err == nil {
 		if fi.GetSize() == 0 {
 			err = Remove(ctx, storage, dstPath)
 			if err != nil {
 				return errors.WithMessagef(err, "while uploading, failed remove existing file which size = 0")
 			}
+		} else if !fi.IsDir() && versioningEnabled(storage) {
+			// keep the old content as a version
+			version, err = keepVersion(ctx, storage, dstPath, fi)
+			if err != nil {
+				return err
+			}
 		} else if storage.Config().NoOverwriteUpload {
 			// try to rename old obj
 			err = Rename(ctx, storage, dstPath, tempName)
@@ -544,6 +595,7 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 			}
 		} else {
 			file.SetExist(fi)
+			overwritten = fi
 		}
 	}
 	err = MakeDir(ctx, storage, dstDirPath)
@@ -560,27 +612,43 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 		up = func(p float64) {}
 	}
 
-	switch s := storage.(type) {
-	case driver.PutResult:
-		var newObj model.Obj
-		newObj, err = s.Put(ctx, parentDir, file, up)
-		if err == nil {
-			if newObj != nil {
-				addCacheObj(storage, dstDirPath, model.WrapObjName(newObj))
-			} else if !utils.IsBool(lazyCache...) {
+	if newObj, ok := putByHash(ctx, storage, parentDir, file); ok {
+		if newObj != nil {
+			addCacheObj(storage, dstDirPath, model.WrapObjName(newObj))
+		} else if !utils.IsBool(lazyCache...) {
+			ClearCache(storage, dstDirPath)
+		}
+		up(100)
+	} else {
+		switch s := storage.(type) {
+		case driver.PutResult:
+			var newObj model.Obj
+			newObj, err = s.Put(ctx, parentDir, file, up)
+			if err == nil {
+				if newObj != nil {
+					addCacheObj(storage, dstDirPath, model.WrapObjName(newObj))
+				} else if !utils.IsBool(lazyCache...) {
+					ClearCache(storage, dstDirPath)
+				}
+			}
+		case driver.Put:
+			err = s.Put(ctx, parentDir, file, up)
+			if err == nil && !utils.IsBool(lazyCache...) {
 				ClearCache(storage, dstDirPath)
 			}
+		default:
+			return errs.NotImplement
 		}
-	case driver.Put:
-		err = s.Put(ctx, parentDir, file, up)
-		if err == nil && !utils.IsBool(lazyCache...) {
-			ClearCache(storage, dstDirPath)
-		}
-	default:
-		return errs.NotImplement
 	}
 	log.Debugf("put file [%s] done", file.GetName())
-	if storage.Config().NoOverwriteUpload && fi != nil && fi.GetSize() > 0 {
+	if version != nil {
+		if err != nil {
+			revertVersion(ctx, storage, version)
+		} else {
+			commitVersion(ctx, storage, version)
+			linkCache.Del(Key(storage, dstPath))
+		}
+	} else if storage.Config().NoOverwriteUpload && fi != nil && fi.GetSize() > 0 {
 		if err != nil {
 			// upload failed, recover old obj
 			err := Rename(ctx, storage, tempPath, file.GetName())
@@ -598,9 +666,55 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 			}
 		}
 	}
+	if err == nil {
+		bytes, files := file.GetSize(), int64(1)
+		if overwritten != nil && !overwritten.IsDir() {
+			bytes, files = bytes-overwritten.GetSize(), 0
+		}
+		increaseQuotaUsage(quotaPathsOf(storage, dstPath), bytes, files)
+		HandleObjEventHook(ctx, model.EventUpload, stdpath.Join(storage.GetStorage().MountPath, dstPath), file)
+	}
 	return errors.WithStack(err)
 }
 
+// putByHash try to create the file by its hashes without uploading the content, return false if the storage
+// doesn't support rapid upload, the hashes are unknown or the storage has no file with the same hashes
+func putByHash(ctx context.Context, storage driver.Driver, dstDir model.Obj, file model.FileStreamer) (model.Obj, bool) {
+	s, ok := storage.(driver.PutByHash)
+	if !ok || file.GetSize() == 0 {
+		return nil, false
+	}
+	hashes := file.GetHash()
+	if !file.IsTrustedHash() {
+		// anyone knowing the hashes of a file would get it by giving them, so the hashes
+		// given by the client are not used, they are computed if the content has been cached
+		f := file.GetFile()
+		if f == nil {
+			return nil, false
+		}
+		hasher := utils.NewMultiHasher(s.PutHashTypes())
+		if _, err := utils.CopyWithBuffer(hasher, io.NewSectionReader(f, 0, file.GetSize())); err != nil {
+			log.Warnf("failed hash [%s], upload it instead: %+v", file.GetName(), err)
+			return nil, false
+		}
+		hashes = *hasher.GetHashInfo()
+	}
+	for _, ht := range s.PutHashTypes() {
+		if len(hashes.GetHash(ht)) != ht.Width {
+			return nil, false
+		}
+	}
+	newObj, err := s.PutByHash(ctx, dstDir, file.GetName(), file.GetSize(), hashes)
+	if err != nil {
+		if !errors.Is(err, errs.RapidUploadMissed) {
+			log.Warnf("failed put [%s] by hash, upload it instead: %+v", file.GetName(), err)
+		}
+		return nil, false
+	}
+	log.Debugf("put file [%s] by hash", file.GetName())
+	return newObj, true
+}
+
 func PutURL(ctx context.Context, storage driver.Driver, dstDirPath, dstName, url string, lazyCache ...bool) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
@@ -1,8 +1,10 @@
 package op
 
 import (
+	"context"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
@@ -109,3 +111,41 @@ func callStorageHooks(typ string, storage driver.Driver) {
 func RegisterStorageHook(hook StorageHook) {
 	storageHooks = append(storageHooks, hook)
 }
+
+// Event
+type EventHook func(event *model.Event)
+
+var eventHooks = make([]EventHook, 0)
+
+func RegisterEventHook(hook EventHook) {
+	eventHooks = append(eventHooks, hook)
+}
+
+// HandleEventHook notify the hooks that something happened,
+// the hooks should not block the caller
+func HandleEventHook(typ string, data any) {
+	event := &model.Event{Type: typ, Time: time.Now(), Data: data}
+	for _, hook := range eventHooks {
+		hook(event)
+	}
+}
+
+// userName get the name of the user who made the request, empty if unknown
+func userName(ctx context.Context) string {
+	if user, ok := ctx.Value("user").(*model.User); ok && user != nil {
+		return user.Username
+	}
+	return ""
+}
+
+// HandleObjEventHook notify the hooks that the obj at path of the mount path was changed
+func HandleObjEventHook(ctx context.Context, typ, path string, obj model.Obj) {
+	if len(eventHooks) == 0 {
+		return
+	}
+	data := model.ObjEventData{Path: path, User: userName(ctx)}
+	if obj != nil {
+		data.Size, data.IsDir = obj.GetSize(), obj.IsDir()
+	}
+	HandleEventHook(typ, data)
+}
@@ -0,0 +1,26 @@
+package op
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func GetObjProps(path string) ([]model.ObjProp, error) {
+	return db.GetObjProps(path)
+}
+
+func GetObjPropsInDir(dir string) ([]model.ObjProp, error) {
+	return db.GetObjPropsInDir(dir)
+}
+
+func SaveObjProps(path string, props []model.ObjProp) error {
+	return db.SaveObjProps(path, props)
+}
+
+func MoveObjProps(srcPath, dstPath string) error {
+	return db.MoveObjProps(srcPath, dstPath)
+}
+
+func DeleteObjProps(path string) error {
+	return db.DeleteObjProps(path)
+}
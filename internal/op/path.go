@@ -1,6 +1,7 @@
 package op
 
 import (
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	stdpath "path"
 	"strings"
@@ -29,6 +30,23 @@ func GetStorageAndActualPath(rawPath string) (storage driver.Driver, actualPath
 	return
 }
 
+// IsHiddenPath check if the actual path is in the trash or versions dir, which are managed by alist
+func IsHiddenPath(actualPath string) bool {
+	actualPath = utils.FixAndCleanPath(actualPath)
+	for _, dir := range []string{conf.TrashDirName, conf.VersionsDirName} {
+		if utils.IsSubPath("/"+dir, actualPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHiddenMountPath is IsHiddenPath for the mount path
+func IsHiddenMountPath(path string) bool {
+	_, actualPath, err := GetStorageAndActualPath(path)
+	return err == nil && IsHiddenPath(actualPath)
+}
+
 // urlTreeSplitLineFormPath 分割path中分割真实路径和UrlTree定义字符串
 func urlTreeSplitLineFormPath(path string) (pp string, file string) {
 	// url.PathUnescape 会移除 // ，手动加回去
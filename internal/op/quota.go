@@ -0,0 +1,244 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	quotaMu     sync.RWMutex
+	quotaPaths  []string
+	quotaLoaded bool
+
+	// the usages of the paths reserved by the changes in progress, so that the concurrent changes
+	// can't pass the check together and exceed the quota
+	reserveMu     sync.Mutex
+	quotaReserved = make(map[string]quotaDelta)
+)
+
+type quotaDelta struct {
+	Bytes int64
+	Files int64
+}
+
+// getQuotaPaths get the paths whose usage is tracked
+func getQuotaPaths() []string {
+	quotaMu.RLock()
+	if quotaLoaded {
+		defer quotaMu.RUnlock()
+		return quotaPaths
+	}
+	quotaMu.RUnlock()
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+	usages, err := db.GetQuotaUsages()
+	if err != nil {
+		log.Errorf("failed load quota usages: %+v", err)
+		return nil
+	}
+	quotaPaths = make([]string, 0, len(usages))
+	for _, u := range usages {
+		quotaPaths = append(quotaPaths, u.Path)
+	}
+	quotaLoaded = true
+	return quotaPaths
+}
+
+// SyncQuotaPaths track the usage of the paths of the quotas set on the users and roles,
+// the usage of the new paths is unknown until they are reconciled
+func SyncQuotaPaths() error {
+	paths, err := db.GetQuotaPaths()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		quotaMu.Lock()
+		quotaLoaded = false
+		quotaMu.Unlock()
+	}()
+	return db.SyncQuotaUsages(paths)
+}
+
+func fixQuotaPaths(quotas model.Quotas) {
+	for i := range quotas {
+		quotas[i].Path = utils.FixAndCleanPath(quotas[i].Path)
+	}
+}
+
+// syncQuotaPaths is called after the quotas of the users or roles changed
+func syncQuotaPaths(old, new model.Quotas) {
+	if utils.SliceEqual(old, new) {
+		return
+	}
+	if err := SyncQuotaPaths(); err != nil {
+		log.Errorf("failed sync quota paths: %+v", err)
+	}
+}
+
+func GetQuotaUsages() ([]model.QuotaUsage, error) {
+	return db.GetQuotaUsages()
+}
+
+func UpdateQuotaUsage(u *model.QuotaUsage) error {
+	return db.UpdateQuotaUsage(u)
+}
+
+// quotaPathsOf get the tracked paths containing the path of the storage,
+// the trash and versions are not counted in any quota
+func quotaPathsOf(storage driver.Driver, actualPath string) []string {
+	actualPath = utils.FixAndCleanPath(actualPath)
+	if IsHiddenPath(actualPath) {
+		return nil
+	}
+	return quotaPathsOfMountPath(stdpath.Join(storage.GetStorage().MountPath, actualPath))
+}
+
+func quotaPathsOfMountPath(path string) []string {
+	var matched []string
+	for _, p := range getQuotaPaths() {
+		if utils.IsSubPath(p, path) {
+			matched = append(matched, p)
+		}
+	}
+	return matched
+}
+
+// quotaPathsDiff get the paths in a but not in b
+func quotaPathsDiff(a, b []string) []string {
+	return utils.SliceFilter(a, func(p string) bool {
+		return !utils.SliceContains(b, p)
+	})
+}
+
+// prepareQuotaUsage count the usage of the obj before it's changed, and reserve the usage added to the
+// quota paths for the user in ctx. The returned func must be called after the change, it updates
+// the usage of the quota paths if the change succeeded and releases the reservation.
+func prepareQuotaUsage(ctx context.Context, storage driver.Driver, path string, obj model.Obj, removed, added []string) (func(succeeded bool), error) {
+	if len(removed) == 0 && len(added) == 0 {
+		return func(bool) {}, nil
+	}
+	bytes, files := objUsage(ctx, storage, path, obj)
+	release, err := reserveQuota(ctx, added, bytes, files)
+	if err != nil {
+		return nil, err
+	}
+	return func(succeeded bool) {
+		if succeeded {
+			increaseQuotaUsage(removed, -bytes, -files)
+			increaseQuotaUsage(added, bytes, files)
+		}
+		release()
+	}, nil
+}
+
+func increaseQuotaUsage(paths []string, bytes, files int64) {
+	if len(paths) == 0 || (bytes == 0 && files == 0) {
+		return
+	}
+	if err := db.IncreaseQuotaUsage(paths, bytes, files); err != nil {
+		log.Errorf("failed update quota usage: %+v", err)
+	}
+}
+
+// objUsage get the total size and count of the files in the obj
+func objUsage(ctx context.Context, storage driver.Driver, path string, obj model.Obj) (bytes, files int64) {
+	if !obj.IsDir() {
+		return obj.GetSize(), 1
+	}
+	objs, err := List(ctx, storage, path, model.ListArgs{})
+	if err != nil {
+		log.Warnf("failed list %s to count usage: %+v", path, err)
+		return 0, 0
+	}
+	for _, o := range objs {
+		b, f := objUsage(ctx, storage, stdpath.Join(path, o.GetName()), o)
+		bytes += b
+		files += f
+	}
+	return bytes, files
+}
+
+// getQuotas get the quotas of the user and the roles of the user
+func getQuotas(user *model.User) []model.Quota {
+	quotas := append([]model.Quota(nil), user.Quotas...)
+	for _, rid := range user.Role {
+		role, err := GetRole(uint(rid))
+		if err != nil {
+			continue
+		}
+		quotas = append(quotas, role.Quotas...)
+	}
+	return quotas
+}
+
+// reserveQuota check if the user in ctx could add the bytes and files to the quota paths,
+// and reserve them until the returned func is called. The usage of a path is shared by all
+// the users, so the quotas of the user are checked against the total under the path.
+func reserveQuota(ctx context.Context, paths []string, bytes, files int64) (func(), error) {
+	user, ok := ctx.Value("user").(*model.User)
+	if !ok || user == nil || len(paths) == 0 || (bytes <= 0 && files <= 0) {
+		return func() {}, nil
+	}
+	quotas := utils.SliceFilter(getQuotas(user), func(q model.Quota) bool {
+		return utils.SliceContains(paths, q.Path) && (q.MaxBytes > 0 || q.MaxFiles > 0)
+	})
+	if len(quotas) == 0 {
+		return func() {}, nil
+	}
+	reserveMu.Lock()
+	defer reserveMu.Unlock()
+	usages, err := db.GetQuotaUsages()
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range usages {
+		r := quotaReserved[u.Path]
+		for _, q := range quotas {
+			if q.Path != u.Path {
+				continue
+			}
+			if q.MaxBytes > 0 && bytes > 0 && u.Bytes+r.Bytes+bytes > q.MaxBytes {
+				return nil, errors.Wrapf(errs.QuotaExceeded, "%s: %d of %d bytes used", q.Path, u.Bytes+r.Bytes, q.MaxBytes)
+			}
+			if q.MaxFiles > 0 && files > 0 && u.Files+r.Files+files > q.MaxFiles {
+				return nil, errors.Wrapf(errs.QuotaExceeded, "%s: %d of %d files used", q.Path, u.Files+r.Files, q.MaxFiles)
+			}
+		}
+	}
+	// all the tracked paths are reserved, so the changes of the other users are checked against them
+	for _, p := range paths {
+		quotaReserved[p] = quotaDelta{quotaReserved[p].Bytes + bytes, quotaReserved[p].Files + files}
+	}
+	return func() {
+		reserveMu.Lock()
+		defer reserveMu.Unlock()
+		for _, p := range paths {
+			r := quotaDelta{quotaReserved[p].Bytes - bytes, quotaReserved[p].Files - files}
+			if r == (quotaDelta{}) {
+				delete(quotaReserved, p)
+			} else {
+				quotaReserved[p] = r
+			}
+		}
+	}, nil
+}
+
+// CheckQuota check if the user in ctx could add the bytes and files to the path of the mount path,
+// it's checked again when the change is made, this is for rejecting the change early
+func CheckQuota(ctx context.Context, path string, bytes, files int64) error {
+	release, err := reserveQuota(ctx, quotaPathsOfMountPath(path), bytes, files)
+	if err != nil {
+		return err
+	}
+	release()
+	return nil
+}
@@ -87,9 +87,14 @@ func CreateRole(r *model.Role) error {
 	for i := range r.PermissionScopes {
 		r.PermissionScopes[i].Path = utils.FixAndCleanPath(r.PermissionScopes[i].Path)
 	}
+	fixQuotaPaths(r.Quotas)
 	roleCache.Del(fmt.Sprint(r.ID))
 	roleCache.Del(r.Name)
-	return db.CreateRole(r)
+	if err := db.CreateRole(r); err != nil {
+		return err
+	}
+	syncQuotaPaths(nil, r.Quotas)
+	return nil
 }
 
 func UpdateRole(r *model.Role) error {
@@ -126,9 +131,14 @@ func UpdateRole(r *model.Role) error {
 			userCache.Del(name)
 		}
 	}
+	fixQuotaPaths(r.Quotas)
 	roleCache.Del(fmt.Sprint(r.ID))
 	roleCache.Del(r.Name)
-	return db.UpdateRole(r)
+	if err := db.UpdateRole(r); err != nil {
+		return err
+	}
+	syncQuotaPaths(old.Quotas, r.Quotas)
+	return nil
 }
 
 func DeleteRole(id uint) error {
@@ -141,5 +151,9 @@ func DeleteRole(id uint) error {
 	}
 	roleCache.Del(fmt.Sprint(id))
 	roleCache.Del(old.Name)
-	return db.DeleteRole(id)
+	if err := db.DeleteRole(id); err != nil {
+		return err
+	}
+	syncQuotaPaths(old.Quotas, nil)
+	return nil
 }
@@ -0,0 +1,62 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateS3MultipartUpload(u *model.S3MultipartUpload) error {
+	return db.CreateS3MultipartUpload(u)
+}
+
+func GetS3MultipartUploadById(id string) (*model.S3MultipartUpload, error) {
+	u, err := db.GetS3MultipartUploadById(id)
+	if err != nil {
+		return nil, errors.WithStack(errs.UploadSessionNotFound)
+	}
+	return u, nil
+}
+
+func GetS3MultipartUploadsByBucket(userId uint, bucket string) ([]model.S3MultipartUpload, error) {
+	return db.GetS3MultipartUploadsByBucket(userId, bucket)
+}
+
+func GetS3MultipartUploadsUpdatedBefore(t time.Time) ([]model.S3MultipartUpload, error) {
+	return db.GetS3MultipartUploadsUpdatedBefore(t)
+}
+
+func DeleteS3MultipartUploadById(id string) error {
+	return db.DeleteS3MultipartUploadById(id)
+}
+
+func GetS3MultipartParts(uploadId string) ([]model.S3MultipartPart, error) {
+	return db.GetS3MultipartParts(uploadId)
+}
+
+func SaveS3MultipartPart(p *model.S3MultipartPart) error {
+	return db.SaveS3MultipartPart(p)
+}
+
+func GetS3ObjectMeta(path string) (*model.S3ObjectMeta, error) {
+	return db.GetS3ObjectMeta(path)
+}
+
+func SaveS3ObjectMeta(m *model.S3ObjectMeta) error {
+	return db.SaveS3ObjectMeta(m)
+}
+
+func DeleteS3ObjectMeta(path string) error {
+	return db.DeleteS3ObjectMeta(path)
+}
+
+func MoveS3ObjectMetas(srcPath, dstPath string) error {
+	return db.MoveS3ObjectMetas(srcPath, dstPath)
+}
+
+func DeleteS3ObjectMetasUnder(path string) error {
+	return db.DeleteS3ObjectMetasUnder(path)
+}
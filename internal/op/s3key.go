@@ -0,0 +1,59 @@
+package op
+
+import (
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+)
+
+// CreateS3AccessKey generate the access key id and the secret of the key, the secret is only returned here
+func CreateS3AccessKey(k *model.S3AccessKey) error {
+	_, err := db.GetS3AccessKeyByUserTitle(k.UserId, k.Title)
+	if err == nil {
+		return errors.New("key with the same title already exists")
+	}
+	k.AccessKeyId = strings.ToUpper(random.String(20))
+	k.SecretAccessKey = random.String(40)
+	k.AddedTime = time.Now()
+	k.LastUsedTime = k.AddedTime
+	return db.CreateS3AccessKey(k)
+}
+
+func GetS3AccessKeysByUserId(userId uint, pageIndex, pageSize int) (keys []model.S3AccessKey, count int64, err error) {
+	return db.GetS3AccessKeysByUserId(userId, pageIndex, pageSize)
+}
+
+func GetS3AccessKeys() ([]model.S3AccessKey, error) {
+	return db.GetS3AccessKeys()
+}
+
+func CountS3AccessKeys() (int64, error) {
+	return db.CountS3AccessKeys()
+}
+
+func GetS3AccessKeyByIdAndUserId(id uint, userId uint) (*model.S3AccessKey, error) {
+	key, err := db.GetS3AccessKeyById(id)
+	if err != nil {
+		return nil, err
+	}
+	if key.UserId != userId {
+		return nil, errors.New("failed get s3 key")
+	}
+	return key, nil
+}
+
+func GetS3AccessKeyByAccessKeyId(accessKeyId string) (*model.S3AccessKey, error) {
+	return db.GetS3AccessKeyByAccessKeyId(accessKeyId)
+}
+
+func UpdateS3AccessKey(k *model.S3AccessKey) error {
+	return db.UpdateS3AccessKey(k)
+}
+
+func DeleteS3AccessKeyById(keyId uint) error {
+	return db.DeleteS3AccessKeyById(keyId)
+}
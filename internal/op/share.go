@@ -0,0 +1,126 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const shareIdLength = 8
+
+func CreateShare(s *model.Share) error {
+	s.Path = utils.FixAndCleanPath(s.Path)
+	s.Downloads = 0
+	s.Created = time.Now()
+	for {
+		s.ID = random.String(shareIdLength)
+		if _, err := db.GetShareById(s.ID); err != nil {
+			break
+		}
+	}
+	return db.CreateShare(s)
+}
+
+func GetShareById(id string) (*model.Share, error) {
+	s, err := db.GetShareById(id)
+	if err != nil {
+		return nil, errors.WithStack(errs.ShareNotFound)
+	}
+	return s, nil
+}
+
+// GetShareByIdAndCreatorId get the share and make sure it belongs to the creator
+func GetShareByIdAndCreatorId(id string, creatorId uint) (*model.Share, error) {
+	s, err := GetShareById(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.CreatorID != creatorId {
+		return nil, errors.WithStack(errs.ShareNotFound)
+	}
+	return s, nil
+}
+
+func GetShares(pageIndex, pageSize int) ([]model.Share, int64, error) {
+	return db.GetShares(pageIndex, pageSize)
+}
+
+func GetSharesByCreatorId(creatorId uint, pageIndex, pageSize int) ([]model.Share, int64, error) {
+	return db.GetSharesByCreatorId(creatorId, pageIndex, pageSize)
+}
+
+func UpdateShare(s *model.Share) error {
+	old, err := GetShareById(s.ID)
+	if err != nil {
+		return err
+	}
+	s.Path = utils.FixAndCleanPath(s.Path)
+	s.CreatorID = old.CreatorID
+	s.Downloads = old.Downloads
+	s.Created = old.Created
+	return db.UpdateShare(s)
+}
+
+func DeleteShareById(id string) error {
+	return db.DeleteShareById(id)
+}
+
+// CheckShare checks whether the share can still be accessed
+func CheckShare(s *model.Share) error {
+	if s.Disabled {
+		return errors.WithStack(errs.ShareDisabled)
+	}
+	if s.IsExpired() {
+		return errors.WithStack(errs.ShareExpired)
+	}
+	return nil
+}
+
+// ValidateShare checks whether the share can still be accessed with the password
+func ValidateShare(s *model.Share, password string) error {
+	if err := CheckShare(s); err != nil {
+		return err
+	}
+	if !s.ValidatePassword(password) {
+		return errors.WithStack(errs.WrongSharePassword)
+	}
+	// the plain passwords kept by the old versions are hashed once they are used
+	if s.Password != "" && s.Salt == "" {
+		s.SetPassword(password)
+		if err := db.UpdateSharePassword(s); err != nil {
+			log.Errorf("failed hash the password of share [%s]: %+v", s.ID, err)
+		}
+	}
+	return nil
+}
+
+// IncreaseShareDownloads counts a download of the share,
+// returns errs.ShareDownloadLimitReached if no downloads are left
+func IncreaseShareDownloads(s *model.Share) error {
+	ok, err := db.IncreaseShareDownloads(s.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.WithStack(errs.ShareDownloadLimitReached)
+	}
+	s.Downloads++
+	return nil
+}
+
+func AddShareAccessLog(l *model.ShareAccessLog) {
+	l.Time = time.Now()
+	if err := db.CreateShareAccessLog(l); err != nil {
+		log.Errorf("failed to add access log of share [%s]: %+v", l.ShareID, err)
+	}
+}
+
+func GetShareAccessLogs(shareId string, pageIndex, pageSize int) ([]model.ShareAccessLog, int64, error) {
+	return db.GetShareAccessLogs(shareId, pageIndex, pageSize)
+}
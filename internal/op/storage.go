@@ -6,13 +6,16 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Xhofe/go-cache"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/pkg/errors"
@@ -101,6 +104,8 @@ func getCurrentGoroutineStack() string {
 func initStorage(ctx context.Context, storage model.Storage, storageDriver driver.Driver) (err error) {
 	storageDriver.SetStorage(storage)
 	driverStorage := storageDriver.GetStorage()
+	// the status saved before is not notified again, even if the driver saves it during the init
+	rememberStorageStatus(driverStorage)
 	defer func() {
 		if err := recover(); err != nil {
 			errInfo := fmt.Sprintf("[panic] err: %v\nstack: %s\n", err, getCurrentGoroutineStack())
@@ -193,6 +198,7 @@ func DisableStorage(ctx context.Context, id uint) error {
 		return errors.WithMessage(err, "failed update storage in db")
 	}
 	storagesMap.Delete(storage.MountPath)
+	detailsCache.Del(storage.MountPath)
 	go callStorageHooks("del", storageDriver)
 	return nil
 }
@@ -217,6 +223,8 @@ func UpdateStorage(ctx context.Context, storage model.Storage) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed update storage in database")
 	}
+	detailsCache.Del(oldStorage.MountPath)
+	detailsCache.Del(storage.MountPath)
 	if storage.Disabled {
 		return nil
 	}
@@ -270,6 +278,8 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 		}
 		// delete the storage in the memory
 		storagesMap.Delete(storage.MountPath)
+		detailsCache.Del(storage.MountPath)
+		forgetStorageStatus(storage.ID)
 		go callStorageHooks("del", storageDriver)
 	}
 	// delete the storage in the database
@@ -289,6 +299,7 @@ func MustSaveDriverStorage(driver driver.Driver) {
 
 func saveDriverStorage(driver driver.Driver) error {
 	storage := driver.GetStorage()
+	notifyStorageStatus(storage)
 	addition := driver.GetAddition()
 	str, err := utils.Json.MarshalToString(addition)
 	if err != nil {
@@ -302,6 +313,40 @@ func saveDriverStorage(driver driver.Driver) error {
 	return nil
 }
 
+// storageStatuses keeps the last saved statuses of the storages by id, the status is saved
+// whenever it is set by the init or the driver, so the errors are notified when the status changes to them
+var (
+	storageStatuses   = make(map[uint]string)
+	storageStatusesMu sync.Mutex
+)
+
+func rememberStorageStatus(storage *model.Storage) {
+	storageStatusesMu.Lock()
+	defer storageStatusesMu.Unlock()
+	storageStatuses[storage.ID] = storage.Status
+}
+
+func notifyStorageStatus(storage *model.Storage) {
+	storageStatusesMu.Lock()
+	last, ok := storageStatuses[storage.ID]
+	storageStatuses[storage.ID] = storage.Status
+	storageStatusesMu.Unlock()
+	if (ok && last == storage.Status) || storage.Status == "" || storage.Status == WORK || storage.Status == DISABLED {
+		return
+	}
+	HandleEventHook(model.EventStorageError, model.StorageEventData{
+		MountPath: storage.MountPath,
+		Driver:    storage.Driver,
+		Status:    storage.Status,
+	})
+}
+
+func forgetStorageStatus(id uint) {
+	storageStatusesMu.Lock()
+	defer storageStatusesMu.Unlock()
+	delete(storageStatuses, id)
+}
+
 // getStoragesByPath get storage by longest match path, contains balance storage.
 // for example, there is /a/b,/a/c,/a/d/e,/a/d/e.balance
 // getStoragesByPath(/a/d/e/f) => /a/d/e,/a/d/e.balance
@@ -385,3 +430,35 @@ func GetBalancedStorage(path string) driver.Driver {
 		return storages[i]
 	}
 }
+
+var detailsCache = cache.NewMemCache(cache.WithShards[*model.StorageDetails](4))
+var detailsG singleflight.Group[*model.StorageDetails]
+
+// GetStorageDetails get the capacity of the storage, the result is cached for a while
+func GetStorageDetails(ctx context.Context, storage driver.Driver, refresh ...bool) (*model.StorageDetails, error) {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	wd, ok := storage.(driver.WithDetails)
+	if !ok {
+		return nil, errors.WithStack(errs.NotImplement)
+	}
+	key := storage.GetStorage().MountPath
+	if !utils.IsBool(refresh...) {
+		if details, ok := detailsCache.Get(key); ok {
+			return details, nil
+		}
+	}
+	details, err, _ := detailsG.Do(key, func() (*model.StorageDetails, error) {
+		// the result is shared by the callers, so it mustn't be canceled with the ctx of the first one
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+		defer cancel()
+		details, err := wd.GetDetails(ctx)
+		if err != nil {
+			return nil, err
+		}
+		detailsCache.Set(key, details, cache.WithEx[*model.StorageDetails](time.Minute*5))
+		return details, nil
+	})
+	return details, err
+}
@@ -88,3 +88,28 @@ func setupStorages(t *testing.T) {
 		}
 	}
 }
+
+func TestStorageErrorEvent(t *testing.T) {
+	var statuses []string
+	op.RegisterEventHook(func(e *model.Event) {
+		if data, ok := e.Data.(model.StorageEventData); ok && e.Type == model.EventStorageError && data.MountPath == "/status" {
+			statuses = append(statuses, data.Status)
+		}
+	})
+	if _, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: "/status", Addition: `{"root_folder_path":"."}`}); err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the driver fails after the init, and again with the same error, then recovers and fails again
+	for _, status := range []string{"token expired", "token expired", op.WORK, "token expired"} {
+		storage.GetStorage().SetStatus(status)
+		op.MustSaveDriverStorage(storage)
+	}
+	expected := []string{"token expired", "token expired"}
+	if !utils.SliceEqual(statuses, expected) {
+		t.Errorf("expected the errors %+v, got %+v", expected, statuses)
+	}
+}
@@ -0,0 +1,100 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// TrashObj move the object to the native trash of the storage
+func TrashObj(ctx context.Context, storage driver.Driver, path string) (string, error) {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return "", errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	if utils.PathEqual(path, "/") {
+		return "", errors.New("delete root folder is not allowed, please goto the manage page to delete the storage instead")
+	}
+	s, ok := storage.(driver.Trash)
+	if !ok {
+		return "", errs.NotImplement
+	}
+	path = utils.FixAndCleanPath(path)
+	rawObj, err := Get(ctx, storage, path)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to get object")
+	}
+	id, err := s.TrashObj(ctx, model.UnwrapObj(rawObj))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	delCacheObj(storage, stdpath.Dir(path), rawObj)
+	if rawObj.IsDir() {
+		ClearCache(storage, path)
+	}
+	return id, nil
+}
+
+// RestoreTrash restore the object from the native trash of the storage,
+// path is where the object was removed from
+func RestoreTrash(ctx context.Context, storage driver.Driver, id, path string) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	s, ok := storage.(driver.Trash)
+	if !ok {
+		return errs.NotImplement
+	}
+	err := s.RestoreTrash(ctx, id)
+	if err == nil {
+		ClearCache(storage, stdpath.Dir(utils.FixAndCleanPath(path)))
+	}
+	return errors.WithStack(err)
+}
+
+// PurgeTrash delete the object from the native trash of the storage permanently
+func PurgeTrash(ctx context.Context, storage driver.Driver, id string) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	s, ok := storage.(driver.Trash)
+	if !ok {
+		return errs.NotImplement
+	}
+	return errors.WithStack(s.PurgeTrash(ctx, id))
+}
+
+func CreateTrashItem(t *model.TrashItem) error {
+	t.Deleted = time.Now()
+	return db.CreateTrashItem(t)
+}
+
+func GetTrashItemById(id uint) (*model.TrashItem, error) {
+	t, err := db.GetTrashItemById(id)
+	if err != nil {
+		return nil, errors.WithStack(errs.TrashItemNotFound)
+	}
+	return t, nil
+}
+
+func GetTrashItems(pageIndex, pageSize int) ([]model.TrashItem, int64, error) {
+	return db.GetTrashItems(pageIndex, pageSize)
+}
+
+func GetTrashItemsByUserId(userId uint, pageIndex, pageSize int) ([]model.TrashItem, int64, error) {
+	return db.GetTrashItemsByUserId(userId, pageIndex, pageSize)
+}
+
+func GetTrashItemsDeletedBefore(t time.Time) ([]model.TrashItem, error) {
+	return db.GetTrashItemsDeletedBefore(t)
+}
+
+func DeleteTrashItemById(id uint) error {
+	return db.DeleteTrashItemById(id)
+}
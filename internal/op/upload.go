@@ -0,0 +1,34 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateUploadSession(s *model.UploadSession) error {
+	return db.CreateUploadSession(s)
+}
+
+func GetUploadSessionById(id string) (*model.UploadSession, error) {
+	s, err := db.GetUploadSessionById(id)
+	if err != nil {
+		return nil, errors.WithStack(errs.UploadSessionNotFound)
+	}
+	return s, nil
+}
+
+func GetUploadSessionsUpdatedBefore(t time.Time) ([]model.UploadSession, error) {
+	return db.GetUploadSessionsUpdatedBefore(t)
+}
+
+func UpdateUploadSession(s *model.UploadSession) error {
+	return db.UpdateUploadSession(s)
+}
+
+func DeleteUploadSessionById(id string) error {
+	return db.DeleteUploadSessionById(id)
+}
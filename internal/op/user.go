@@ -78,11 +78,13 @@ func GetUsers(pageIndex, pageSize int) (users []model.User, count int64, err err
 
 func CreateUser(u *model.User) error {
 	u.BasePath = utils.FixAndCleanPath(u.BasePath)
+	fixQuotaPaths(u.Quotas)
 
 	err := db.CreateUser(u)
 	if err != nil {
 		return err
 	}
+	syncQuotaPaths(nil, u.Quotas)
 
 	roles, err := GetRolesByUserID(u.ID)
 	if err == nil {
@@ -108,7 +110,11 @@ func DeleteUserById(id uint) error {
 		return errs.DeleteAdminOrGuest
 	}
 	userCache.Del(old.Username)
-	return db.DeleteUserById(id)
+	if err := db.DeleteUserById(id); err != nil {
+		return err
+	}
+	syncQuotaPaths(old.Quotas, nil)
+	return nil
 }
 
 func UpdateUser(u *model.User) error {
@@ -124,6 +130,7 @@ func UpdateUser(u *model.User) error {
 	}
 	userCache.Del(old.Username)
 	u.BasePath = utils.FixAndCleanPath(u.BasePath)
+	fixQuotaPaths(u.Quotas)
 	if len(u.Role) > 0 {
 		roles, err := GetRolesByUserID(u.ID)
 		if err == nil {
@@ -135,7 +142,11 @@ func UpdateUser(u *model.User) error {
 			}
 		}
 	}
-	return db.UpdateUser(u)
+	if err := db.UpdateUser(u); err != nil {
+		return err
+	}
+	syncQuotaPaths(old.Quotas, u.Quotas)
+	return nil
 }
 
 func Cancel2FAByUser(u *model.User) error {
@@ -0,0 +1,88 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetWebdavLocks get the locks which have not expired
+func GetWebdavLocks(pageIndex, pageSize int) ([]model.WebdavLock, int64, error) {
+	if err := db.DeleteExpiredWebdavLocks(time.Now()); err != nil {
+		log.Warnf("failed delete expired webdav locks: %+v", err)
+	}
+	return db.GetWebdavLocks(pageIndex, pageSize)
+}
+
+func GetWebdavLockByToken(token string) (*model.WebdavLock, error) {
+	l, err := db.GetWebdavLockByToken(token)
+	if err != nil {
+		return nil, errors.WithStack(errs.WebdavLockNotFound)
+	}
+	return l, nil
+}
+
+// CreateWebdavLock return errs.WebdavLocked if the root is locked by another lock
+func CreateWebdavLock(l *model.WebdavLock, now time.Time) error {
+	created, err := db.CreateWebdavLock(l, now)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return errors.WithStack(errs.WebdavLocked)
+	}
+	return nil
+}
+
+// RefreshWebdavLock return errs.WebdavLocked if the lock is held by a request
+func RefreshWebdavLock(l *model.WebdavLock, now time.Time) error {
+	refreshed, err := db.RefreshWebdavLock(l, now)
+	if err != nil {
+		return err
+	}
+	if !refreshed {
+		return errors.WithStack(errs.WebdavLocked)
+	}
+	return nil
+}
+
+// HoldWebdavLocks return errs.WebdavLocked if any of the locks is held by another request
+func HoldWebdavLocks(tokens []string, holder string, now, until time.Time) error {
+	held, err := db.HoldWebdavLocks(tokens, holder, now, until)
+	if err != nil {
+		return err
+	}
+	if !held {
+		return errors.WithStack(errs.WebdavLocked)
+	}
+	return nil
+}
+
+func RenewWebdavLocks(tokens []string, holder string, until time.Time) error {
+	return db.RenewWebdavLocks(tokens, holder, until)
+}
+
+func ReleaseWebdavLocks(tokens []string, holder string) error {
+	return db.ReleaseWebdavLocks(tokens, holder)
+}
+
+// UnlockWebdavLock return errs.WebdavLocked if the lock is held by a request
+func UnlockWebdavLock(token string, now time.Time) error {
+	unlocked, err := db.UnlockWebdavLock(token, now)
+	if err != nil {
+		return err
+	}
+	if !unlocked {
+		return errors.WithStack(errs.WebdavLocked)
+	}
+	return nil
+}
+
+// DeleteWebdavLockByToken force release the lock even if it is held
+func DeleteWebdavLockByToken(token string) error {
+	return db.DeleteWebdavLockByToken(token)
+}
@@ -0,0 +1,221 @@
+package schedule
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/search"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// maxSeenItems is the max number of rss items remembered in the job state
+const maxSeenItems = 1000
+
+func taskNames(tasks []task.TaskExtensionInfo) string {
+	names := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		names = append(names, t.GetName())
+	}
+	return strings.Join(names, "\n")
+}
+
+func runCopy(ctx context.Context, job *model.ScheduledJob) (string, error) {
+	var args model.CopyJobArgs
+	if err := utils.Json.UnmarshalFromString(job.Args, &args); err != nil {
+		return "", errors.WithStack(err)
+	}
+	srcPaths := []string{args.SrcDir}
+	if len(args.Names) > 0 {
+		srcPaths = srcPaths[:0]
+		for _, name := range args.Names {
+			srcPaths = append(srcPaths, stdpath.Join(args.SrcDir, name))
+		}
+	}
+	var tasks []task.TaskExtensionInfo
+	for _, srcPath := range srcPaths {
+		t, err := fs.Copy(ctx, srcPath, args.DstDir)
+		if err != nil {
+			return taskNames(tasks), errors.WithMessagef(err, "failed copy %s", srcPath)
+		}
+		if t != nil {
+			tasks = append(tasks, t)
+		}
+	}
+	return taskNames(tasks), nil
+}
+
+func runSync(ctx context.Context, job *model.ScheduledJob) (string, error) {
+	var args model.SyncJobArgs
+	if err := utils.Json.UnmarshalFromString(job.Args, &args); err != nil {
+		return "", errors.WithStack(err)
+	}
+	t, err := fs.Sync(ctx, args.SrcDir, args.DstDir, fs.SyncArgs{Delete: args.Delete})
+	if err != nil {
+		return "", err
+	}
+	return t.GetName(), nil
+}
+
+func runIndex(ctx context.Context, job *model.ScheduledJob) (string, error) {
+	var args model.IndexJobArgs
+	if err := utils.Json.UnmarshalFromString(job.Args, &args); err != nil {
+		return "", errors.WithStack(err)
+	}
+	if search.Running() {
+		return "", errors.New("index is running")
+	}
+	if len(args.Paths) == 0 {
+		if err := search.Clear(ctx); err != nil {
+			return "", err
+		}
+		maxDepth := args.MaxDepth
+		if maxDepth == 0 {
+			maxDepth = setting.GetInt(conf.MaxIndexDepth, 20)
+		}
+		return "build index on /", search.BuildIndex(ctx, []string{"/"}, conf.SlicesMap[conf.IgnorePaths], maxDepth, true)
+	}
+	if !search.Config(ctx).AutoUpdate {
+		return "", errors.New("update is not supported for current index")
+	}
+	for _, path := range args.Paths {
+		if err := search.Del(ctx, path); err != nil {
+			return "", errors.WithMessagef(err, "failed delete index on %s", path)
+		}
+	}
+	return "update index on " + strings.Join(args.Paths, ", "),
+		search.BuildIndex(ctx, args.Paths, conf.SlicesMap[conf.IgnorePaths], args.MaxDepth, false)
+}
+
+func runIndexRefresh(ctx context.Context, job *model.ScheduledJob) (string, error) {
+	var args model.IndexRefreshJobArgs
+	if err := utils.Json.UnmarshalFromString(job.Args, &args); err != nil {
+		return "", errors.WithStack(err)
+	}
+	if len(args.Paths) == 0 {
+		args.Paths = []string{"/"}
+	}
+	return "refresh index on " + strings.Join(args.Paths, ", "), search.Refresh(ctx, args.Paths)
+}
+
+func runOfflineDownload(ctx context.Context, job *model.ScheduledJob) (string, error) {
+	var args model.OfflineDownloadJobArgs
+	if err := utils.Json.UnmarshalFromString(job.Args, &args); err != nil {
+		return "", errors.WithStack(err)
+	}
+	urls := args.Urls
+	var seen []string
+	if args.Rss != "" {
+		if job.State != "" {
+			_ = utils.Json.UnmarshalFromString(job.State, &seen)
+		}
+		items, err := fetchFeed(ctx, args.Rss)
+		if err != nil {
+			return "", errors.WithMessage(err, "failed fetch rss")
+		}
+		for _, item := range items {
+			if !utils.SliceContains(seen, item) {
+				urls = append(urls, item)
+			}
+		}
+	}
+	var tasks []task.TaskExtensionInfo
+	var err error
+	for _, url := range urls {
+		var t task.TaskExtensionInfo
+		t, err = tool.AddURL(ctx, &tool.AddURLArgs{
+			URL:          url,
+			DstDirPath:   args.DstDir,
+			Tool:         args.Tool,
+			DeletePolicy: tool.DeletePolicy(args.DeletePolicy),
+		})
+		if err != nil {
+			err = errors.WithMessagef(err, "failed add %s", url)
+			break
+		}
+		tasks = append(tasks, t)
+		if args.Rss != "" {
+			seen = append(seen, url)
+		}
+	}
+	if args.Rss != "" {
+		if len(seen) > maxSeenItems {
+			seen = seen[len(seen)-maxSeenItems:]
+		}
+		job.State, _ = utils.Json.MarshalToString(seen)
+	}
+	return taskNames(tasks), err
+}
+
+type feed struct {
+	// rss
+	Items []struct {
+		Link      string `xml:"link"`
+		Enclosure struct {
+			URL string `xml:"url,attr"`
+		} `xml:"enclosure"`
+	} `xml:"channel>item"`
+	// atom
+	Entries []struct {
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+	} `xml:"entry"`
+}
+
+// fetchFeed get the download urls of the items in a rss or atom feed,
+// the enclosure is preferred over the link
+func fetchFeed(ctx context.Context, url string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status: %s", res.Status)
+	}
+	var f feed
+	if err = xml.NewDecoder(io.LimitReader(res.Body, 16*utils.MB)).Decode(&f); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var urls []string
+	for _, item := range f.Items {
+		u := item.Enclosure.URL
+		if u == "" {
+			u = strings.TrimSpace(item.Link)
+		}
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
+	for _, entry := range f.Entries {
+		var u string
+		for _, link := range entry.Links {
+			if link.Rel == "enclosure" || (u == "" && (link.Rel == "" || link.Rel == "alternate")) {
+				u = link.Href
+			}
+		}
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls, nil
+}
@@ -0,0 +1,247 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type entry struct {
+	job      model.ScheduledJob
+	schedule cron.Schedule
+	next     time.Time
+	running  *atomic.Bool
+}
+
+var (
+	scheduler *cron.Cron
+	mu        sync.Mutex
+	entries   = make(map[uint]*entry)
+)
+
+// Init load the enabled jobs and check if any of them is due every minute
+func Init() {
+	if err := Reload(); err != nil {
+		log.Errorf("failed load scheduled jobs: %+v", err)
+	}
+	scheduler = cron.NewCron(time.Minute)
+	scheduler.Do(tick)
+}
+
+// Reload load the enabled jobs from the database
+func Reload() error {
+	jobs, err := db.GetEnabledScheduledJobs()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	mu.Lock()
+	defer mu.Unlock()
+	old := entries
+	entries = make(map[uint]*entry, len(jobs))
+	for _, job := range jobs {
+		s, err := cron.Parse(job.Cron)
+		if err != nil {
+			log.Warnf("skip scheduled job [%s]: %+v", job.Name, err)
+			continue
+		}
+		e := &entry{job: job, schedule: s, next: s.Next(now), running: &atomic.Bool{}}
+		// keep the running flag, so that a job won't run twice at the same time after reload
+		if o, ok := old[job.ID]; ok {
+			e.running = o.running
+		}
+		entries[job.ID] = e
+	}
+	return nil
+}
+
+// NextRun get the next time the job will run, nil if it's not scheduled
+func NextRun(id uint) *time.Time {
+	mu.Lock()
+	defer mu.Unlock()
+	if e, ok := entries[id]; ok && !e.next.IsZero() {
+		next := e.next
+		return &next
+	}
+	return nil
+}
+
+func tick() {
+	now := time.Now()
+	var due []*entry
+	mu.Lock()
+	for _, e := range entries {
+		if !e.next.IsZero() && !e.next.After(now) {
+			e.next = e.schedule.Next(now)
+			due = append(due, e)
+		}
+	}
+	mu.Unlock()
+	for _, e := range due {
+		e := e
+		go run(e.job, e.running)
+	}
+}
+
+// RunNow run the job immediately, no matter if it's disabled
+func RunNow(job *model.ScheduledJob) error {
+	running := &atomic.Bool{}
+	mu.Lock()
+	if e, ok := entries[job.ID]; ok {
+		running = e.running
+	}
+	mu.Unlock()
+	if running.Load() {
+		return errors.New("the job is running")
+	}
+	go run(*job, running)
+	return nil
+}
+
+func run(job model.ScheduledJob, running *atomic.Bool) {
+	if !running.CompareAndSwap(false, true) {
+		log.Infof("skip scheduled job [%s], the previous run is not finished", job.Name)
+		return
+	}
+	defer running.Store(false)
+	// the state may be changed by the previous run
+	if j, err := db.GetScheduledJobById(job.ID); err == nil {
+		job = *j
+	}
+	r := &model.ScheduledJobRun{
+		JobID:     job.ID,
+		StartTime: time.Now(),
+	}
+	msg, err := execute(&job)
+	r.EndTime = time.Now()
+	if err != nil {
+		log.Errorf("failed run scheduled job [%s]: %+v", job.Name, err)
+		r.Message = err.Error()
+	} else {
+		r.Success = true
+		r.Message = msg
+	}
+	if err := db.CreateScheduledJobRun(r); err != nil {
+		log.Errorf("failed record run of scheduled job [%s]: %+v", job.Name, err)
+	}
+	if err := db.UpdateScheduledJobLastRun(job.ID, r.StartTime, job.State); err != nil {
+		log.Errorf("failed update scheduled job [%s]: %+v", job.Name, err)
+	}
+}
+
+func execute(job *model.ScheduledJob) (string, error) {
+	creator, err := op.GetUserById(job.CreatorID)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get creator")
+	}
+	ctx := context.WithValue(context.Background(), "user", creator)
+	switch job.Type {
+	case model.ScheduledJobCopy:
+		return runCopy(ctx, job)
+	case model.ScheduledJobSync:
+		return runSync(ctx, job)
+	case model.ScheduledJobIndex:
+		return runIndex(ctx, job)
+	case model.ScheduledJobIndexRefresh:
+		return runIndexRefresh(ctx, job)
+	case model.ScheduledJobOfflineDownload:
+		return runOfflineDownload(ctx, job)
+	default:
+		return "", errors.Errorf("unknown job type: %s", job.Type)
+	}
+}
+
+// Validate check the cron expression and the arguments of the job
+func Validate(job *model.ScheduledJob) error {
+	if _, err := cron.Parse(job.Cron); err != nil {
+		return err
+	}
+	var args any
+	switch job.Type {
+	case model.ScheduledJobCopy:
+		args = &model.CopyJobArgs{}
+	case model.ScheduledJobSync:
+		args = &model.SyncJobArgs{}
+	case model.ScheduledJobIndex:
+		args = &model.IndexJobArgs{}
+	case model.ScheduledJobIndexRefresh:
+		args = &model.IndexRefreshJobArgs{}
+	case model.ScheduledJobOfflineDownload:
+		args = &model.OfflineDownloadJobArgs{}
+	default:
+		return errors.Errorf("unknown job type: %s", job.Type)
+	}
+	if job.Args == "" {
+		job.Args = "{}"
+	}
+	return errors.WithMessage(utils.Json.UnmarshalFromString(job.Args, args), "invalid args")
+}
+
+func GetJobs(pageIndex, pageSize int) ([]model.ScheduledJob, int64, error) {
+	jobs, total, err := db.GetScheduledJobs(pageIndex, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range jobs {
+		jobs[i].NextRun = NextRun(jobs[i].ID)
+	}
+	return jobs, total, nil
+}
+
+func GetJob(id uint) (*model.ScheduledJob, error) {
+	job, err := db.GetScheduledJobById(id)
+	if err != nil {
+		return nil, err
+	}
+	job.NextRun = NextRun(job.ID)
+	return job, nil
+}
+
+func CreateJob(job *model.ScheduledJob) error {
+	if err := Validate(job); err != nil {
+		return err
+	}
+	if err := db.CreateScheduledJob(job); err != nil {
+		return err
+	}
+	return Reload()
+}
+
+func UpdateJob(job *model.ScheduledJob) error {
+	old, err := db.GetScheduledJobById(job.ID)
+	if err != nil {
+		return err
+	}
+	if err := Validate(job); err != nil {
+		return err
+	}
+	// the fields maintained by the scheduler are not editable
+	job.CreatorID, job.Created, job.LastRun = old.CreatorID, old.Created, old.LastRun
+	if job.Type == old.Type {
+		job.State = old.State
+	}
+	if err := db.UpdateScheduledJob(job); err != nil {
+		return err
+	}
+	return Reload()
+}
+
+func DeleteJob(id uint) error {
+	if err := db.DeleteScheduledJobById(id); err != nil {
+		return err
+	}
+	return Reload()
+}
+
+func GetJobRuns(id uint, pageIndex, pageSize int) ([]model.ScheduledJobRun, int64, error) {
+	return db.GetScheduledJobRuns(id, pageIndex, pageSize)
+}
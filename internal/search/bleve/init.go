@@ -8,7 +8,10 @@ import (
 )
 
 var config = searcher.Config{
-	Name: "bleve",
+	Name:    "bleve",
+	Content: true,
+	// 1: the fields of the filters, 2: the content with the term vectors
+	Version: 2,
 }
 
 func Init(indexPath *string) (bleve.Index, error) {
@@ -26,6 +29,17 @@ func Init(indexPath *string) (bleve.Index, error) {
 		nameFieldMapping := bleve.NewKeywordFieldMapping()
 		searchNodeMapping.AddFieldMappingsAt("name", nameFieldMapping)
 		indexMapping.AddDocumentMapping("SearchNode", searchNodeMapping)
+		// the nodes are indexed by value, which don't implement the classifier,
+		// so the fields used by the filters are mapped in the default mapping
+		indexMapping.DefaultMapping.AddFieldMappingsAt("parent", bleve.NewKeywordFieldMapping())
+		indexMapping.DefaultMapping.AddFieldMappingsAt("modified", bleve.NewDateTimeFieldMapping())
+		indexMapping.DefaultMapping.AddFieldMappingsAt("ext", bleve.NewKeywordFieldMapping())
+		indexMapping.DefaultMapping.AddFieldMappingsAt("storage", bleve.NewKeywordFieldMapping())
+		indexMapping.DefaultMapping.AddFieldMappingsAt("taken", bleve.NewDateTimeFieldMapping())
+		// the content is stored with the term vectors for cutting the snippets
+		contentFieldMapping := bleve.NewTextFieldMapping()
+		contentFieldMapping.IncludeTermVectors = true
+		indexMapping.DefaultMapping.AddFieldMappingsAt("content", contentFieldMapping)
 		fileIndex, err = bleve.New(*indexPath, indexMapping)
 		if err != nil {
 			return nil, err
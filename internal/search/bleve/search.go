@@ -3,6 +3,7 @@ package bleve
 import (
 	"context"
 	"os"
+	"time"
 
 	query2 "github.com/blevesearch/bleve/v2/search/query"
 
@@ -27,36 +28,172 @@ func (b *Bleve) Config() searcher.Config {
 
 func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	var queries []query2.Query
-	query := bleve.NewMatchQuery(req.Keywords)
-	query.SetField("name")
-	queries = append(queries, query)
+	if req.Keywords != "" {
+		query := bleve.NewMatchQuery(req.Keywords)
+		query.SetField("name")
+		contentQuery := bleve.NewMatchQuery(req.Keywords)
+		contentQuery.SetField("content")
+		queries = append(queries, bleve.NewDisjunctionQuery(query, contentQuery))
+	} else {
+		queries = append(queries, bleve.NewMatchAllQuery())
+	}
 	if req.Scope != 0 {
 		isDir := req.Scope == 1
 		isDirQuery := bleve.NewBoolFieldQuery(isDir)
+		isDirQuery.SetField("is_dir")
 		queries = append(queries, isDirQuery)
 	}
+	if req.Parent != "" && req.Parent != "/" {
+		parentQuery := bleve.NewTermQuery(req.Parent)
+		parentQuery.SetField("parent")
+		childrenQuery := bleve.NewPrefixQuery(req.Parent + "/")
+		childrenQuery.SetField("parent")
+		queries = append(queries, bleve.NewDisjunctionQuery(parentQuery, childrenQuery))
+	}
+	queries = append(queries, filterQueries(req.SearchFilter)...)
 	reqQuery := bleve.NewConjunctionQuery(queries...)
 	search := bleve.NewSearchRequest(reqQuery)
-	search.SortBy([]string{"name"})
+	sortBy := "name"
+	if req.OrderBy != "" {
+		sortBy = req.OrderBy
+	}
+	if req.OrderDirection == "desc" {
+		sortBy = "-" + sortBy
+	}
+	search.SortBy([]string{sortBy})
 	search.From = (req.Page - 1) * req.PerPage
 	search.Size = req.PerPage
-	search.Fields = []string{"*"}
+	search.Fields = []string{"parent", "name", "is_dir", "size", "modified", "ext", "obj_type", "storage", "content"}
+	search.IncludeLocations = req.Keywords != ""
 	searchResults, err := b.BIndex.Search(search)
 	if err != nil {
 		log.Errorf("search error: %+v", err)
 		return nil, 0, err
 	}
 	res, err := utils.SliceConvert(searchResults.Hits, func(src *search2.DocumentMatch) (model.SearchNode, error) {
-		return model.SearchNode{
+		node := model.SearchNode{
 			Parent: src.Fields["parent"].(string),
 			Name:   src.Fields["name"].(string),
 			IsDir:  src.Fields["is_dir"].(bool),
 			Size:   int64(src.Fields["size"].(float64)),
-		}, nil
+		}
+		// the nodes indexed by the old versions don't have the fields below
+		if modified, ok := src.Fields["modified"].(string); ok {
+			node.Modified, _ = time.Parse(time.RFC3339, modified)
+		}
+		node.Ext, _ = src.Fields["ext"].(string)
+		if objType, ok := src.Fields["obj_type"].(float64); ok {
+			node.ObjType = int(objType)
+		}
+		node.Storage, _ = src.Fields["storage"].(string)
+		if content, ok := src.Fields["content"].(string); ok {
+			var matches []searcher.Match
+			for _, locations := range src.Locations["content"] {
+				for _, location := range locations {
+					matches = append(matches, searcher.Match{Start: int(location.Start), End: int(location.End)})
+				}
+			}
+			node.Snippet = searcher.NewSnippet(content, matches)
+		}
+		return node, nil
 	})
 	return res, int64(searchResults.Total), nil
 }
 
+func filterQueries(f model.SearchFilter) []query2.Query {
+	var queries []query2.Query
+	inclusive := true
+	if f.MinSize != nil || f.MaxSize != nil {
+		var minSize, maxSize *float64
+		if f.MinSize != nil {
+			v := float64(*f.MinSize)
+			minSize = &v
+		}
+		if f.MaxSize != nil {
+			v := float64(*f.MaxSize)
+			maxSize = &v
+		}
+		sizeQuery := bleve.NewNumericRangeInclusiveQuery(minSize, maxSize, &inclusive, &inclusive)
+		sizeQuery.SetField("size")
+		queries = append(queries, sizeQuery)
+	}
+	if f.ModifiedAfter != nil || f.ModifiedBefore != nil {
+		var start, end time.Time
+		if f.ModifiedAfter != nil {
+			start = *f.ModifiedAfter
+		}
+		if f.ModifiedBefore != nil {
+			end = *f.ModifiedBefore
+		}
+		modifiedQuery := bleve.NewDateRangeQuery(start, end)
+		modifiedQuery.SetField("modified")
+		queries = append(queries, modifiedQuery)
+	}
+	if len(f.Exts) > 0 {
+		queries = append(queries, termsQuery("ext", f.Exts))
+	}
+	if len(f.Types) > 0 {
+		var typeQueries []query2.Query
+		for _, t := range f.Types {
+			v := float64(t)
+			typeQuery := bleve.NewNumericRangeInclusiveQuery(&v, &v, &inclusive, &inclusive)
+			typeQuery.SetField("obj_type")
+			typeQueries = append(typeQueries, typeQuery)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(typeQueries...))
+	}
+	if len(f.Storages) > 0 {
+		queries = append(queries, termsQuery("storage", f.Storages))
+	}
+	if f.MinWidth != nil {
+		queries = append(queries, minQuery("width", float64(*f.MinWidth)))
+	}
+	if f.MinHeight != nil {
+		queries = append(queries, minQuery("height", float64(*f.MinHeight)))
+	}
+	if f.MinDuration != nil || f.MaxDuration != nil {
+		// the files without duration are not matched
+		minDuration, minInclusive := 0.0, false
+		if f.MinDuration != nil && *f.MinDuration > 0 {
+			minDuration, minInclusive = *f.MinDuration, true
+		}
+		durationQuery := bleve.NewNumericRangeInclusiveQuery(&minDuration, f.MaxDuration, &minInclusive, &inclusive)
+		durationQuery.SetField("duration")
+		queries = append(queries, durationQuery)
+	}
+	if f.TakenAfter != nil || f.TakenBefore != nil {
+		var start, end time.Time
+		if f.TakenAfter != nil {
+			start = *f.TakenAfter
+		}
+		if f.TakenBefore != nil {
+			end = *f.TakenBefore
+		}
+		takenQuery := bleve.NewDateRangeQuery(start, end)
+		takenQuery.SetField("taken")
+		queries = append(queries, takenQuery)
+	}
+	return queries
+}
+
+func minQuery(field string, min float64) query2.Query {
+	inclusive := true
+	q := bleve.NewNumericRangeInclusiveQuery(&min, nil, &inclusive, nil)
+	q.SetField(field)
+	return q
+}
+
+// termsQuery match any of the terms in the keyword field
+func termsQuery(field string, terms []string) query2.Query {
+	var queries []query2.Query
+	for _, term := range terms {
+		q := bleve.NewTermQuery(term)
+		q.SetField(field)
+		queries = append(queries, q)
+	}
+	return bleve.NewDisjunctionQuery(queries...)
+}
+
 func (b *Bleve) Index(ctx context.Context, node model.SearchNode) error {
 	return b.BIndex.Index(uuid.NewString(), node)
 }
@@ -188,10 +188,16 @@ func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth
 }
 
 func Del(ctx context.Context, prefix string) error {
+	if err := op.DeleteDirFingerprints(prefix); err != nil {
+		log.Errorf("delete dir fingerprints error: %+v", err)
+	}
 	return instance.Del(ctx, prefix)
 }
 
 func Clear(ctx context.Context) error {
+	if err := op.DeleteDirFingerprints("/"); err != nil {
+		log.Errorf("delete dir fingerprints error: %+v", err)
+	}
 	return instance.Clear(ctx)
 }
 
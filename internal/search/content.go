@@ -0,0 +1,69 @@
+package search
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/search/extract"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// shouldIndexContent check the searcher, the storage opt-in, the size limit and the extension allow-list
+func shouldIndexContent(storage driver.Driver, obj model.Obj) bool {
+	if instance == nil || !instance.Config().Content || !storage.GetStorage().IndexContent || obj.IsDir() {
+		return false
+	}
+	if obj.GetSize() > int64(setting.GetInt(conf.SearchContentMaxSize, 10))*1024*1024 {
+		return false
+	}
+	ext := utils.Ext(obj.GetName())
+	for _, allowed := range strings.Split(setting.GetStr(conf.SearchContentExts), ",") {
+		if strings.ToLower(strings.TrimSpace(allowed)) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// getContent download the file and extract the text from it
+func getContent(ctx context.Context, storage driver.Driver, actualPath string, obj model.Obj) (string, error) {
+	link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get link")
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Obj: obj, Ctx: ctx}, link)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get stream")
+	}
+	defer ss.Close()
+	data, err := io.ReadAll(io.LimitReader(ss, obj.GetSize()))
+	if err != nil {
+		return "", errors.WithMessage(err, "failed read file")
+	}
+	return extract.Extract(utils.Ext(obj.GetName()), data)
+}
+
+func fillContent(ctx context.Context, node *model.SearchNode, storage driver.Driver, actualPath string, obj model.Obj) {
+	if !shouldIndexContent(storage, obj) {
+		return
+	}
+	content, err := getContent(ctx, storage, actualPath, obj)
+	if err != nil {
+		log.Warnf("failed extract content of %s: %+v", path.Join(node.Parent, node.Name), err)
+		return
+	}
+	node.Content = content
+}
@@ -0,0 +1,62 @@
+package extract
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTextLength is the max length in bytes of the extracted text, the rest is dropped
+const MaxTextLength = 1 << 20
+
+// maxDecompressSize limits the size of the decompressed parts of a document
+const maxDecompressSize = 64 << 20
+
+// Extract the plain text from the document, the ext decides how the data is parsed,
+// all the extensions other than pdf and the office documents are taken as plain text
+func Extract(ext string, data []byte) (string, error) {
+	var (
+		text string
+		err  error
+	)
+	switch strings.ToLower(ext) {
+	case "pdf":
+		text, err = extractPDF(data)
+	case "docx":
+		text, err = extractDocx(data)
+	case "xlsx":
+		text, err = extractXlsx(data)
+	case "pptx":
+		text, err = extractPptx(data)
+	default:
+		text = extractText(data)
+	}
+	if err != nil {
+		return "", err
+	}
+	return truncate(strings.TrimSpace(text), MaxTextLength), nil
+}
+
+func extractText(data []byte) string {
+	// the binary files disguised as text are ignored
+	head := data
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	if bytes.IndexByte(head, 0) != -1 {
+		return ""
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	return strings.ToValidUTF8(string(data), "")
+}
+
+// truncate the string to at most n bytes without breaking a character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
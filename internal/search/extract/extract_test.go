@@ -0,0 +1,90 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractDocx(t *testing.T) {
+	data := zipFiles(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="w"><w:body><w:p><w:r><w:t>Hello</w:t></w:r><w:r><w:t xml:space="preserve"> world</w:t></w:r></w:p><w:p><w:r><w:t>second</w:t></w:r></w:p></w:body></w:document>`,
+		"word/styles.xml":   `<w:styles xmlns:w="w"><w:t>ignored</w:t></w:styles>`,
+	})
+	text, err := Extract("docx", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Hello world\nsecond" {
+		t.Errorf("unexpected text: %q", text)
+	}
+}
+
+func TestExtractPptxOrder(t *testing.T) {
+	data := zipFiles(t, map[string]string{
+		"ppt/slides/slide10.xml": `<p:sld xmlns:p="p" xmlns:a="a"><a:p><a:t>ten</a:t></a:p></p:sld>`,
+		"ppt/slides/slide2.xml":  `<p:sld xmlns:p="p" xmlns:a="a"><a:p><a:t>two</a:t></a:p></p:sld>`,
+	})
+	text, err := Extract("pptx", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "two\nten" {
+		t.Errorf("unexpected text: %q", text)
+	}
+}
+
+func TestExtractPDF(t *testing.T) {
+	var content bytes.Buffer
+	zw := zlib.NewWriter(&content)
+	_, _ = zw.Write([]byte("BT /F1 12 Tf 72 712 Td (Hello \\(PDF\\)) Tj 0 -14 Td [(Wor) -20 (ld) -300 (again)] TJ ET"))
+	_ = zw.Close()
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+	pdf.WriteString(fmt.Sprintf("4 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", content.Len()))
+	pdf.Write(content.Bytes())
+	pdf.WriteString("\nendstream\nendobj\n%%EOF\n")
+	text, err := Extract("pdf", pdf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Hello (PDF)\nWorld again" {
+		t.Errorf("unexpected text: %q", text)
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	text, err := Extract("md", []byte("\xef\xbb\xbf# title\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "# title" {
+		t.Errorf("unexpected text: %q", text)
+	}
+	text, _ = Extract("txt", []byte("bin\x00ary"))
+	if text != "" {
+		t.Errorf("binary file should be ignored, got %q", text)
+	}
+	if long := truncate(strings.Repeat("中", 10), 10); long != strings.Repeat("中", 3) {
+		t.Errorf("unexpected truncated text: %q", long)
+	}
+}
@@ -0,0 +1,117 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func extractDocx(data []byte) (string, error) {
+	return extractOffice(data, func(name string) bool {
+		return name == "word/document.xml"
+	})
+}
+
+func extractXlsx(data []byte) (string, error) {
+	// the cells refer to the shared strings by index, and the inline strings are in the sheets
+	return extractOffice(data, func(name string) bool {
+		return name == "xl/sharedStrings.xml" || strings.HasPrefix(name, "xl/worksheets/sheet")
+	})
+}
+
+func extractPptx(data []byte) (string, error) {
+	return extractOffice(data, func(name string) bool {
+		return strings.HasPrefix(name, "ppt/slides/slide")
+	})
+}
+
+var partNumberReg = regexp.MustCompile(`(\d+)\.xml$`)
+
+// partNumber return the number in the part name, e.g. ppt/slides/slide12.xml -> 12
+func partNumber(name string) int {
+	m := partNumberReg.FindStringSubmatch(name)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// extractOffice extract the text from the xml parts selected by match of an office open xml package
+func extractOffice(data []byte, match func(name string) bool) (string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	var parts []*zip.File
+	for _, f := range reader.File {
+		if strings.HasSuffix(f.Name, ".xml") && match(f.Name) {
+			parts = append(parts, f)
+		}
+	}
+	// keep the order of the slides and sheets
+	sort.SliceStable(parts, func(i, j int) bool {
+		return partNumber(parts[i].Name) < partNumber(parts[j].Name)
+	})
+	var sb strings.Builder
+	for _, f := range parts {
+		rc, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		err = xmlText(io.LimitReader(rc, maxDecompressSize), &sb)
+		_ = rc.Close()
+		if err != nil {
+			return "", err
+		}
+		if sb.Len() > MaxTextLength {
+			break
+		}
+	}
+	return sb.String(), nil
+}
+
+// xmlText write the character data of the <t> elements, which hold the text in
+// all of docx, xlsx and pptx, and break lines at the end of paragraphs and rows
+func xmlText(r io.Reader, sb *strings.Builder) error {
+	decoder := xml.NewDecoder(r)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteByte('\t')
+			case "br":
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p", "si", "row":
+				sb.WriteByte('\n')
+			case "c":
+				sb.WriteByte('\t')
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// extractPDF is a best-effort extractor, which walks through the content streams and
+// collects the strings shown by the text operators. The fonts are not parsed, so the
+// text drawn with custom encodings or CID fonts without unicode mapping is skipped.
+func extractPDF(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF")) {
+		return "", errors.New("not a pdf file")
+	}
+	var sb strings.Builder
+	for pos := 0; pos < len(data) && sb.Len() <= MaxTextLength; {
+		i := bytes.Index(data[pos:], []byte("stream"))
+		if i == -1 {
+			break
+		}
+		start := pos + i
+		pos = start + len("stream")
+		// skip the "endstream" keywords
+		if start >= 3 && string(data[start-3:start]) == "end" {
+			continue
+		}
+		// the stream data begin after the EOL of the keyword
+		if pos < len(data) && data[pos] == '\r' {
+			pos++
+		}
+		if pos < len(data) && data[pos] == '\n' {
+			pos++
+		}
+		end := bytes.Index(data[pos:], []byte("endstream"))
+		if end == -1 {
+			break
+		}
+		raw := data[pos : pos+end]
+		pos += end + len("endstream")
+		dict := streamDict(data[:start])
+		content, ok := decodeStream(dict, raw)
+		if !ok {
+			continue
+		}
+		pdfContentText(content, &sb)
+	}
+	return sb.String(), nil
+}
+
+// streamDict return the dictionary right before the stream keyword
+func streamDict(before []byte) string {
+	i := bytes.LastIndex(before, []byte("obj"))
+	if i == -1 {
+		return ""
+	}
+	return string(before[i:])
+}
+
+func decodeStream(dict string, raw []byte) ([]byte, bool) {
+	// the images, fonts and other embedded files never contain the page text
+	for _, skip := range []string{"/Image", "/Length1", "/Length2", "/EmbeddedFile", "/XRef", "/Metadata"} {
+		if strings.Contains(dict, skip) {
+			return nil, false
+		}
+	}
+	if !strings.Contains(dict, "/Filter") {
+		return raw, true
+	}
+	// only the deflated streams are supported, which are used by almost all the generators
+	if strings.Count(dict, "/Filter") > 1 || !strings.Contains(dict, "/FlateDecode") ||
+		strings.Contains(dict, "/DCTDecode") || strings.Contains(dict, "/LZWDecode") ||
+		strings.Contains(dict, "/ASCII85Decode") || strings.Contains(dict, "/ASCIIHexDecode") {
+		return nil, false
+	}
+	r, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, false
+	}
+	defer r.Close()
+	// keep what has been inflated even if the stream is truncated
+	content, _ := io.ReadAll(io.LimitReader(r, maxDecompressSize))
+	return content, len(content) > 0
+}
+
+// pdfContentText interpret the text operators of a content stream
+func pdfContentText(content []byte, sb *strings.Builder) {
+	if !bytes.Contains(content, []byte("BT")) {
+		return
+	}
+	var (
+		operands []string
+		numbers  []float64
+		inArray  bool
+		// the vertical position of the text line, which decides where to break lines
+		curY, lastY float64
+		shown       bool
+		space       bool
+	)
+	newLine := func() {
+		if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+			sb.WriteByte('\n')
+		}
+	}
+	write := func(s string) {
+		if shown && curY != lastY {
+			newLine()
+		} else if space && sb.Len() > 0 && !strings.HasSuffix(sb.String(), " ") {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(s)
+		lastY, shown, space = curY, true, false
+	}
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, n := pdfLiteralString(content[i:])
+			operands = append(operands, s)
+			i += n
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			// skip the inline dictionaries
+			end := bytes.Index(content[i:], []byte(">>"))
+			if end == -1 {
+				return
+			}
+			i += end + 2
+		case c == '<':
+			end := bytes.IndexByte(content[i:], '>')
+			if end == -1 {
+				return
+			}
+			operands = append(operands, pdfHexString(content[i+1:i+end]))
+			i += end + 1
+		case c == '[':
+			inArray = true
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case isPDFDelimiter(c) || isPDFSpace(c):
+			i++
+		default:
+			j := i
+			for j < len(content) && !isPDFDelimiter(content[j]) && !isPDFSpace(content[j]) {
+				j++
+			}
+			token := string(content[i:j])
+			i = j
+			if f, err := strconv.ParseFloat(token, 64); err == nil {
+				// a large negative offset in the TJ array is a space between words
+				if inArray && f < -200 {
+					operands = append(operands, " ")
+				}
+				numbers = append(numbers, f)
+				continue
+			}
+			switch token {
+			case "Tj", "TJ":
+				write(strings.Join(operands, ""))
+			case "'", "\"":
+				newLine()
+				write(strings.Join(operands, ""))
+			case "T*":
+				newLine()
+			case "BT":
+				curY = 0
+			case "Tm":
+				if len(numbers) == 6 {
+					curY = numbers[5]
+				}
+			case "Td", "TD":
+				if len(numbers) == 2 {
+					curY += numbers[1]
+					space = numbers[0] != 0
+				}
+			case "BI":
+				// skip the inline images
+				end := bytes.Index(content[i:], []byte("EI"))
+				if end == -1 {
+					return
+				}
+				i += end + 2
+			}
+			operands = operands[:0]
+			numbers = numbers[:0]
+		}
+	}
+	newLine()
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) != -1
+}
+
+// pdfLiteralString parse the string enclosed in the parentheses, return it and the bytes consumed
+func pdfLiteralString(data []byte) (string, int) {
+	var buf []byte
+	depth := 0
+	i := 0
+	for ; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '(':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfTextString(buf), i + 1
+			}
+		case '\\':
+			i++
+			if i >= len(data) {
+				return pdfTextString(buf), i
+			}
+			e := data[i]
+			switch e {
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// line continuation
+				if e == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+					i++
+				}
+			default:
+				if e >= '0' && e <= '7' {
+					v := 0
+					j := 0
+					for ; j < 3 && i+j < len(data) && data[i+j] >= '0' && data[i+j] <= '7'; j++ {
+						v = v*8 + int(data[i+j]-'0')
+					}
+					i += j - 1
+					buf = append(buf, byte(v))
+				} else {
+					buf = append(buf, e)
+				}
+			}
+			continue
+		}
+		buf = append(buf, c)
+	}
+	return pdfTextString(buf), i
+}
+
+func pdfHexString(data []byte) string {
+	var (
+		buf  []byte
+		half = -1
+	)
+	for _, c := range data {
+		var v int
+		switch {
+		case c >= '0' && c <= '9':
+			v = int(c - '0')
+		case c >= 'a' && c <= 'f':
+			v = int(c-'a') + 10
+		case c >= 'A' && c <= 'F':
+			v = int(c-'A') + 10
+		default:
+			continue
+		}
+		if half == -1 {
+			half = v
+		} else {
+			buf = append(buf, byte(half<<4|v))
+			half = -1
+		}
+	}
+	if half != -1 {
+		buf = append(buf, byte(half<<4))
+	}
+	return pdfTextString(buf)
+}
+
+// pdfTextString decode the bytes as UTF-16BE with BOM, or UTF-8, or the Latin-1
+// compatible PDFDocEncoding, the strings with control characters are glyph ids and dropped
+func pdfTextString(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+		u := make([]uint16, 0, len(b)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		return string(utf16.Decode(u))
+	}
+	for _, c := range b {
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' {
+			return ""
+		}
+	}
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	r := make([]rune, len(b))
+	for i, c := range b {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
@@ -0,0 +1,30 @@
+package search
+
+import (
+	"context"
+	"path"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/media"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	log "github.com/sirupsen/logrus"
+)
+
+// fillMediaInfo fill the fields of the media filters, the media info is shared with FsGet by the cache
+func fillMediaInfo(ctx context.Context, node *model.SearchNode, storage driver.Driver, actualPath string, obj model.Obj) {
+	if obj.IsDir() || !setting.GetBool(conf.SearchMediaInfo) || !media.Supported(obj.GetName()) {
+		return
+	}
+	nodePath := path.Join(node.Parent, node.Name)
+	info, err := media.GetByObj(ctx, storage, actualPath, nodePath, obj)
+	if err != nil {
+		log.Warnf("failed get media info of %s: %+v", nodePath, err)
+		return
+	}
+	node.Width = info.Width
+	node.Height = info.Height
+	node.Duration = info.Duration
+	node.Taken = info.Taken
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/meilisearch/meilisearch-go"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,6 +17,11 @@ import (
 type searchDocument struct {
 	ID string `json:"id"`
 	model.SearchNode
+	// meilisearch can only filter and sort by numbers, not the time strings
+	ModifiedUnix int64 `json:"modified_unix"`
+	TakenUnix    int64 `json:"taken_unix,omitempty"`
+	// all the ancestors, so that the nodes under a dir can be filtered by "parents = dir"
+	Parents []string `json:"parents"`
 }
 
 type Meilisearch struct {
@@ -23,6 +29,7 @@ type Meilisearch struct {
 	IndexUid             string
 	FilterableAttributes []string
 	SearchableAttributes []string
+	SortableAttributes   []string
 }
 
 func (m *Meilisearch) Config() searcher.Config {
@@ -34,22 +41,41 @@ func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.
 		AttributesToSearchOn: m.SearchableAttributes,
 		Page:                 int64(req.Page),
 		HitsPerPage:          int64(req.PerPage),
+		ShowMatchesPosition:  req.Keywords != "",
 	}
+	var filters []string
 	if req.Scope != 0 {
-		mReq.Filter = fmt.Sprintf("is_dir = %v", req.Scope == 1)
+		filters = append(filters, fmt.Sprintf("is_dir = %v", req.Scope == 1))
+	}
+	if req.Parent != "" && req.Parent != "/" {
+		filters = append(filters, fmt.Sprintf("parents = %s", quote(req.Parent)))
+	}
+	filters = append(filters, filterExprs(req.SearchFilter)...)
+	if len(filters) > 0 {
+		mReq.Filter = filters
+	}
+	if req.OrderBy != "" {
+		sortBy := req.OrderBy
+		if sortBy == "modified" {
+			sortBy = "modified_unix"
+		}
+		direction := "asc"
+		if req.OrderDirection == "desc" {
+			direction = "desc"
+		}
+		mReq.Sort = []string{sortBy + ":" + direction}
 	}
 	search, err := m.Client.Index(m.IndexUid).Search(req.Keywords, mReq)
 	if err != nil {
 		return nil, 0, err
 	}
 	nodes, err := utils.SliceConvert(search.Hits, func(src any) (model.SearchNode, error) {
-		srcMap := src.(map[string]any)
-		return model.SearchNode{
-			Parent: srcMap["parent"].(string),
-			Name:   srcMap["name"].(string),
-			IsDir:  srcMap["is_dir"].(bool),
-			Size:   int64(srcMap["size"].(float64)),
-		}, nil
+		hit := src.(map[string]any)
+		node := toSearchNode(hit)
+		if content, ok := hit["content"].(string); ok {
+			node.Snippet = searcher.NewSnippet(content, contentMatches(hit))
+		}
+		return node, nil
 	})
 	if err != nil {
 		return nil, 0, err
@@ -63,11 +89,16 @@ func (m *Meilisearch) Index(ctx context.Context, node model.SearchNode) error {
 
 func (m *Meilisearch) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
 	documents, _ := utils.SliceConvert(nodes, func(src model.SearchNode) (*searchDocument, error) {
-
-		return &searchDocument{
-			ID:         uuid.NewString(),
-			SearchNode: src,
-		}, nil
+		document := &searchDocument{
+			ID:           uuid.NewString(),
+			SearchNode:   src,
+			ModifiedUnix: src.Modified.Unix(),
+			Parents:      ancestors(src.Parent),
+		}
+		if src.Taken != nil {
+			document.TakenUnix = src.Taken.Unix()
+		}
+		return document, nil
 	})
 
 	_, err := m.Client.Index(m.IndexUid).AddDocuments(documents)
@@ -100,13 +131,8 @@ func (m *Meilisearch) getDocumentsByParent(ctx context.Context, parent string) (
 	}
 	return utils.SliceConvert(result.Results, func(src map[string]any) (*searchDocument, error) {
 		return &searchDocument{
-			ID: src["id"].(string),
-			SearchNode: model.SearchNode{
-				Parent: src["parent"].(string),
-				Name:   src["name"].(string),
-				IsDir:  src["is_dir"].(bool),
-				Size:   int64(src["size"].(float64)),
-			},
+			ID:         src["id"].(string),
+			SearchNode: toSearchNode(src),
 		}, nil
 	})
 }
@@ -225,3 +251,100 @@ func (m *Meilisearch) getTaskStatus(ctx context.Context, taskUID int64) (meilise
 	}
 	return forTask.Status, nil
 }
+
+func toSearchNode(src map[string]any) model.SearchNode {
+	node := model.SearchNode{
+		Parent: src["parent"].(string),
+		Name:   src["name"].(string),
+		IsDir:  src["is_dir"].(bool),
+		Size:   int64(src["size"].(float64)),
+	}
+	// the documents indexed by the old versions don't have the fields below
+	if modified, ok := src["modified"].(string); ok {
+		node.Modified, _ = time.Parse(time.RFC3339, modified)
+	}
+	node.Ext, _ = src["ext"].(string)
+	if objType, ok := src["obj_type"].(float64); ok {
+		node.ObjType = int(objType)
+	}
+	node.Storage, _ = src["storage"].(string)
+	return node
+}
+
+// contentMatches return the byte ranges of the matched terms in the content
+func contentMatches(hit map[string]any) []searcher.Match {
+	positions, _ := hit["_matchesPosition"].(map[string]any)
+	list, _ := positions["content"].([]any)
+	var matches []searcher.Match
+	for _, item := range list {
+		position, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		start, _ := position["start"].(float64)
+		length, _ := position["length"].(float64)
+		matches = append(matches, searcher.Match{Start: int(start), End: int(start + length)})
+	}
+	return matches
+}
+
+func filterExprs(f model.SearchFilter) []string {
+	var filters []string
+	if f.MinSize != nil {
+		filters = append(filters, fmt.Sprintf("size >= %d", *f.MinSize))
+	}
+	if f.MaxSize != nil {
+		filters = append(filters, fmt.Sprintf("size <= %d", *f.MaxSize))
+	}
+	if f.ModifiedAfter != nil {
+		filters = append(filters, fmt.Sprintf("modified_unix >= %d", f.ModifiedAfter.Unix()))
+	}
+	if f.ModifiedBefore != nil {
+		filters = append(filters, fmt.Sprintf("modified_unix < %d", f.ModifiedBefore.Unix()))
+	}
+	if len(f.Exts) > 0 {
+		filters = append(filters, fmt.Sprintf("ext IN [%s]", strings.Join(utils.MustSliceConvert(f.Exts, quote), ",")))
+	}
+	if len(f.Types) > 0 {
+		filters = append(filters, fmt.Sprintf("obj_type IN [%s]", strings.Join(utils.MustSliceConvert(f.Types, strconv.Itoa), ",")))
+	}
+	if len(f.Storages) > 0 {
+		filters = append(filters, fmt.Sprintf("storage IN [%s]", strings.Join(utils.MustSliceConvert(f.Storages, quote), ",")))
+	}
+	if f.MinWidth != nil {
+		filters = append(filters, fmt.Sprintf("width >= %d", *f.MinWidth))
+	}
+	if f.MinHeight != nil {
+		filters = append(filters, fmt.Sprintf("height >= %d", *f.MinHeight))
+	}
+	if f.MinDuration != nil {
+		filters = append(filters, fmt.Sprintf("duration >= %f", *f.MinDuration))
+	}
+	if f.MaxDuration != nil {
+		// the files without duration are not matched
+		filters = append(filters, "duration > 0", fmt.Sprintf("duration <= %f", *f.MaxDuration))
+	}
+	if f.TakenAfter != nil {
+		filters = append(filters, fmt.Sprintf("taken_unix >= %d", f.TakenAfter.Unix()))
+	}
+	if f.TakenBefore != nil {
+		filters = append(filters, fmt.Sprintf("taken_unix < %d", f.TakenBefore.Unix()))
+	}
+	return filters
+}
+
+func quote(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	return "'" + strings.ReplaceAll(s, "'", "\\'") + "'"
+}
+
+// ancestors return the dir and all of its ancestors, e.g. /a/b -> [/ /a /a/b]
+func ancestors(dir string) []string {
+	dir = utils.FixAndCleanPath(dir)
+	res := []string{dir}
+	for dir != "/" {
+		dir = path.Dir(dir)
+		res = append(res, dir)
+	}
+	return res
+}
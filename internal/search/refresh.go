@@ -0,0 +1,364 @@
+package search
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Refresh update the index of the dirs under the paths whose fingerprints changed since the last refresh,
+// the dirs whose modified time and size in the listing of their parents are unchanged are not listed again,
+// but the indexed dirs under them are still walked, as the modified time of a dir only reflects its direct children,
+// the storages offering a change feed only re-list the dirs reported by the feed
+func Refresh(ctx context.Context, paths []string) error {
+	if instance == nil {
+		return errs.SearchNotAvailable
+	}
+	// the searcher must support getting and deleting the nodes by path
+	if !instance.Config().AutoUpdate {
+		return errors.New("refresh is not supported for current index")
+	}
+	quit := make(chan struct{}, 1)
+	if !Quit.CompareAndSwap(nil, &quit) {
+		return errs.BuildIndexIsRunning
+	}
+	defer Quit.Store(nil)
+	admin, err := op.GetAdmin()
+	if err != nil {
+		return err
+	}
+	r := &refresher{
+		ctx:         context.WithValue(ctx, "user", admin),
+		quit:        quit,
+		maxDepth:    setting.GetInt(conf.MaxIndexDepth, 20),
+		ignorePaths: conf.SlicesMap[conf.IgnorePaths],
+	}
+	log.Infof("refresh index for: %+v", paths)
+	for _, p := range paths {
+		if err = r.walk(utils.FixAndCleanPath(p), nil, true); err != nil {
+			return err
+		}
+	}
+	if r.stopped {
+		log.Infof("refresh index for %+v stopped by StopIndex", paths)
+	}
+	log.Infof("success refresh index, %d dirs visited, %d dirs changed, %d dirs skipped", r.visited, r.changed, r.skipped)
+	return nil
+}
+
+type refresher struct {
+	ctx         context.Context
+	quit        chan struct{}
+	stopped     bool
+	maxDepth    int
+	ignorePaths []string
+	visited     int
+	changed     int
+	skipped     int
+}
+
+func (r *refresher) isStopped() bool {
+	if r.stopped {
+		return true
+	}
+	select {
+	case <-r.quit:
+		r.stopped = true
+	default:
+	}
+	return r.stopped
+}
+
+func (r *refresher) skip(dir string) bool {
+	for _, ignorePath := range r.ignorePaths {
+		if strings.HasPrefix(dir, ignorePath) {
+			return true
+		}
+	}
+	if storage, _, err := op.GetStorageAndActualPath(dir); err == nil && storage.GetStorage().DisableIndex {
+		return true
+	}
+	// the depth is counted from the root as the auto update does
+	return dir != "/" && strings.Count(dir, "/") > r.maxDepth
+}
+
+// walk refresh the dir and the dirs under it, obj is the dir in the listing of its parent,
+// the mount paths of the storages which offer a change feed are refreshed by the changes if feed is true
+func (r *refresher) walk(dir string, obj model.Obj, feed bool) error {
+	if r.isStopped() || r.skip(dir) {
+		return nil
+	}
+	unchanged, err := r.unchanged(dir, obj)
+	if err != nil {
+		return err
+	}
+	if unchanged {
+		r.skipped++
+		return r.walkIndexed(dir)
+	}
+	if feed {
+		if storage, err := op.GetStorageByMountPath(dir); err == nil {
+			if _, ok := storage.(driver.IndexChanges); ok {
+				return r.refreshByChanges(storage)
+			}
+		}
+	}
+	subDirs, err := r.refreshDir(dir, obj)
+	if err != nil {
+		return err
+	}
+	for _, subDir := range subDirs {
+		if err = r.walk(path.Join(dir, subDir.GetName()), subDir, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkIndexed walk the indexed sub dirs of the dir without listing it again,
+// they are not in a listing, so they are listed to find the changes under them
+func (r *refresher) walkIndexed(dir string) error {
+	nodes, err := instance.Get(r.ctx, dir)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if !node.IsDir {
+			continue
+		}
+		if err = r.walk(path.Join(dir, node.Name), nil, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unchanged check whether the dir is the same as the last refresh by its modified time and size
+// in the listing of its parent, the dirs without the modified time and the mount paths are always listed
+func (r *refresher) unchanged(dir string, obj model.Obj) (bool, error) {
+	if obj == nil || obj.ModTime().IsZero() {
+		return false, nil
+	}
+	if _, actualPath, err := op.GetStorageAndActualPath(dir); err != nil || actualPath == "/" {
+		return false, nil
+	}
+	f, err := op.GetDirFingerprint(dir)
+	if err != nil || f == nil {
+		return false, err
+	}
+	return f.DirSize == obj.GetSize() && f.DirModified.Equal(truncateTime(obj.ModTime())), nil
+}
+
+func (r *refresher) refreshByChanges(storage driver.Driver) error {
+	mountPath := storage.GetStorage().MountPath
+	feed := storage.(driver.IndexChanges)
+	root, err := op.GetDirFingerprint(mountPath)
+	if err != nil {
+		return err
+	}
+	if root != nil && root.Cursor != "" {
+		dirs, cursor, err := feed.GetIndexChanges(r.ctx, root.Cursor)
+		if err == nil {
+			for _, dir := range mapset.NewSet[string](dirs...).ToSlice() {
+				if err = r.refreshChangedDir(path.Join(mountPath, dir)); err != nil {
+					return err
+				}
+			}
+			return r.saveCursor(mountPath, cursor)
+		}
+		log.Warnf("failed get the changes of %s, walk it instead: %+v", mountPath, err)
+	}
+	// take the cursor before walking, so that the changes during the walk are not missed
+	_, cursor, err := feed.GetIndexChanges(r.ctx, "")
+	if err != nil {
+		log.Warnf("failed get the change cursor of %s: %+v", mountPath, err)
+	}
+	if err = r.walk(mountPath, nil, false); err != nil {
+		return err
+	}
+	if r.stopped {
+		return nil
+	}
+	return r.saveCursor(mountPath, cursor)
+}
+
+// refreshChangedDir refresh the dir reported by the change feed, and walk into the new dirs under it
+func (r *refresher) refreshChangedDir(dir string) error {
+	if r.isStopped() || r.skip(dir) {
+		return nil
+	}
+	subDirs, err := r.refreshDir(dir, nil)
+	if err != nil {
+		return err
+	}
+	for _, subDir := range subDirs {
+		subPath := path.Join(dir, subDir.GetName())
+		f, err := op.GetDirFingerprint(subPath)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			if err = r.walk(subPath, subDir, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *refresher) saveCursor(mountPath, cursor string) error {
+	root, err := op.GetDirFingerprint(mountPath)
+	if err != nil {
+		return err
+	}
+	if root == nil {
+		root = &model.DirFingerprint{Path: mountPath}
+	}
+	root.Cursor = cursor
+	root.Updated = time.Now()
+	return op.SaveDirFingerprint(root)
+}
+
+// refreshDir list the dir, update its index if the fingerprint changed, and return the sub dirs,
+// obj is the dir in the listing of its parent, which is nil if it is not listed
+func (r *refresher) refreshDir(dir string, obj model.Obj) ([]model.Obj, error) {
+	r.visited++
+	objs, err := fs.List(r.ctx, dir, &fs.ListArgs{Refresh: true, NoLog: true})
+	if err != nil {
+		// the dir may be removed during refreshing, it will be deleted from the index with its parent
+		log.Warnf("failed list %s while refreshing index: %+v", dir, err)
+		return nil, nil
+	}
+	subDirs := utils.SliceFilter(objs, func(obj model.Obj) bool {
+		return obj.IsDir()
+	})
+	f := newDirFingerprint(dir, objs)
+	old, err := op.GetDirFingerprint(dir)
+	if err != nil {
+		return nil, err
+	}
+	if obj != nil {
+		f.DirModified = truncateTime(obj.ModTime())
+		f.DirSize = obj.GetSize()
+	} else if old != nil {
+		f.DirModified, f.DirSize = old.DirModified, old.DirSize
+	}
+	if old != nil {
+		f.Cursor = old.Cursor
+	}
+	if f.Equal(old) {
+		if !f.DirModified.Equal(old.DirModified) || f.DirSize != old.DirSize {
+			return subDirs, op.SaveDirFingerprint(f)
+		}
+		return subDirs, nil
+	}
+	r.changed++
+	if err = updateDir(r.ctx, dir, objs); err != nil {
+		return nil, errors.WithMessagef(err, "failed update index of %s", dir)
+	}
+	return subDirs, op.SaveDirFingerprint(f)
+}
+
+func newDirFingerprint(dir string, objs []model.Obj) *model.DirFingerprint {
+	f := &model.DirFingerprint{
+		Path:    dir,
+		Count:   len(objs),
+		Updated: time.Now(),
+	}
+	for _, obj := range objs {
+		f.Size += obj.GetSize()
+		if obj.ModTime().After(f.Modified) {
+			f.Modified = obj.ModTime()
+		}
+	}
+	f.Modified = truncateTime(f.Modified)
+	return f
+}
+
+// truncateTime drop the part of the time which can't be kept by all the databases
+func truncateTime(t time.Time) time.Time {
+	return t.Truncate(time.Second).UTC()
+}
+
+// updateDir make the index of the children of the dir the same as the objs
+func updateDir(ctx context.Context, dir string, objs []model.Obj) error {
+	nodes, err := instance.Get(ctx, dir)
+	if err != nil {
+		return err
+	}
+	indexed := make(map[string]model.SearchNode, len(nodes))
+	duplicated := mapset.NewSet[string]()
+	for _, node := range nodes {
+		if _, ok := indexed[node.Name]; ok {
+			duplicated.Add(node.Name)
+		}
+		indexed[node.Name] = node
+	}
+	current := mapset.NewSet[string]()
+	for _, obj := range objs {
+		current.Add(obj.GetName())
+	}
+	for _, node := range nodes {
+		nodePath := path.Join(dir, node.Name)
+		if current.Contains(node.Name) || op.HasStorage(nodePath) {
+			continue
+		}
+		log.Debugf("delete index: %s", nodePath)
+		if err = deleteNode(ctx, nodePath, node.IsDir); err != nil {
+			return err
+		}
+	}
+	var toAdd []ObjWithParent
+	for _, obj := range objs {
+		node, ok := indexed[obj.GetName()]
+		if ok && !duplicated.Contains(node.Name) && !nodeChanged(node, obj) {
+			continue
+		}
+		if ok {
+			if err = deleteNode(ctx, path.Join(dir, node.Name), node.IsDir); err != nil {
+				return err
+			}
+		}
+		toAdd = append(toAdd, ObjWithParent{Parent: dir, Obj: obj})
+	}
+	return BatchIndex(ctx, toAdd)
+}
+
+func deleteNode(ctx context.Context, nodePath string, isDir bool) error {
+	if err := instance.Del(ctx, nodePath); err != nil {
+		return err
+	}
+	if isDir {
+		return op.DeleteDirFingerprints(nodePath)
+	}
+	return nil
+}
+
+// nodeChanged compare the indexed node with the obj, the dirs are only compared by type,
+// as the changes under them are handled by their own fingerprints
+func nodeChanged(node model.SearchNode, obj model.Obj) bool {
+	if node.IsDir != obj.IsDir() {
+		return true
+	}
+	if node.IsDir {
+		return false
+	}
+	if node.Size != obj.GetSize() {
+		return true
+	}
+	// the nodes indexed by the old versions don't have the modified time
+	return !node.Modified.IsZero() && node.Modified.Unix() != obj.ModTime().Unix()
+}
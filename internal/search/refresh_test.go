@@ -0,0 +1,89 @@
+package search_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/search"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestRefreshNestedChange(t *testing.T) {
+	if err := op.CreateRole(&model.Role{ID: uint(model.ADMIN), Name: "admin"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := op.CreateUser(&model.User{Username: "admin", Password: "admin", Role: model.Roles{int(model.ADMIN)}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := search.Init("database"); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	writeFile := func(name string) {
+		if err := os.WriteFile(filepath.Join(nested, name), []byte(name), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("f1")
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{
+		Driver:    "Local",
+		MountPath: "/refresh",
+		Addition:  `{"root_folder_path":"` + root + `"}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	if err = search.Refresh(ctx, []string{"/refresh"}); err != nil {
+		t.Fatalf("failed to refresh: %+v", err)
+	}
+
+	// a new file in /a/b changes the modified time of b, but not the one of a
+	aInfo, err := os.Stat(filepath.Join(root, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile("f2")
+	if err = os.Chtimes(nested, time.Now(), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chtimes(filepath.Join(root, "a"), aInfo.ModTime(), aInfo.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if err = search.Refresh(ctx, []string{"/refresh"}); err != nil {
+		t.Fatalf("failed to refresh: %+v", err)
+	}
+	nodes, _, err := search.Search(ctx, model.SearchReq{
+		Parent:   "/refresh",
+		Keywords: "f2",
+		PageReq:  model.PageReq{Page: 1, PerPage: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0].Parent != "/refresh/a/b" {
+		t.Errorf("expected f2 under the unchanged dir to be indexed, got %+v", nodes)
+	}
+}
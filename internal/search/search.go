@@ -3,12 +3,14 @@ package search
 import (
 	"context"
 	"fmt"
+	"path"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -43,10 +45,44 @@ func Init(mode string) error {
 		log.Errorf("init searcher error: %+v", err)
 	} else {
 		instance = i
+		CheckVersion()
 	}
 	return err
 }
 
+// CheckVersion clear the index built by another searcher or with another layout,
+// the new fields are missing in it, so it has to be rebuilt
+func CheckVersion() {
+	if instance == nil {
+		return
+	}
+	cfg := instance.Config()
+	if cfg.Version == 0 {
+		return
+	}
+	progress, err := Progress()
+	if err != nil {
+		log.Errorf("get index progress error: %+v", err)
+		return
+	}
+	if progress.Searcher == cfg.Name && progress.Version == cfg.Version {
+		return
+	}
+	log.Warnf("the %s index is outdated, clearing it", cfg.Name)
+	if err = instance.Clear(context.Background()); err != nil {
+		log.Errorf("clear outdated index error: %+v", err)
+		return
+	}
+	eMsg := ""
+	if progress.ObjCount > 0 || progress.LastDoneTime != nil {
+		eMsg = "the index is outdated and has been cleared, please rebuild it"
+	}
+	WriteProgress(&model.IndexProgress{
+		IsDone: true,
+		Error:  eMsg,
+	})
+}
+
 func Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	return instance.Search(ctx, req)
 }
@@ -55,12 +91,28 @@ func Index(ctx context.Context, parent string, obj model.Obj) error {
 	if instance == nil {
 		return errs.SearchNotAvailable
 	}
-	return instance.Index(ctx, model.SearchNode{
-		Parent: parent,
-		Name:   obj.GetName(),
-		IsDir:  obj.IsDir(),
-		Size:   obj.GetSize(),
-	})
+	return instance.Index(ctx, newSearchNode(ctx, parent, obj))
+}
+
+func newSearchNode(ctx context.Context, parent string, obj model.Obj) model.SearchNode {
+	node := model.SearchNode{
+		Parent:   parent,
+		Name:     obj.GetName(),
+		IsDir:    obj.IsDir(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		ObjType:  utils.GetObjType(obj.GetName(), obj.IsDir()),
+	}
+	// the names like "a.very-long-suffix" are not taken as extensions
+	if ext := utils.Ext(obj.GetName()); !obj.IsDir() && len(ext) <= 32 {
+		node.Ext = ext
+	}
+	if storage, actualPath, err := op.GetStorageAndActualPath(path.Join(parent, obj.GetName())); err == nil {
+		node.Storage = storage.GetStorage().MountPath
+		fillContent(ctx, &node, storage, actualPath, obj)
+		fillMediaInfo(ctx, &node, storage, actualPath, obj)
+	}
+	return node
 }
 
 type ObjWithParent struct {
@@ -77,12 +129,7 @@ func BatchIndex(ctx context.Context, objs []ObjWithParent) error {
 	}
 	var searchNodes []model.SearchNode
 	for i := range objs {
-		searchNodes = append(searchNodes, model.SearchNode{
-			Parent: objs[i].Parent,
-			Name:   objs[i].GetName(),
-			IsDir:  objs[i].IsDir(),
-			Size:   objs[i].GetSize(),
-		})
+		searchNodes = append(searchNodes, newSearchNode(ctx, objs[i].Parent, objs[i].Obj))
 	}
 	return instance.BatchIndex(ctx, searchNodes)
 }
@@ -9,6 +9,11 @@ import (
 type Config struct {
 	Name       string
 	AutoUpdate bool
+	// whether the content of documents can be indexed and searched
+	Content bool
+	// Version of the layout of the index, bump it when the mappings or the fields change,
+	// the index built with another version is cleared on init and has to be rebuilt
+	Version int
 }
 
 type Searcher interface {
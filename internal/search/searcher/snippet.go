@@ -0,0 +1,85 @@
+package searcher
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+// the bytes of content kept before the first match and in the whole snippet
+const (
+	snippetContext = 60
+	snippetLength  = 240
+)
+
+// Match is the byte range [Start, End) of a matched term in the content
+type Match struct {
+	Start int
+	End   int
+}
+
+// NewSnippet cut the fragment around the first match from the content,
+// and convert the byte offsets of the matches to the character offsets in the fragment
+func NewSnippet(content string, matches []Match) *model.SearchSnippet {
+	matches = validMatches(content, matches)
+	if len(matches) == 0 {
+		return nil
+	}
+	start := alignRune(content, max(matches[0].Start-snippetContext, 0))
+	// don't begin the snippet in the middle of a word
+	if i := strings.IndexAny(content[start:matches[0].Start], " \t\r\n"); start > 0 && i != -1 {
+		start += i + 1
+	}
+	end := alignRune(content, min(start+snippetLength, len(content)))
+	if end < matches[0].End {
+		end = matches[0].End
+	}
+	snippet := &model.SearchSnippet{Highlights: []model.SnippetHighlight{}}
+	// the line breaks are replaced with spaces, which keeps the offsets unchanged
+	text := []byte(content[start:end])
+	for i, c := range text {
+		if c == '\n' || c == '\r' || c == '\t' {
+			text[i] = ' '
+		}
+	}
+	snippet.Text = string(text)
+	for _, m := range matches {
+		if m.Start < start || m.End > end {
+			continue
+		}
+		snippet.Highlights = append(snippet.Highlights, model.SnippetHighlight{
+			Start: utf8.RuneCountInString(content[start:m.Start]),
+			End:   utf8.RuneCountInString(content[start:m.End]),
+		})
+	}
+	return snippet
+}
+
+// validMatches sort the matches and drop the ones out of the content or overlapped
+func validMatches(content string, matches []Match) []Match {
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+	res := make([]Match, 0, len(matches))
+	for _, m := range matches {
+		if m.Start < 0 || m.End > len(content) || m.Start >= m.End {
+			continue
+		}
+		m.Start, m.End = alignRune(content, m.Start), alignRune(content, m.End)
+		if len(res) > 0 && m.Start < res[len(res)-1].End {
+			continue
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
+// alignRune move the offset back to the start of a character
+func alignRune(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
@@ -22,6 +22,10 @@ func Progress() (*model.IndexProgress, error) {
 }
 
 func WriteProgress(progress *model.IndexProgress) {
+	if instance != nil && instance.Config().Version != 0 {
+		progress.Searcher = instance.Config().Name
+		progress.Version = instance.Config().Version
+	}
 	p, err := utils.Json.MarshalToString(progress)
 	if err != nil {
 		log.Errorf("marshal progress error: %+v", err)
@@ -73,6 +77,9 @@ func updateIgnorePaths(customIgnorePaths string) {
 }
 
 func isIgnorePath(path string) bool {
+	if op.IsHiddenMountPath(path) {
+		return true
+	}
 	for _, ignorePath := range conf.SlicesMap[conf.IgnorePaths] {
 		if strings.HasPrefix(path, ignorePath) {
 			return true
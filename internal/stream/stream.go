@@ -24,7 +24,9 @@ type FileStream struct {
 	Mimetype          string
 	WebPutAsTask      bool
 	ForceStreamUpload bool
-	Exist             model.Obj //the file existed in the destination, we can reuse some info since we wil overwrite it
+	// the hashes of Obj are taken from the src storage, rather than given by the client
+	TrustedHash bool
+	Exist       model.Obj //the file existed in the destination, we can reuse some info since we wil overwrite it
 	utils.Closers
 	tmpFile  *os.File //if present, tmpFile has full content, it will be deleted at last
 	peekBuff *bytes.Reader
@@ -52,6 +54,10 @@ func (f *FileStream) IsForceStreamUpload() bool {
 	return f.ForceStreamUpload
 }
 
+func (f *FileStream) IsTrustedHash() bool {
+	return f.TrustedHash
+}
+
 func (f *FileStream) Close() error {
 	var err1, err2 error
 
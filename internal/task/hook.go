@@ -0,0 +1,22 @@
+package task
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+// HandleEventHook notify the hooks that the task succeeded or failed
+func HandleEventHook(t TaskExtensionInfo, succeeded bool) {
+	data := model.TaskEventData{ID: t.GetID(), Name: t.GetName()}
+	if creator := t.GetCreator(); creator != nil {
+		data.User = creator.Username
+	}
+	if succeeded {
+		op.HandleEventHook(model.EventTaskSucceeded, data)
+		return
+	}
+	if err := t.GetErr(); err != nil {
+		data.Error = err.Error()
+	}
+	op.HandleEventHook(model.EventTaskFailed, data)
+}
@@ -0,0 +1,145 @@
+package thumbnail
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/setting"
+	log "github.com/sirupsen/logrus"
+)
+
+const tempPrefix = "tmp-"
+
+// diskCache keep the thumbnails in the thumbnail dir, and remove the least recently used ones once
+// the total size exceeds the limit. The order is restored from the modified time of the files,
+// which is updated on each hit, so that it survives restarts
+type diskCache struct {
+	mu     sync.Mutex
+	loaded bool
+	ll     *list.List
+	items  map[string]*list.Element
+	size   int64
+}
+
+type cacheItem struct {
+	name string
+	size int64
+}
+
+var cache = &diskCache{}
+
+func (c *diskCache) path(name string) string {
+	return filepath.Join(conf.Conf.ThumbnailDir, name)
+}
+
+// load scan the thumbnail dir on the first access
+func (c *diskCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.ll = list.New()
+	c.items = make(map[string]*list.Element)
+	entries, err := os.ReadDir(conf.Conf.ThumbnailDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("failed read thumbnail dir: %+v", err)
+		}
+		return
+	}
+	type file struct {
+		cacheItem
+		modified time.Time
+	}
+	var files []file
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if strings.HasPrefix(e.Name(), tempPrefix) {
+			// left by an interrupted generation
+			_ = os.Remove(c.path(e.Name()))
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{cacheItem: cacheItem{name: e.Name(), size: info.Size()}, modified: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modified.Before(files[j].modified) })
+	for _, f := range files {
+		c.items[f.name] = c.ll.PushFront(&f.cacheItem)
+		c.size += f.size
+	}
+}
+
+func (c *diskCache) get(name string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	e, ok := c.items[name]
+	if !ok {
+		return "", false
+	}
+	c.ll.MoveToFront(e)
+	now := time.Now()
+	_ = os.Chtimes(c.path(name), now, now)
+	return c.path(name), true
+}
+
+func (c *diskCache) put(name string, data []byte) (string, error) {
+	if err := os.MkdirAll(conf.Conf.ThumbnailDir, 0o777); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(conf.Conf.ThumbnailDir, tempPrefix+"*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return "", err
+	}
+	if err = os.Rename(f.Name(), c.path(name)); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	if e, ok := c.items[name]; ok {
+		c.size -= e.Value.(*cacheItem).size
+		c.ll.Remove(e)
+	}
+	c.items[name] = c.ll.PushFront(&cacheItem{name: name, size: int64(len(data))})
+	c.size += int64(len(data))
+	c.evict()
+	return c.path(name), nil
+}
+
+// evict remove the least recently used thumbnails except the newest one, 0 means no limit
+func (c *diskCache) evict() {
+	limit := int64(setting.GetInt(conf.ThumbnailCacheSize, 512)) * 1024 * 1024
+	if limit <= 0 {
+		return
+	}
+	for c.size > limit && c.ll.Len() > 1 {
+		e := c.ll.Back()
+		item := e.Value.(*cacheItem)
+		if err := os.Remove(c.path(item.name)); err != nil && !os.IsNotExist(err) {
+			log.Warnf("failed remove thumbnail %s: %+v", item.name, err)
+		}
+		c.ll.Remove(e)
+		delete(c.items, item.name)
+		c.size -= item.size
+	}
+}
@@ -0,0 +1,131 @@
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/disintegration/imaging"
+	"github.com/pkg/errors"
+	_ "golang.org/x/image/webp"
+)
+
+// the formats which can be decoded in pure go
+var extensions = []string{"jpg", "jpeg", "png", "gif", "webp", "bmp", "tif", "tiff"}
+
+// the images with more pixels are not decoded, a small file of a huge image would take gigabytes of memory
+const maxPixels = 100 * 1000 * 1000
+
+var thumbG singleflight.Group[string]
+
+// Supported check whether the thumbnail of the file can be generated
+func Supported(name string, size int64) bool {
+	if !setting.GetBool(conf.ThumbnailEnabled) {
+		return false
+	}
+	if size > int64(setting.GetInt(conf.ThumbnailMaxFileSize, 20))*1024*1024 {
+		return false
+	}
+	return utils.SliceContains(extensions, utils.Ext(name))
+}
+
+// Get return the local file of the thumbnail of the path, which is generated on the first access and cached
+func Get(ctx context.Context, path string) (string, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get storage")
+	}
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get file")
+	}
+	if obj.IsDir() || !Supported(obj.GetName(), obj.GetSize()) {
+		return "", errors.WithStack(errs.NotSupport)
+	}
+	size := setting.GetInt(conf.ThumbnailSize, 256)
+	name := cacheName(path, obj, size)
+	if file, ok := cache.get(name); ok {
+		return file, nil
+	}
+	file, err, _ := thumbG.Do(name, func() (string, error) {
+		link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{
+			Header: http.Header{},
+		})
+		if err != nil {
+			return "", errors.WithMessage(err, "failed get link")
+		}
+		ss, err := stream.NewSeekableStream(stream.FileStream{Obj: obj, Ctx: ctx}, link)
+		if err != nil {
+			return "", errors.WithMessage(err, "failed get stream")
+		}
+		defer ss.Close()
+		// the file is read into memory, which is limited by the max file size,
+		// so that the dimensions can be checked before decoding
+		raw, err := io.ReadAll(io.LimitReader(ss, obj.GetSize()))
+		if err != nil {
+			return "", errors.WithMessage(err, "failed read image")
+		}
+		data, err := generate(raw, size, isJpeg(obj.GetName()))
+		if err != nil {
+			return "", err
+		}
+		return cache.put(name, data)
+	})
+	return file, err
+}
+
+// cacheName identify the thumbnail by the path, modified time and size of the image,
+// so that it is regenerated once the image changes
+func cacheName(path string, obj model.Obj, size int) string {
+	sum := md5.Sum([]byte(fmt.Sprintf("%s\n%d\n%d\n%d", path, obj.ModTime().UnixNano(), obj.GetSize(), size)))
+	ext := ".png"
+	if isJpeg(obj.GetName()) {
+		ext = ".jpg"
+	}
+	return hex.EncodeToString(sum[:]) + ext
+}
+
+func isJpeg(name string) bool {
+	ext := utils.Ext(name)
+	return ext == "jpg" || ext == "jpeg"
+}
+
+// generate scale the image down to fit the size, the orientation in exif is applied,
+// jpeg is kept as jpeg and the others are encoded as png to keep the transparency
+func generate(raw []byte, size int, jpeg bool) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed decode image config")
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, errors.WithMessagef(errs.NotSupport, "the image of %dx%d is too large", cfg.Width, cfg.Height)
+	}
+	img, err := imaging.Decode(bytes.NewReader(raw), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed decode image")
+	}
+	img = imaging.Fit(img, size, size, imaging.Lanczos)
+	var buf bytes.Buffer
+	if jpeg {
+		err = imaging.Encode(&buf, img, imaging.JPEG, imaging.JPEGQuality(85))
+	} else {
+		err = imaging.Encode(&buf, img, imaging.PNG)
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed encode thumbnail")
+	}
+	return buf.Bytes(), nil
+}
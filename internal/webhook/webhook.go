@@ -0,0 +1,263 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxBackoff      = time.Minute
+	maxResponseSize = 4 * utils.KB
+	// the delivery isn't retried by the retrier within the lease, while it's being posted
+	deliveryLease = time.Minute
+)
+
+var (
+	client = &http.Client{Timeout: 15 * time.Second}
+
+	mu       sync.RWMutex
+	webhooks []model.Webhook
+	loaded   bool
+
+	pruner  *cron.Cron
+	retrier *cron.Cron
+)
+
+// Init register the event hook, retry the failed deliveries and prune the expired delivery logs every day.
+// The retries are kept in the database, so the deliveries are at least once, even if alist restarts;
+// the receivers should drop the duplicate ones by the X-Alist-Delivery header.
+func Init() {
+	op.RegisterEventHook(Emit)
+	pruner = cron.NewCron(24 * time.Hour)
+	pruner.Do(func() {
+		if err := PruneDeliveries(); err != nil {
+			log.Errorf("failed prune webhook deliveries: %+v", err)
+		}
+	})
+	retrier = cron.NewCron(10 * time.Second)
+	retrier.Do(retryDeliveries)
+}
+
+func getWebhooks() []model.Webhook {
+	mu.RLock()
+	if loaded {
+		defer mu.RUnlock()
+		return webhooks
+	}
+	mu.RUnlock()
+	mu.Lock()
+	defer mu.Unlock()
+	hooks, err := db.GetEnabledWebhooks()
+	if err != nil {
+		log.Errorf("failed load webhooks: %+v", err)
+		return nil
+	}
+	webhooks, loaded = hooks, true
+	return webhooks
+}
+
+// invalidate drop the cached webhooks, they will be loaded on the next event
+func invalidate() {
+	mu.Lock()
+	defer mu.Unlock()
+	webhooks, loaded = nil, false
+}
+
+// Emit send the event to all the webhooks subscribing it, it doesn't block the caller
+func Emit(event *model.Event) {
+	go func() {
+		for _, w := range getWebhooks() {
+			if !w.Subscribed(event.Type) {
+				continue
+			}
+			if _, err := send(w, event); err != nil {
+				log.Errorf("failed send %s event to webhook [%s]: %+v", event.Type, w.Name, err)
+			}
+		}
+	}()
+}
+
+func send(w model.Webhook, event *model.Event) (*model.WebhookDelivery, error) {
+	payload, err := utils.Json.MarshalToString(event)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return sendPayload(w, event.Type, payload)
+}
+
+func sendPayload(w model.Webhook, event, payload string) (*model.WebhookDelivery, error) {
+	d := &model.WebhookDelivery{
+		WebhookID: w.ID,
+		Event:     event,
+		Payload:   payload,
+		Created:   time.Now(),
+	}
+	d.Updated = d.Created
+	lease := d.Created.Add(deliveryLease)
+	d.NextRetry = &lease
+	if err := db.CreateWebhookDelivery(d); err != nil {
+		return nil, err
+	}
+	go deliver(w, d)
+	return d, nil
+}
+
+// deliver post the payload to the webhook once, the retry is scheduled with exponential backoff on failure
+func deliver(w model.Webhook, d *model.WebhookDelivery) {
+	d.Attempts++
+	d.StatusCode, d.Response, d.Error = post(w, d)
+	d.Success = d.Error == "" && d.StatusCode >= 200 && d.StatusCode < 300
+	d.Updated = time.Now()
+	d.NextRetry = nil
+	if !d.Success && d.Attempts <= setting.GetInt(conf.WebhookMaxRetries, 3) {
+		backoff := time.Second << d.Attempts
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+		next := d.Updated.Add(backoff)
+		d.NextRetry = &next
+	}
+	if err := db.UpdateWebhookDelivery(d); err != nil {
+		log.Errorf("failed update webhook delivery: %+v", err)
+	}
+}
+
+// retryDeliveries deliver the failed deliveries whose retry time is up
+func retryDeliveries() {
+	now := time.Now()
+	deliveries, err := db.GetWebhookDeliveriesToRetry(now)
+	if err != nil {
+		log.Errorf("failed get webhook deliveries to retry: %+v", err)
+		return
+	}
+	for i := range deliveries {
+		d := &deliveries[i]
+		w, err := db.GetWebhookById(d.WebhookID)
+		if err != nil || w.Disabled {
+			// the webhook is deleted or disabled, give up the delivery
+			d.NextRetry = nil
+		} else {
+			lease := now.Add(deliveryLease)
+			d.NextRetry = &lease
+		}
+		if err := db.UpdateWebhookDelivery(d); err != nil {
+			log.Errorf("failed update webhook delivery: %+v", err)
+			continue
+		}
+		if d.NextRetry != nil {
+			go deliver(*w, d)
+		}
+	}
+}
+
+func post(w model.Webhook, d *model.WebhookDelivery) (int, string, string) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewBufferString(d.Payload))
+	if err != nil {
+		return 0, "", err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AList-Webhook")
+	req.Header.Set("X-Alist-Event", d.Event)
+	req.Header.Set("X-Alist-Delivery", strconv.FormatUint(uint64(d.ID), 10))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("X-Alist-Timestamp", timestamp)
+	if w.Secret != "" {
+		req.Header.Set("X-Alist-Signature", Sign(w.Secret, timestamp, []byte(d.Payload)))
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, "", err.Error()
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+	return res.StatusCode, string(body), ""
+}
+
+// Sign the timestamp and the payload, joined by ".", with HMAC-SHA256.
+// The receiver should compute it with the same secret and the X-Alist-Timestamp header,
+// compare it with the X-Alist-Signature header, and reject the old timestamps to prevent the replays
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func PruneDeliveries() error {
+	days := setting.GetInt(conf.WebhookRetentionDays, 7)
+	if days <= 0 {
+		return nil
+	}
+	return db.DeleteWebhookDeliveriesBefore(time.Now().AddDate(0, 0, -days))
+}
+
+func GetWebhooks(pageIndex, pageSize int) ([]model.Webhook, int64, error) {
+	return db.GetWebhooks(pageIndex, pageSize)
+}
+
+func GetWebhook(id uint) (*model.Webhook, error) {
+	return db.GetWebhookById(id)
+}
+
+func CreateWebhook(w *model.Webhook) error {
+	defer invalidate()
+	return db.CreateWebhook(w)
+}
+
+func UpdateWebhook(w *model.Webhook) error {
+	old, err := db.GetWebhookById(w.ID)
+	if err != nil {
+		return err
+	}
+	w.Created = old.Created
+	defer invalidate()
+	return db.UpdateWebhook(w)
+}
+
+func DeleteWebhook(id uint) error {
+	defer invalidate()
+	return db.DeleteWebhookById(id)
+}
+
+func GetDeliveries(webhookId uint, pageIndex, pageSize int) ([]model.WebhookDelivery, int64, error) {
+	return db.GetWebhookDeliveries(webhookId, pageIndex, pageSize)
+}
+
+// Ping send a ping event to the webhook, no matter if it's disabled
+func Ping(id uint) (*model.WebhookDelivery, error) {
+	w, err := db.GetWebhookById(id)
+	if err != nil {
+		return nil, err
+	}
+	return send(*w, &model.Event{Type: model.EventPing, Time: time.Now(), Data: w.Name})
+}
+
+// Redeliver send the payload of the delivery again as a new delivery
+func Redeliver(id uint) (*model.WebhookDelivery, error) {
+	old, err := db.GetWebhookDeliveryById(id)
+	if err != nil {
+		return nil, err
+	}
+	w, err := db.GetWebhookById(old.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+	return sendPayload(*w, old.Event, old.Payload)
+}
@@ -144,6 +144,7 @@ func (d *FtpMainDriver) AuthUser(cc ftpserver.ClientContext, user, pass string)
 		ctx = context.WithValue(ctx, "meta_pass", "")
 	}
 	ctx = context.WithValue(ctx, "client_ip", cc.RemoteAddr().String())
+	ctx = context.WithValue(ctx, conf.ProtocolKey, "ftp")
 	ctx = context.WithValue(ctx, "proxy_header", d.proxyHeader)
 	return ftp.NewAferoAdapter(ctx), nil
 }
@@ -40,6 +40,7 @@ func OpenDownload(ctx context.Context, reqPath string, offset int64) (*FileDownl
 		IP:     ctx.Value("client_ip").(string),
 		Header: header,
 	})
+	fs.AuditDownload(ctx, reqPath, err)
 	if err != nil {
 		return nil, err
 	}
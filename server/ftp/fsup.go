@@ -44,11 +44,23 @@ func uploadAuth(ctx context.Context, path string) error {
 	return nil
 }
 
+// uploadError reply 552 to the client if the quota is exceeded
+func uploadError(err error) error {
+	if errors.Is(err, errs.QuotaExceeded) {
+		return errors.WithMessage(ftpserver.ErrStorageExceeded, err.Error())
+	}
+	return err
+}
+
 func OpenUpload(ctx context.Context, path string, trunc bool) (*FileUploadProxy, error) {
 	err := uploadAuth(ctx, path)
 	if err != nil {
 		return nil, err
 	}
+	// the size is unknown until the upload finished, reject it early if the quota is already used up
+	if err = fs.CheckQuota(ctx, path, 0); err != nil {
+		return nil, uploadError(err)
+	}
 	tmpFile, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
 	if err != nil {
 		return nil, err
@@ -104,7 +116,7 @@ func (f *FileUploadProxy) Close() error {
 	}
 	s.SetTmpFile(f.buffer)
 	_, err = fs.PutAsTask(f.ctx, dir, s)
-	return err
+	return uploadError(err)
 }
 
 type FileUploadWithLengthProxy struct {
@@ -123,6 +135,9 @@ func OpenUploadWithLength(ctx context.Context, path string, trunc bool, length i
 	if err != nil {
 		return nil, err
 	}
+	if err = fs.CheckQuota(ctx, path, length); err != nil {
+		return nil, uploadError(err)
+	}
 	if trunc {
 		_ = fs.Remove(ctx, path)
 	}
@@ -200,7 +215,7 @@ func (f *FileUploadWithLengthProxy) Close() error {
 			return err
 		}
 		err = <-f.errChan
-		return err
+		return uploadError(err)
 	} else {
 		data := f.first512Bytes[:f.pFirst]
 		contentType := http.DetectContentType(data)
@@ -215,6 +230,6 @@ func (f *FileUploadWithLengthProxy) Close() error {
 			WebPutAsTask: false,
 			Reader:       bytes.NewReader(data),
 		}
-		return fs.PutDirectly(f.ctx, dir, s, true)
+		return uploadError(fs.PutDirectly(f.ctx, dir, s, true))
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/task"
 	"net/url"
 	stdpath "path"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/archive/tool"
 	"github.com/alist-org/alist/v3/internal/conf"
@@ -313,6 +314,110 @@ func FsArchiveDecompress(c *gin.Context) {
 	})
 }
 
+type ArchiveCompressReq struct {
+	SrcDir      string        `json:"src_dir" form:"src_dir"`
+	Name        StringOrArray `json:"name" form:"name"`
+	DstDir      string        `json:"dst_dir" form:"dst_dir"`
+	ArchiveName string        `json:"archive_name" form:"archive_name"`
+	// the format is taken from the suffix of the archive name if empty
+	Format   string `json:"format" form:"format"`
+	Password string `json:"password" form:"password"`
+}
+
+// validArchiveName check the archive name is a single name with something before its suffix
+func validArchiveName(name string) bool {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return false
+	}
+	return strings.TrimSuffix(name, stdpath.Ext(name)) != ""
+}
+
+func FsArchiveCompress(c *gin.Context) {
+	var req ArchiveCompressReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.ArchiveName = strings.TrimSpace(req.ArchiveName)
+	if len(req.Name) == 0 || !validArchiveName(req.ArchiveName) {
+		common.ErrorStrResp(c, "invalid names or archive name", 400)
+		return
+	}
+	if req.Format == "" {
+		req.Format = archiveFormat(req.ArchiveName)
+	}
+	user := c.MustGet("user").(*model.User)
+	dstDir, err := user.JoinPath(req.DstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !common.CheckPathLimitWithRoles(user, dstDir) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	if !common.HasPermission(common.MergeRolePermissions(user, dstDir), common.PermWrite) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	// the objs are walked here as the zip download does, so that the task only packs the accessible ones
+	z := &zipWalker{ctx: c, user: user}
+	for _, name := range req.Name {
+		if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+			common.ErrorStrResp(c, fmt.Sprintf("invalid name [%s]", name), 400)
+			return
+		}
+		srcPath, err := user.JoinPath(stdpath.Join(req.SrcDir, name))
+		if err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+		ok, err := z.walk(srcPath, name)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		if !ok {
+			common.ErrorStrResp(c, fmt.Sprintf("no permission to access [%s]", name), 403)
+			return
+		}
+	}
+	srcDir, err := user.JoinPath(req.SrcDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	names := make([]string, 0, len(z.entries))
+	for _, e := range z.entries {
+		names = append(names, strings.TrimSuffix(e.name, "/"))
+	}
+	t, err := fs.ArchiveCompress(c, srcDir, names, dstDir, req.ArchiveName, model.ArchiveCompressArgs{
+		Format:   strings.ToLower(req.Format),
+		Password: req.Password,
+	})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	tasks := make([]task.TaskExtensionInfo, 0, 1)
+	if t != nil {
+		tasks = append(tasks, t)
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfos(tasks),
+	})
+}
+
+// archiveFormat return the suffix of the archive name, including the double suffixes like .tar.gz
+func archiveFormat(name string) string {
+	name = strings.ToLower(name)
+	ext := stdpath.Ext(name)
+	if inner := stdpath.Ext(strings.TrimSuffix(name, ext)); inner == ".tar" {
+		return inner + ext
+	}
+	return ext
+}
+
 func ArchiveDown(c *gin.Context) {
 	archiveRawPath := c.MustGet("path").(string)
 	innerPath := utils.FixAndCleanPath(c.Query("inner"))
@@ -0,0 +1,126 @@
+package handles
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type AuditLogsReq struct {
+	model.PageReq
+	model.AuditLogQuery
+}
+
+func ListAuditLogs(c *gin.Context) {
+	var req AuditLogsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	logs, total, err := op.GetAuditLogs(req.AuditLogQuery, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: logs,
+		Total:   total,
+	})
+}
+
+type ExportAuditLogsReq struct {
+	model.AuditLogQuery
+	Format string `json:"format" form:"format"`
+}
+
+var auditLogCSVHeader = []string{"id", "time", "user_id", "username", "protocol", "action", "src_path", "dst_path", "ip", "success", "error"}
+
+// csvCell prefix the value with a quote if it starts like a formula,
+// so that it isn't run when the csv is opened by the spreadsheets
+func csvCell(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+// ExportAuditLogs write all the matched audit logs as a csv or json file
+func ExportAuditLogs(c *gin.Context) {
+	var req ExportAuditLogsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Format == "" {
+		req.Format = "csv"
+	}
+	if req.Format != "csv" && req.Format != "json" {
+		common.ErrorStrResp(c, "unsupported format: "+req.Format, 400)
+		return
+	}
+	filename := fmt.Sprintf("audit_logs_%s.%s", time.Now().Format("20060102150405"), req.Format)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	var err error
+	if req.Format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		w := csv.NewWriter(c.Writer)
+		_ = w.Write(auditLogCSVHeader)
+		err = op.WalkAuditLogs(req.AuditLogQuery, func(logs []model.AuditLog) error {
+			for _, l := range logs {
+				if err := w.Write([]string{
+					strconv.FormatUint(uint64(l.ID), 10),
+					l.Time.Format(time.RFC3339),
+					strconv.FormatUint(uint64(l.UserID), 10),
+					csvCell(l.Username),
+					l.Protocol,
+					l.Action,
+					csvCell(l.SrcPath),
+					csvCell(l.DstPath),
+					csvCell(l.IP),
+					strconv.FormatBool(l.Success),
+					csvCell(l.Error),
+				}); err != nil {
+					return err
+				}
+			}
+			w.Flush()
+			return w.Error()
+		})
+		w.Flush()
+	} else {
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		first := true
+		_, _ = c.Writer.WriteString("[")
+		err = op.WalkAuditLogs(req.AuditLogQuery, func(logs []model.AuditLog) error {
+			for _, l := range logs {
+				data, err := utils.Json.Marshal(l)
+				if err != nil {
+					return err
+				}
+				if !first {
+					_, _ = c.Writer.WriteString(",")
+				}
+				first = false
+				if _, err = c.Writer.Write(data); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		_, _ = c.Writer.WriteString("]")
+	}
+	if err != nil {
+		// the header has been sent, so the error can only be logged
+		log.Errorf("failed export audit logs: %+v", err)
+	}
+}
@@ -87,6 +87,12 @@ func loginHash(c *gin.Context, req *LoginReq) {
 	}
 	common.SuccessResp(c, gin.H{"token": token})
 	loginCache.Del(ip)
+	onLogin(c, user)
+}
+
+// onLogin notify the hooks that the user logged in
+func onLogin(c *gin.Context, user *model.User) {
+	op.HandleEventHook(model.EventLogin, model.LoginEventData{User: user.Username, IP: c.ClientIP()})
 }
 
 type UserResp struct {
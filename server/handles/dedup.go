@@ -0,0 +1,68 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type DedupReportReq struct {
+	model.PageReq
+	Path string `json:"path" form:"path"`
+}
+
+func DedupReport(c *gin.Context) {
+	var req DedupReportReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	if req.Path == "" {
+		req.Path = "/"
+	}
+	groups, err := fs.DedupReport(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	var wasted int64
+	for _, g := range groups {
+		wasted += g.Wasted
+	}
+	total := len(groups)
+	start := min((req.Page-1)*req.PerPage, total)
+	end := min(start+req.PerPage, total)
+	common.SuccessResp(c, gin.H{
+		"content": groups[start:end],
+		"total":   total,
+		"wasted":  wasted,
+	})
+}
+
+type DedupReq struct {
+	Path   string `json:"path"`
+	Action string `json:"action" binding:"required"`
+}
+
+// RunDedup index the hashes of the files, or remove or hard link the duplicate files by a dedup task
+func RunDedup(c *gin.Context) {
+	var req DedupReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Path == "" {
+		req.Path = "/"
+	}
+	t, err := fs.Dedup(c, req.Path, req.Action)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"tasks": getTaskInfos([]task.TaskExtensionInfo{t}),
+	})
+}
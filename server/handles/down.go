@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"net/http"
 	stdpath "path"
 	"strconv"
 	"strings"
@@ -41,6 +42,7 @@ func Down(c *gin.Context) {
 			HttpReq:  c.Request,
 			Redirect: true,
 		})
+		auditDownload(c, rawPath, err)
 		if err != nil {
 			common.ErrorResp(c, err, 500)
 			return
@@ -66,6 +68,7 @@ func Proxy(c *gin.Context) {
 					strings.Split(downProxyUrl, "\n")[0],
 					utils.EncodePath(rawPath, true),
 					sign.Sign(rawPath))
+				auditDownload(c, rawPath, nil)
 				c.Redirect(302, URL)
 				return
 			}
@@ -75,6 +78,7 @@ func Proxy(c *gin.Context) {
 			Type:    c.Query("type"),
 			HttpReq: c.Request,
 		})
+		auditDownload(c, rawPath, err)
 		if err != nil {
 			common.ErrorResp(c, err, 500)
 			return
@@ -86,6 +90,13 @@ func Proxy(c *gin.Context) {
 	}
 }
 
+// auditDownload record the download, the HEAD requests are not downloads
+func auditDownload(c *gin.Context, rawPath string, err error) {
+	if c.Request.Method != http.MethodHead {
+		fs.AuditDownload(c, rawPath, err)
+	}
+}
+
 func down(c *gin.Context, link *model.Link) {
 	var err error
 	if link.MFile != nil {
@@ -177,6 +177,72 @@ func FsCopy(c *gin.Context) {
 	})
 }
 
+type SyncReq struct {
+	SrcDir string `json:"src_dir"`
+	DstDir string `json:"dst_dir"`
+	Delete bool   `json:"delete"`
+	DryRun bool   `json:"dry_run"`
+}
+
+func FsSync(c *gin.Context) {
+	var req SyncReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	srcDir, err := user.JoinPath(req.SrcDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !common.CheckPathLimitWithRoles(user, srcDir) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	dstDir, err := user.JoinPath(req.DstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !common.CheckPathLimitWithRoles(user, dstDir) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	perm := common.MergeRolePermissions(user, srcDir)
+	if !common.HasPermission(perm, common.PermCopy) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	if req.Delete {
+		perm = common.MergeRolePermissions(user, dstDir)
+		if !common.HasPermission(perm, common.PermRemove) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	args := fs.SyncArgs{Delete: req.Delete}
+	if req.DryRun {
+		ops, err := fs.PlanSync(c, srcDir, dstDir, args)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		common.SuccessResp(c, gin.H{
+			"operations": ops,
+		})
+		return
+	}
+	t, err := fs.Sync(c, srcDir, dstDir, args)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"tasks": getTaskInfos([]task.TaskExtensionInfo{t}),
+	})
+}
+
 type RenameReq struct {
 	Path      string `json:"path"`
 	Name      string `json:"name"`
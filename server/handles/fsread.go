@@ -9,6 +9,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/media"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
@@ -17,6 +18,7 @@ import (
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 type ListReq struct {
@@ -113,8 +115,10 @@ func FsList(c *gin.Context) {
 	if err == nil {
 		provider = storage.GetStorage().Driver
 	}
+	content := toObjsResp(objs, reqPath, isEncrypt(meta, reqPath), user.ID)
+	fillThumbs(c, content, reqPath)
 	common.SuccessResp(c, FsListResp{
-		Content:  toObjsResp(objs, reqPath, isEncrypt(meta, reqPath), user.ID),
+		Content:  content,
 		Total:    int64(total),
 		Readme:   getReadme(meta, reqPath),
 		Header:   getHeader(meta, reqPath),
@@ -263,6 +267,8 @@ type FsGetResp struct {
 	Header   string         `json:"header"`
 	Provider string         `json:"provider"`
 	Related  []ObjLabelResp `json:"related"`
+	// only returned when media_info_enabled is on
+	MediaInfo *model.MediaInfo `json:"media_info,omitempty"`
 }
 
 func FsGet(c *gin.Context) {
@@ -365,14 +371,36 @@ func FsGet(c *gin.Context) {
 			Type:        utils.GetFileType(obj.GetName()),
 			Thumb:       thumb,
 		},
-		RawURL:   rawURL,
-		Readme:   getReadme(meta, reqPath),
-		Header:   getHeader(meta, reqPath),
-		Provider: provider,
-		Related:  toObjsResp(related, parentPath, isEncrypt(parentMeta, parentPath), user.ID),
+		RawURL:    rawURL,
+		Readme:    getReadme(meta, reqPath),
+		Header:    getHeader(meta, reqPath),
+		Provider:  provider,
+		Related:   toObjsResp(related, parentPath, isEncrypt(parentMeta, parentPath), user.ID),
+		MediaInfo: getMediaInfo(c, user, reqPath, obj),
 	})
 }
 
+// getMediaInfo read the media info of the file, the gps location is only shown
+// to the users who can write the file unless it is public by the setting
+func getMediaInfo(c *gin.Context, user *model.User, reqPath string, obj model.Obj) *model.MediaInfo {
+	if obj.IsDir() || !setting.GetBool(conf.MediaInfoEnabled) || !media.Supported(obj.GetName()) {
+		return nil
+	}
+	info, err := media.Get(c, reqPath)
+	if err != nil {
+		log.Warnf("failed get media info of %s: %+v", reqPath, err)
+		return nil
+	}
+	if (info.Latitude != nil || info.Longitude != nil) && !setting.GetBool(conf.MediaInfoPublicGPS) &&
+		!common.HasPermission(common.MergeRolePermissions(user, reqPath), common.PermWrite) {
+		// the info is cached, so it is copied before being changed
+		stripped := *info
+		stripped.Latitude, stripped.Longitude = nil, nil
+		info = &stripped
+	}
+	return info
+}
+
 func filterRelated(objs []model.Obj, obj model.Obj) []model.Obj {
 	var related []model.Obj
 	nameWithoutExt := strings.TrimSuffix(obj.GetName(), stdpath.Ext(obj.GetName()))
@@ -424,3 +452,51 @@ func FsOther(c *gin.Context) {
 	}
 	common.SuccessResp(c, res)
 }
+
+type FsDetailsReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+	Refresh  bool   `json:"refresh" form:"refresh"`
+}
+
+// FsDetails return the capacity of the storage the path belongs to,
+// data is null if the storage can't report it
+func FsDetails(c *gin.Context) {
+	var req FsDetailsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	if !common.CanAccessWithRoles(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	perm := common.MergeRolePermissions(user, reqPath)
+	if !common.HasPermission(perm, common.PermWrite) && !common.CanWrite(meta, reqPath) && req.Refresh {
+		common.ErrorStrResp(c, "Refresh without permission", 403)
+		return
+	}
+	details, err := fs.GetStorageDetails(c, reqPath, req.Refresh)
+	if err != nil {
+		if errors.Is(err, errs.NotImplement) || errors.Is(err, errs.NotSupport) {
+			common.SuccessResp(c, nil)
+			return
+		}
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, details)
+}
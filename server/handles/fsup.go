@@ -1,12 +1,15 @@
 package handles
 
 import (
+	"errors"
 	"io"
+	"net/http"
 	"net/url"
 	stdpath "path"
 	"strconv"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/stream"
@@ -27,6 +30,13 @@ func getLastModified(c *gin.Context) time.Time {
 	return lastModified
 }
 
+func putErrorCode(err error) int {
+	if errors.Is(err, errs.QuotaExceeded) {
+		return http.StatusInsufficientStorage
+	}
+	return 500
+}
+
 func FsStream(c *gin.Context) {
 	path := c.GetHeader("File-Path")
 	path, err := url.PathUnescape(path)
@@ -89,7 +99,7 @@ func FsStream(c *gin.Context) {
 	}
 	defer c.Request.Body.Close()
 	if err != nil {
-		common.ErrorResp(c, err, 500)
+		common.ErrorResp(c, err, putErrorCode(err))
 		return
 	}
 	if t == nil {
@@ -182,7 +192,7 @@ func FsForm(c *gin.Context) {
 		err = fs.PutDirectly(c, dir, &s, true)
 	}
 	if err != nil {
-		common.ErrorResp(c, err, 500)
+		common.ErrorResp(c, err, putErrorCode(err))
 		return
 	}
 	if t == nil {
@@ -0,0 +1,249 @@
+package handles
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	stdpath "path"
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// the tus resumable upload protocol, see https://tus.io/protocols/resumable-upload
+// the destination is given by the same headers as FsStream when creating the upload,
+// and the errors are returned by the status codes, which are checked by the tus clients
+
+const (
+	tusVersion     = "1.0.0"
+	tusExtensions  = "creation,creation-with-upload,termination,expiration"
+	tusContentType = "application/offset+octet-stream"
+)
+
+func tusError(c *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, errs.UploadSessionNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, errs.UploadOffsetMismatch):
+		code = http.StatusConflict
+	case errors.Is(err, errs.UploadSessionBusy):
+		code = http.StatusLocked
+	case errors.Is(err, errs.UploadNotSupported):
+		code = http.StatusMethodNotAllowed
+	case errors.Is(err, errs.QuotaExceeded):
+		code = http.StatusInsufficientStorage
+	}
+	c.String(code, err.Error())
+	c.Abort()
+}
+
+// checkTusResumable set the common headers, and check the version of the protocol used by the client
+func checkTusResumable(c *gin.Context) bool {
+	c.Header("Tus-Resumable", tusVersion)
+	if c.GetHeader("Tus-Resumable") != tusVersion {
+		c.Header("Tus-Version", tusVersion)
+		c.String(http.StatusPreconditionFailed, "unsupported tus version")
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+// parseTusMetadata parse the Upload-Metadata, which is the comma separated pairs of key and base64 encoded value
+func parseTusMetadata(header string) map[string]string {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		m[key] = string(decoded)
+	}
+	return m
+}
+
+func setTusExpires(c *gin.Context, s *model.UploadSession) {
+	if expires := fs.UploadSessionExpires(s); !expires.IsZero() {
+		c.Header("Upload-Expires", expires.UTC().Format(http.TimeFormat))
+	}
+}
+
+func getTusSession(c *gin.Context) *model.UploadSession {
+	s, err := fs.GetUploadSession(c.Param("id"))
+	if err != nil {
+		tusError(c, err)
+		return nil
+	}
+	// the sessions of the other users are hidden
+	if s.UserID != c.MustGet("user").(*model.User).ID {
+		tusError(c, errs.UploadSessionNotFound)
+		return nil
+	}
+	return s
+}
+
+// writeTusData write the body at the offset, and put the file once all the data are received
+func writeTusData(c *gin.Context, s *model.UploadSession, offset int64) bool {
+	defer c.Request.Body.Close()
+	err := fs.WriteUploadSession(s, offset, c.Request.Body)
+	c.Header("Upload-Offset", strconv.FormatInt(s.Offset, 10))
+	setTusExpires(c, s)
+	if err != nil {
+		tusError(c, err)
+		return false
+	}
+	if s.Offset < s.Size {
+		return true
+	}
+	t, err := fs.FinishUploadSession(c, s)
+	if err != nil {
+		tusError(c, err)
+		return false
+	}
+	if t != nil {
+		c.Header("Upload-Task-Id", t.GetID())
+	}
+	return true
+}
+
+func TusOptions(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", tusExtensions)
+	c.Status(http.StatusNoContent)
+}
+
+func TusCreate(c *gin.Context) {
+	if !checkTusResumable(c) {
+		return
+	}
+	path, err := url.PathUnescape(c.GetHeader("File-Path"))
+	if err != nil {
+		tusError(c, err)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	path, err = user.JoinPath(path)
+	if err != nil {
+		c.String(http.StatusForbidden, err.Error())
+		return
+	}
+	size, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		c.String(http.StatusBadRequest, "invalid Upload-Length")
+		return
+	}
+	if c.GetHeader("Overwrite") == "false" {
+		if res, _ := fs.Get(c, path, &fs.GetArgs{NoLog: true}); res != nil {
+			c.String(http.StatusConflict, "file exists")
+			return
+		}
+	}
+	h := make(map[*utils.HashType]string)
+	if md5 := c.GetHeader("X-File-Md5"); md5 != "" {
+		h[utils.MD5] = md5
+	}
+	if sha1 := c.GetHeader("X-File-Sha1"); sha1 != "" {
+		h[utils.SHA1] = sha1
+	}
+	if sha256 := c.GetHeader("X-File-Sha256"); sha256 != "" {
+		h[utils.SHA256] = sha256
+	}
+	metadata := c.GetHeader("Upload-Metadata")
+	mimetype := parseTusMetadata(metadata)["filetype"]
+	if mimetype == "" {
+		mimetype = utils.GetMimeType(stdpath.Base(path))
+	}
+	s := &model.UploadSession{
+		UserID:   user.ID,
+		Path:     path,
+		Size:     size,
+		Metadata: metadata,
+		Mimetype: mimetype,
+		Modified: getLastModified(c),
+		HashInfo: utils.NewHashInfoByMap(h).String(),
+		AsTask:   c.GetHeader("As-Task") == "true",
+	}
+	if err = fs.CreateUploadSession(c, s); err != nil {
+		_, _ = utils.CopyWithBuffer(io.Discard, c.Request.Body)
+		tusError(c, err)
+		return
+	}
+	c.Header("Location", common.GetApiUrl(c.Request)+"/api/fs/tus/"+s.ID)
+	// the empty file is put once it is created
+	if c.GetHeader("Content-Type") == tusContentType || size == 0 {
+		if !writeTusData(c, s, 0) {
+			return
+		}
+	}
+	setTusExpires(c, s)
+	c.Status(http.StatusCreated)
+}
+
+func TusHead(c *gin.Context) {
+	if !checkTusResumable(c) {
+		return
+	}
+	s := getTusSession(c)
+	if s == nil {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Offset", strconv.FormatInt(s.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(s.Size, 10))
+	if s.Metadata != "" {
+		c.Header("Upload-Metadata", s.Metadata)
+	}
+	setTusExpires(c, s)
+	c.Status(http.StatusOK)
+}
+
+func TusPatch(c *gin.Context) {
+	if !checkTusResumable(c) {
+		return
+	}
+	if c.GetHeader("Content-Type") != tusContentType {
+		c.String(http.StatusUnsupportedMediaType, "invalid Content-Type")
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.String(http.StatusBadRequest, "invalid Upload-Offset")
+		return
+	}
+	s := getTusSession(c)
+	if s == nil {
+		return
+	}
+	if writeTusData(c, s, offset) {
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func TusDelete(c *gin.Context) {
+	if !checkTusResumable(c) {
+		return
+	}
+	s := getTusSession(c)
+	if s == nil {
+		return
+	}
+	if err := fs.TerminateUploadSession(s); err != nil {
+		tusError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
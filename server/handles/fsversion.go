@@ -0,0 +1,99 @@
+package handles
+
+import (
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type FsVersionReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+	ID       uint   `json:"id" form:"id"`
+}
+
+// checkVersionReq bind the request and check if the user can access the file,
+// return the joined path of the file
+func checkVersionReq(c *gin.Context, req *FsVersionReq, write bool) (string, bool) {
+	if err := c.ShouldBind(req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return "", false
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return "", false
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return "", false
+		}
+	}
+	c.Set("meta", meta)
+	if !common.CanAccessWithRoles(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return "", false
+	}
+	if write {
+		perm := common.MergeRolePermissions(user, reqPath)
+		if !common.HasPermission(perm, common.PermWrite) && !common.CanWrite(meta, stdpath.Dir(reqPath)) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return "", false
+		}
+	}
+	return reqPath, true
+}
+
+func FsVersions(c *gin.Context) {
+	var req FsVersionReq
+	reqPath, ok := checkVersionReq(c, &req, false)
+	if !ok {
+		return
+	}
+	versions, err := fs.GetFileVersions(c, reqPath)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, versions)
+}
+
+// FsVersionDownload download the content of the version
+func FsVersionDownload(c *gin.Context) {
+	var req FsVersionReq
+	reqPath, ok := checkVersionReq(c, &req, false)
+	if !ok {
+		return
+	}
+	versionPath, err := fs.GetFileVersionPath(reqPath, req.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	c.Set("path", versionPath)
+	c.Set(conf.HiddenKey, struct{}{})
+	Down(c)
+}
+
+func FsVersionRestore(c *gin.Context) {
+	var req FsVersionReq
+	reqPath, ok := checkVersionReq(c, &req, true)
+	if !ok {
+		return
+	}
+	if err := fs.RestoreFileVersion(c, reqPath, req.ID); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
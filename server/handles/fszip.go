@@ -0,0 +1,290 @@
+package handles
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/url"
+	stdpath "path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type DownloadZipReq struct {
+	Path string `json:"path" form:"path"`
+	// the names of the objs under the path, all of the objs are downloaded if empty
+	Names    StringOrArray `json:"names" form:"names"`
+	Password string        `json:"password" form:"password"`
+}
+
+type zipEntry struct {
+	// the mount path of the obj
+	path string
+	// the slash separated path in the zip, with a "/" suffix for dirs
+	name string
+	obj  model.Obj
+}
+
+// FsDownloadZip stream the objs as a zip in store mode, so that the size is known before
+// sending, and no temp file is needed
+func FsDownloadZip(c *gin.Context) {
+	var req DownloadZipReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !common.CheckPathLimitWithRoles(user, reqPath) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	meta, err := getNearestMeta(reqPath)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !common.CanAccessWithRoles(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	z := &zipWalker{ctx: c, user: user, password: req.Password}
+	names := []string(req.Names)
+	if len(names) == 0 {
+		objs, err := z.list(reqPath, meta)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		for _, obj := range objs {
+			names = append(names, obj.GetName())
+		}
+	}
+	for _, name := range names {
+		if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+			common.ErrorStrResp(c, fmt.Sprintf("invalid name [%s]", name), 400)
+			return
+		}
+		p := stdpath.Join(reqPath, name)
+		ok, err := z.walk(p, name)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		// the inaccessible objs are skipped if the whole dir is downloaded
+		if !ok && len(req.Names) > 0 {
+			common.ErrorStrResp(c, fmt.Sprintf("no permission to access [%s]", name), 403)
+			return
+		}
+	}
+	size, err := zipSize(z.entries)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	filename := "download.zip"
+	if reqPath != "/" {
+		filename = stdpath.Base(reqPath) + ".zip"
+	}
+	if len(req.Names) == 1 {
+		filename = stdpath.Base(req.Names[0]) + ".zip"
+	}
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Length", fmt.Sprint(size))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, filename, url.PathEscape(filename)))
+	c.Status(http.StatusOK)
+	if c.Request.Method == http.MethodHead {
+		return
+	}
+	if err = writeZip(c.Writer, z.entries, z.write); err != nil {
+		// the response has been started, so the client can only find the error by the short length
+		log.Errorf("failed stream zip of %s: %+v", reqPath, err)
+		c.Abort()
+	}
+}
+
+type zipWalker struct {
+	ctx      *gin.Context
+	user     *model.User
+	password string
+	entries  []zipEntry
+}
+
+func getNearestMeta(p string) (*model.Meta, error) {
+	meta, err := op.GetNearestMeta(p)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// list the dir with its meta in the context, so that the hidden objs are filtered out for the user
+func (z *zipWalker) list(dir string, meta *model.Meta) ([]model.Obj, error) {
+	return fs.List(context.WithValue(z.ctx, "meta", meta), dir, &fs.ListArgs{})
+}
+
+// walk add the obj and the objs under it to the entries, it returns false if the user can't access the obj,
+// the inaccessible objs under it are skipped
+func (z *zipWalker) walk(p, name string) (bool, error) {
+	if !common.CheckPathLimitWithRoles(z.user, p) {
+		return false, nil
+	}
+	meta, err := getNearestMeta(p)
+	if err != nil {
+		return false, err
+	}
+	if !common.CanAccessWithRoles(z.user, meta, p, z.password) {
+		return false, nil
+	}
+	obj, err := fs.Get(z.ctx, p, &fs.GetArgs{})
+	if err != nil {
+		return false, err
+	}
+	if !obj.IsDir() {
+		z.entries = append(z.entries, zipEntry{path: p, name: name, obj: obj})
+		return true, nil
+	}
+	z.entries = append(z.entries, zipEntry{path: p, name: name + "/", obj: obj})
+	objs, err := z.list(p, meta)
+	if err != nil {
+		return false, err
+	}
+	for _, o := range objs {
+		if _, err = z.walk(stdpath.Join(p, o.GetName()), stdpath.Join(name, o.GetName())); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// write copy the content of the file to w, and return its crc
+func (z *zipWalker) write(e zipEntry, w io.Writer) (crc uint32, err error) {
+	defer func() {
+		fs.AuditDownload(z.ctx, e.path, err)
+	}()
+	link, _, err := fs.Link(z.ctx, e.path, model.LinkArgs{
+		IP:     z.ctx.ClientIP(),
+		Header: http.Header{},
+		Type:   z.ctx.Query("type"),
+	})
+	if err != nil {
+		return 0, err
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Obj: e.obj, Ctx: z.ctx}, link)
+	if err != nil {
+		return 0, err
+	}
+	defer ss.Close()
+	hash := crc32.NewIEEE()
+	n, err := io.Copy(io.MultiWriter(w, hash), io.LimitReader(ss, e.obj.GetSize()))
+	if err != nil {
+		return 0, err
+	}
+	if n != e.obj.GetSize() {
+		return 0, errors.Errorf("the size changed, expected %d, got %d", e.obj.GetSize(), n)
+	}
+	return hash.Sum32(), nil
+}
+
+// zipSize write the zip without the contents to get its size, the store mode makes the size
+// of the zip only depend on the headers and the sizes of the files
+func zipSize(entries []zipEntry) (int64, error) {
+	counter := &zipCounter{}
+	buf := make([]byte, 1024*1024)
+	err := writeZip(counter, entries, func(e zipEntry, w io.Writer) (uint32, error) {
+		// the counter doesn't care about the bytes
+		for left := e.obj.GetSize(); left > 0; {
+			n := min(left, int64(len(buf)))
+			if _, err := w.Write(buf[:n]); err != nil {
+				return 0, err
+			}
+			left -= n
+		}
+		return 0, nil
+	})
+	return counter.n, err
+}
+
+// writeZip write the entries by CreateRaw with the sizes known, the crc is computed while streaming
+// and written in the data descriptor after the contents
+func writeZip(w io.Writer, entries []zipEntry, write func(e zipEntry, w io.Writer) (uint32, error)) error {
+	zw := zip.NewWriter(w)
+	for _, e := range entries {
+		fh := &zip.FileHeader{
+			Name:   e.name,
+			Method: zip.Store,
+			Extra:  extendedTimestamp(e.obj.ModTime()),
+		}
+		fh.SetModTime(e.obj.ModTime())
+		if !isASCII(e.name) && utf8.ValidString(e.name) {
+			fh.Flags |= 0x800
+		}
+		if e.obj.IsDir() {
+			if _, err := zw.CreateRaw(fh); err != nil {
+				return err
+			}
+			continue
+		}
+		fh.Flags |= 0x8
+		fh.CompressedSize64 = uint64(e.obj.GetSize())
+		fh.UncompressedSize64 = uint64(e.obj.GetSize())
+		fw, err := zw.CreateRaw(fh)
+		if err != nil {
+			return err
+		}
+		crc, err := write(e, fw)
+		if err != nil {
+			return errors.WithMessagef(err, "failed write [%s]", e.path)
+		}
+		// the data descriptor is written with the crc of the header when the next entry is created
+		fh.CRC32 = crc
+	}
+	return zw.Close()
+}
+
+// extendedTimestamp is the extra field which Info-ZIP uses for the unix modified time
+func extendedTimestamp(t time.Time) []byte {
+	b := make([]byte, 9)
+	binary.LittleEndian.PutUint16(b, 0x5455)
+	binary.LittleEndian.PutUint16(b[2:], 5)
+	b[4] = 1
+	binary.LittleEndian.PutUint32(b[5:], uint32(t.Unix()))
+	return b
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+type zipCounter struct {
+	n int64
+}
+
+func (c *zipCounter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
@@ -71,6 +71,36 @@ func UpdateIndex(c *gin.Context) {
 	common.SuccessResp(c)
 }
 
+type RefreshIndexReq struct {
+	Paths []string `json:"paths"`
+}
+
+func RefreshIndex(c *gin.Context) {
+	var req RefreshIndexReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if search.Running() {
+		common.ErrorStrResp(c, "index is running", 400)
+		return
+	}
+	if !search.Config(c).AutoUpdate {
+		common.ErrorStrResp(c, "refresh is not supported for current index", 400)
+		return
+	}
+	if len(req.Paths) == 0 {
+		req.Paths = []string{"/"}
+	}
+	go func() {
+		err := search.Refresh(context.Background(), req.Paths)
+		if err != nil {
+			log.Errorf("refresh index error: %+v", err)
+		}
+	}()
+	common.SuccessResp(c)
+}
+
 func StopIndex(c *gin.Context) {
 	quit := search.Quit.Load()
 	if quit == nil {
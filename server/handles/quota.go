@@ -0,0 +1,26 @@
+package handles
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListQuotaUsages list the usage of the paths limited by the quotas of users and roles
+func ListQuotaUsages(c *gin.Context) {
+	usages, err := op.GetQuotaUsages()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, usages)
+}
+
+// ReconcileQuotaUsages walk all the quota paths to correct the usage in background
+func ReconcileQuotaUsages(c *gin.Context) {
+	go fs.ReconcileQuotaUsage(context.Background(), true)
+	common.SuccessResp(c)
+}
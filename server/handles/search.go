@@ -43,6 +43,12 @@ func Search(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
+	for i := range req.Exts {
+		req.Exts[i] = strings.ToLower(strings.TrimPrefix(req.Exts[i], "."))
+	}
+	for i := range req.Storages {
+		req.Storages[i] = utils.FixAndCleanPath(req.Storages[i])
+	}
 	nodes, total, err := search.Search(c, req.SearchReq)
 	if err != nil {
 		common.ErrorResp(c, err, 500)
@@ -53,6 +59,10 @@ func Search(c *gin.Context) {
 		if !strings.HasPrefix(node.Parent, user.BasePath) {
 			continue
 		}
+		// the trash and versions may be indexed before they are hidden
+		if op.IsHiddenMountPath(path.Join(node.Parent, node.Name)) {
+			continue
+		}
 		meta, err := op.GetNearestMeta(node.Parent)
 		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			continue
@@ -69,6 +79,8 @@ func Search(c *gin.Context) {
 }
 
 func nodeToSearchResp(node model.SearchNode) SearchResp {
+	// only the snippet of the content is returned
+	node.Content = ""
 	return SearchResp{
 		SearchNode: node,
 		Type:       utils.GetObjType(node.Name, node.IsDir),
@@ -0,0 +1,418 @@
+package handles
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type ShareReq struct {
+	ID           string     `json:"id"`
+	Path         string     `json:"path" binding:"required"`
+	MetaPassword string     `json:"meta_password"` // password of the meta which the path belongs to
+	Password     *string    `json:"password"`      // nil means keep the password when updating
+	Expires      *time.Time `json:"expires"`
+	MaxDownloads int        `json:"max_downloads"`
+	Remark       string     `json:"remark"`
+	Disabled     bool       `json:"disabled"`
+}
+
+// checkSharePath makes sure the user can access the path which is going to be shared
+func checkSharePath(c *gin.Context, user *model.User, req *ShareReq) bool {
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return false
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return false
+		}
+	}
+	if !common.CanAccessWithRoles(user, meta, reqPath, req.MetaPassword) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return false
+	}
+	if _, err = fs.Get(c, reqPath, &fs.GetArgs{}); err != nil {
+		common.ErrorResp(c, err, 500)
+		return false
+	}
+	return true
+}
+
+func ListShares(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	var shares []model.Share
+	var total int64
+	var err error
+	if user.IsAdmin() && c.Query("all") == "true" {
+		shares, total, err = op.GetShares(req.Page, req.PerPage)
+	} else {
+		shares, total, err = op.GetSharesByCreatorId(user.ID, req.Page, req.PerPage)
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: shares,
+		Total:   total,
+	})
+}
+
+func getOwnShare(c *gin.Context, id string) (*model.Share, bool) {
+	user := c.MustGet("user").(*model.User)
+	var s *model.Share
+	var err error
+	if user.IsAdmin() {
+		s, err = op.GetShareById(id)
+	} else {
+		s, err = op.GetShareByIdAndCreatorId(id, user.ID)
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return nil, false
+	}
+	return s, true
+}
+
+func GetShare(c *gin.Context) {
+	s, ok := getOwnShare(c, c.Query("id"))
+	if !ok {
+		return
+	}
+	common.SuccessResp(c, s)
+}
+
+func CreateShare(c *gin.Context) {
+	var req ShareReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if !checkSharePath(c, user, &req) {
+		return
+	}
+	s := &model.Share{
+		CreatorID:    user.ID,
+		Path:         req.Path,
+		MetaPassword: req.MetaPassword,
+		Expires:      req.Expires,
+		MaxDownloads: req.MaxDownloads,
+		Remark:       req.Remark,
+		Disabled:     req.Disabled,
+	}
+	if req.Password != nil {
+		s.SetPassword(*req.Password)
+	}
+	if err := op.CreateShare(s); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, s)
+}
+
+func UpdateShare(c *gin.Context) {
+	var req ShareReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	s, ok := getOwnShare(c, req.ID)
+	if !ok {
+		return
+	}
+	creator, err := op.GetUserById(s.CreatorID)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if !checkSharePath(c, creator, &req) {
+		return
+	}
+	s.Path = req.Path
+	s.MetaPassword = req.MetaPassword
+	if req.Password != nil {
+		s.SetPassword(*req.Password)
+	}
+	s.Expires = req.Expires
+	s.MaxDownloads = req.MaxDownloads
+	s.Remark = req.Remark
+	s.Disabled = req.Disabled
+	if err := op.UpdateShare(s); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, s)
+}
+
+func DeleteShare(c *gin.Context) {
+	s, ok := getOwnShare(c, c.Query("id"))
+	if !ok {
+		return
+	}
+	if err := op.DeleteShareById(s.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListShareAccessLogs(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	s, ok := getOwnShare(c, c.Query("id"))
+	if !ok {
+		return
+	}
+	logs, total, err := op.GetShareAccessLogs(s.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: logs,
+		Total:   total,
+	})
+}
+
+type ShareObjResp struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	IsDir    bool      `json:"is_dir"`
+	Modified time.Time `json:"modified"`
+	Type     int       `json:"type"`
+}
+
+type ShareListResp struct {
+	Path    string         `json:"path"`
+	Remark  string         `json:"remark"`
+	Content []ShareObjResp `json:"content"`
+	Total   int64          `json:"total"`
+}
+
+// the failed attempts of the share passwords of the ips
+var shareAttempts = cache.NewMemCache[int]()
+
+const (
+	shareAttemptsLimit   = 5
+	shareAttemptsTimeout = 5 * time.Minute
+	// shareDownloadExpiry is how long a counted download can be read, by the range requests
+	// of the downloaders and the players
+	shareDownloadExpiry = 6 * time.Hour
+)
+
+// sharePassword get the password from the header, or the form of the post requests,
+// it is never read from the query so that it isn't kept in the access logs
+func sharePassword(c *gin.Context) string {
+	if password := c.GetHeader("X-Share-Password"); password != "" {
+		return password
+	}
+	if c.Request.Method == http.MethodPost {
+		return c.PostForm("password")
+	}
+	return ""
+}
+
+// validateSharePassword check the password and limit the failed attempts of the ip
+func validateSharePassword(c *gin.Context, s *model.Share) bool {
+	ip := c.ClientIP()
+	count, _ := shareAttempts.Get(ip)
+	if count >= shareAttemptsLimit {
+		shareAttempts.Expire(ip, shareAttemptsTimeout)
+		common.ErrorStrResp(c, "too many incorrect share passwords, try again later", 429)
+		return false
+	}
+	if err := op.ValidateShare(s, sharePassword(c)); err != nil {
+		if errors.Is(err, errs.WrongSharePassword) {
+			shareAttempts.Set(ip, count+1, cache.WithEx[int](shareAttemptsTimeout))
+		}
+		common.ErrorResp(c, err, 403)
+		return false
+	}
+	return true
+}
+
+// shareDownloadData is signed as the token of a counted download of the file of the share
+func shareDownloadData(s *model.Share, subPath string) string {
+	return "share-download:" + s.ID + ":" + subPath
+}
+
+// ShareAccess serves /s/:id/*path, lists the folder or downloads the file.
+// The path is resolved with the creator's base path and permissions,
+// so a share can never reach beyond what its creator can see.
+//
+// A download is counted once by the first request, which is redirected to the url with a signed token,
+// so the range requests of the same download are not counted again.
+func ShareAccess(c *gin.Context) {
+	s, err := op.GetShareById(c.Param("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	subPath := utils.FixAndCleanPath(c.Param("path"))
+	token := c.Query("dl")
+	counted := token != "" && sign.Verify(shareDownloadData(s, subPath), token) == nil
+	if counted {
+		if err = op.CheckShare(s); err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+	} else if !validateSharePassword(c, s) {
+		return
+	}
+	creator, err := op.GetUserById(s.CreatorID)
+	if err != nil || creator.Disabled {
+		common.ErrorResp(c, errs.ShareDisabled, 403)
+		return
+	}
+	rootPath, err := creator.JoinPath(s.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	reqPath, err := utils.JoinBasePath(rootPath, subPath)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	// the meta password given by the creator is only for the meta of the shared path,
+	// the metas with passwords under it are denied
+	metaPassword := s.MetaPassword
+	if meta != nil && !utils.IsSubPath(meta.Path, rootPath) {
+		metaPassword = ""
+	}
+	if !common.CanAccessWithRoles(creator, meta, reqPath, metaPassword) {
+		common.ErrorStrResp(c, "the creator of the share has no permission", 403)
+		return
+	}
+	c.Set("user", creator)
+	c.Set("meta", meta)
+	accessLog := &model.ShareAccessLog{
+		ShareID:   s.ID,
+		Path:      subPath,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	obj, err := fs.Get(c, reqPath, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if obj.IsDir() {
+		accessLog.Action = model.ShareActionList
+		shareList(c, s, reqPath, subPath, accessLog)
+		return
+	}
+	c.Set("path", reqPath)
+	if counted {
+		Down(c)
+		return
+	}
+	if s.IsDownloadLimitReached() {
+		if c.Request.Method == http.MethodHead {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		common.ErrorResp(c, errs.ShareDownloadLimitReached, 403)
+		return
+	}
+	// the link is not given by head, which is not counted
+	if c.Request.Method == http.MethodHead {
+		c.Header("Content-Length", strconv.FormatInt(obj.GetSize(), 10))
+		c.Header("Content-Type", utils.GetMimeType(obj.GetName()))
+		c.Header("Last-Modified", obj.ModTime().UTC().Format(http.TimeFormat))
+		c.Status(http.StatusOK)
+		return
+	}
+	accessLog.Action = model.ShareActionDownload
+	// make sure the file can be downloaded before it is counted
+	link, _, err := fs.Link(c, reqPath, model.LinkArgs{IP: c.ClientIP(), Header: c.Request.Header, HttpReq: c.Request})
+	if err != nil {
+		op.AddShareAccessLog(accessLog)
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if link.MFile != nil {
+		_ = link.MFile.Close()
+	}
+	if err = op.IncreaseShareDownloads(s); err != nil {
+		op.AddShareAccessLog(accessLog)
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	accessLog.Success = true
+	op.AddShareAccessLog(accessLog)
+	query := url.Values{}
+	query.Set("dl", sign.WithDuration(shareDownloadData(s, subPath), shareDownloadExpiry))
+	if t := c.Query("type"); t != "" {
+		query.Set("type", t)
+	}
+	c.Redirect(http.StatusFound, (&url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}).String())
+}
+
+func shareList(c *gin.Context, s *model.Share, reqPath, subPath string, accessLog *model.ShareAccessLog) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	objs, err := fs.List(c, reqPath, &fs.ListArgs{})
+	if err != nil {
+		op.AddShareAccessLog(accessLog)
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	accessLog.Success = true
+	op.AddShareAccessLog(accessLog)
+	total, objs := pagination(objs, &req)
+	content := make([]ShareObjResp, 0, len(objs))
+	for _, obj := range objs {
+		content = append(content, ShareObjResp{
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			IsDir:    obj.IsDir(),
+			Modified: obj.ModTime(),
+			Type:     utils.GetObjType(obj.GetName(), obj.IsDir()),
+		})
+	}
+	common.SuccessResp(c, ShareListResp{
+		Path:    subPath,
+		Remark:  s.Remark,
+		Content: content,
+		Total:   int64(total),
+	})
+}
@@ -3,6 +3,8 @@ package handles
 import (
 	"context"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
@@ -13,6 +15,42 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+type StorageResp struct {
+	model.Storage
+	MountDetails *model.StorageDetails `json:"mount_details,omitempty"`
+}
+
+// getStoragesDetails fill the capacity of the loaded storages concurrently,
+// storages that can't report it in time are left empty
+func getStoragesDetails(ctx context.Context, storages []model.Storage) []StorageResp {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	resp := make([]StorageResp, len(storages))
+	var wg sync.WaitGroup
+	for i, s := range storages {
+		resp[i].Storage = s
+		if s.Disabled {
+			continue
+		}
+		storageDriver, err := op.GetStorageByMountPath(s.MountPath)
+		if err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			details, err := op.GetStorageDetails(ctx, storageDriver)
+			if err != nil {
+				log.Debugf("failed get details of storage [%s]: %+v", s.MountPath, err)
+				return
+			}
+			resp[i].MountDetails = details
+		}(i)
+	}
+	wg.Wait()
+	return resp
+}
+
 func ListStorages(c *gin.Context) {
 	var req model.PageReq
 	if err := c.ShouldBind(&req); err != nil {
@@ -27,7 +65,7 @@ func ListStorages(c *gin.Context) {
 		return
 	}
 	common.SuccessResp(c, common.PageResp{
-		Content: storages,
+		Content: getStoragesDetails(c, storages),
 		Total:   total,
 	})
 }
@@ -0,0 +1,49 @@
+package handles
+
+import (
+	"fmt"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/thumbnail"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+func Thumb(c *gin.Context) {
+	rawPath := c.MustGet("path").(string)
+	file, err := thumbnail.Get(c, rawPath)
+	if err != nil {
+		if errors.Is(err, errs.NotSupport) {
+			common.ErrorStrResp(c, "thumbnail is not supported for this file", 404)
+			return
+		}
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	// the name of the cached file changes with the image, so it is a strong etag
+	c.Header("ETag", `"`+strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))+`"`)
+	c.Header("Cache-Control", "max-age=3600")
+	c.File(file)
+}
+
+// fillThumbs give the images without the thumbnail of the driver the one generated by /t
+func fillThumbs(c *gin.Context, objs []ObjLabelResp, parent string) {
+	for i := range objs {
+		if objs[i].Thumb != "" || objs[i].IsDir || !thumbnail.Supported(objs[i].Name, objs[i].Size) {
+			continue
+		}
+		query := ""
+		if objs[i].Sign != "" {
+			query = "?sign=" + objs[i].Sign
+		}
+		objs[i].Thumb = fmt.Sprintf("%s/t%s%s",
+			common.GetApiUrl(c.Request),
+			utils.EncodePath(stdpath.Join(parent, objs[i].Name), true),
+			query)
+	}
+}
@@ -0,0 +1,82 @@
+package handles
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListTrash list the objects removed by the current user, admin can see all of them
+func ListTrash(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	var (
+		items []model.TrashItem
+		total int64
+		err   error
+	)
+	if user.IsAdmin() {
+		items, total, err = op.GetTrashItems(req.Page, req.PerPage)
+	} else {
+		items, total, err = op.GetTrashItemsByUserId(user.ID, req.Page, req.PerPage)
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: items,
+		Total:   total,
+	})
+}
+
+type TrashReq struct {
+	IDs []uint `json:"ids"`
+}
+
+func RestoreTrash(c *gin.Context) {
+	handleTrashItems(c, fs.RestoreTrash)
+}
+
+func PurgeTrash(c *gin.Context) {
+	handleTrashItems(c, fs.PurgeTrash)
+}
+
+func handleTrashItems(c *gin.Context, handle func(ctx context.Context, item *model.TrashItem) error) {
+	var req TrashReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if len(req.IDs) == 0 {
+		common.ErrorStrResp(c, "Empty trash item ids", 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	for _, id := range req.IDs {
+		item, err := op.GetTrashItemById(id)
+		if err != nil {
+			common.ErrorResp(c, err, 404)
+			return
+		}
+		if !user.IsAdmin() && (item.UserID != user.ID || !common.CheckPathLimitWithRoles(user, item.Path)) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+		if err = handle(c, item); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c)
+}
@@ -117,6 +117,7 @@ func FinishAuthnLogin(c *gin.Context) {
 		return
 	}
 	common.SuccessResp(c, gin.H{"token": token})
+	onLogin(c, user)
 }
 
 func BeginAuthnRegistration(c *gin.Context) {
@@ -0,0 +1,41 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListWebdavLocks list the webdav locks kept in the db, the locks kept in memory are not listed
+func ListWebdavLocks(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	locks, total, err := op.GetWebdavLocks(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: locks,
+		Total:   total,
+	})
+}
+
+// DeleteWebdavLock force release the lock, for the locks which are left by the crashed clients
+func DeleteWebdavLock(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		common.ErrorStrResp(c, "token is required", 400)
+		return
+	}
+	if err := op.DeleteWebdavLockByToken(token); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,14 @@
+package middlewares
+
+import (
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientInfo put the protocol and the ip of the client into the context,
+// which are recorded by the audit log of internal/fs
+func ClientInfo(c *gin.Context) {
+	c.Set(conf.ProtocolKey, "http")
+	c.Set(conf.ClientIPKey, c.ClientIP())
+	c.Next()
+}
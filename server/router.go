@@ -34,6 +34,7 @@ func Init(e *gin.Engine) {
 	g.GET("/i/:link_name", handles.Plist)
 	common.SecretKey = []byte(conf.Conf.JwtSecret)
 	g.Use(middlewares.StoragesLoaded)
+	g.Use(middlewares.ClientInfo)
 	if conf.Conf.MaxConnections > 0 {
 		g.Use(middlewares.MaxAllowed(conf.Conf.MaxConnections))
 	}
@@ -46,6 +47,8 @@ func Init(e *gin.Engine) {
 	g.GET("/p/*path", signCheck, downloadLimiter, handles.Proxy)
 	g.HEAD("/d/*path", signCheck, handles.Down)
 	g.HEAD("/p/*path", signCheck, handles.Proxy)
+	g.GET("/t/*path", signCheck, downloadLimiter, handles.Thumb)
+	g.HEAD("/t/*path", signCheck, handles.Thumb)
 	archiveSignCheck := middlewares.Down(sign.VerifyArchive)
 	g.GET("/ad/*path", archiveSignCheck, downloadLimiter, handles.ArchiveDown)
 	g.GET("/ap/*path", archiveSignCheck, downloadLimiter, handles.ArchiveProxy)
@@ -53,6 +56,13 @@ func Init(e *gin.Engine) {
 	g.HEAD("/ad/*path", archiveSignCheck, handles.ArchiveDown)
 	g.HEAD("/ap/*path", archiveSignCheck, handles.ArchiveProxy)
 	g.HEAD("/ae/*path", archiveSignCheck, handles.ArchiveInternalExtract)
+	g.GET("/s/:id", downloadLimiter, handles.ShareAccess)
+	g.GET("/s/:id/*path", downloadLimiter, handles.ShareAccess)
+	g.HEAD("/s/:id", handles.ShareAccess)
+	g.HEAD("/s/:id/*path", handles.ShareAccess)
+	// the password of the share can be posted by a form
+	g.POST("/s/:id", downloadLimiter, handles.ShareAccess)
+	g.POST("/s/:id/*path", downloadLimiter, handles.ShareAccess)
 
 	api := g.Group("/api")
 	auth := api.Group("", middlewares.Auth)
@@ -66,6 +76,9 @@ func Init(e *gin.Engine) {
 	auth.GET("/me/sshkey/list", handles.ListMyPublicKey)
 	auth.POST("/me/sshkey/add", handles.AddMyPublicKey)
 	auth.POST("/me/sshkey/delete", handles.DeleteMyPublicKey)
+	auth.GET("/me/s3key/list", handles.ListMyS3Keys)
+	auth.POST("/me/s3key/add", handles.AddMyS3Key)
+	auth.POST("/me/s3key/delete", handles.DeleteMyS3Key)
 	auth.POST("/auth/2fa/generate", handles.Generate2FA)
 	auth.POST("/auth/2fa/verify", handles.Verify2FA)
 	auth.GET("/auth/logout", handles.LogOut)
@@ -92,6 +105,7 @@ func Init(e *gin.Engine) {
 
 	_fs(auth.Group("/fs"))
 	_task(auth.Group("/task", middlewares.AuthNotGuest))
+	_share(auth.Group("/share", middlewares.AuthNotGuest))
 	admin(auth.Group("/admin", middlewares.AuthAdmin))
 	if flags.Debug || flags.Dev {
 		debug(g.Group("/debug"))
@@ -119,6 +133,8 @@ func admin(g *gin.RouterGroup) {
 	user.POST("/del_cache", handles.DelUserCache)
 	user.GET("/sshkey/list", handles.ListPublicKeys)
 	user.POST("/sshkey/delete", handles.DeletePublicKey)
+	user.GET("/s3key/list", handles.ListS3Keys)
+	user.POST("/s3key/delete", handles.DeleteS3Key)
 
 	role := g.Group("/role")
 	role.GET("/list", handles.ListRoles)
@@ -127,6 +143,18 @@ func admin(g *gin.RouterGroup) {
 	role.POST("/update", handles.UpdateRole)
 	role.POST("/delete", handles.DeleteRole)
 
+	quota := g.Group("/quota")
+	quota.GET("/usage", handles.ListQuotaUsages)
+	quota.POST("/reconcile", handles.ReconcileQuotaUsages)
+
+	audit := g.Group("/audit")
+	audit.GET("/list", handles.ListAuditLogs)
+	audit.GET("/export", handles.ExportAuditLogs)
+
+	dedup := g.Group("/dedup")
+	dedup.GET("/report", handles.DedupReport)
+	dedup.POST("/run", handles.RunDedup)
+
 	storage := g.Group("/storage")
 	storage.GET("/list", handles.ListStorages)
 	storage.GET("/get", handles.GetStorage)
@@ -155,6 +183,29 @@ func admin(g *gin.RouterGroup) {
 	setting.POST("/set_pikpak", handles.SetPikPak)
 	setting.POST("/set_thunder", handles.SetThunder)
 
+	sch := g.Group("/schedule")
+	sch.GET("/list", handles.ListScheduledJobs)
+	sch.GET("/get", handles.GetScheduledJob)
+	sch.POST("/create", handles.CreateScheduledJob)
+	sch.POST("/update", handles.UpdateScheduledJob)
+	sch.POST("/delete", handles.DeleteScheduledJob)
+	sch.POST("/run", handles.RunScheduledJob)
+	sch.GET("/runs", handles.ListScheduledJobRuns)
+
+	wh := g.Group("/webhook")
+	wh.GET("/list", handles.ListWebhooks)
+	wh.GET("/get", handles.GetWebhook)
+	wh.POST("/create", handles.CreateWebhook)
+	wh.POST("/update", handles.UpdateWebhook)
+	wh.POST("/delete", handles.DeleteWebhook)
+	wh.POST("/ping", handles.PingWebhook)
+	wh.GET("/deliveries", handles.ListWebhookDeliveries)
+	wh.POST("/redeliver", handles.RedeliverWebhook)
+
+	davLock := g.Group("/webdav/lock")
+	davLock.GET("/list", handles.ListWebdavLocks)
+	davLock.POST("/delete", handles.DeleteWebdavLock)
+
 	// retain /admin/task API to ensure compatibility with legacy automation scripts
 	_task(g.Group("/task"))
 
@@ -165,6 +216,7 @@ func admin(g *gin.RouterGroup) {
 	index := g.Group("/index")
 	index.POST("/build", middlewares.SearchIndex, handles.BuildIndex)
 	index.POST("/update", middlewares.SearchIndex, handles.UpdateIndex)
+	index.POST("/refresh", middlewares.SearchIndex, handles.RefreshIndex)
 	index.POST("/stop", middlewares.SearchIndex, handles.StopIndex)
 	index.POST("/clear", middlewares.SearchIndex, handles.ClearIndex)
 	index.GET("/progress", middlewares.SearchIndex, handles.GetProgress)
@@ -187,7 +239,15 @@ func _fs(g *gin.RouterGroup) {
 	g.Any("/list", handles.FsList)
 	g.Any("/search", middlewares.SearchIndex, handles.Search)
 	g.Any("/get", handles.FsGet)
+	g.Any("/details", handles.FsDetails)
+	g.GET("/trash/list", handles.ListTrash)
+	g.POST("/trash/restore", handles.RestoreTrash)
+	g.POST("/trash/purge", handles.PurgeTrash)
+	g.Any("/versions/list", handles.FsVersions)
+	g.GET("/versions/download", handles.FsVersionDownload)
+	g.POST("/versions/restore", handles.FsVersionRestore)
 	g.Any("/other", handles.FsOther)
+	g.Any("/download_zip", middlewares.DownloadRateLimiter(stream.ClientDownloadLimit), handles.FsDownloadZip)
 	g.Any("/dirs", handles.FsDirs)
 	g.POST("/mkdir", handles.FsMkdir)
 	g.POST("/rename", handles.FsRename)
@@ -196,11 +256,17 @@ func _fs(g *gin.RouterGroup) {
 	g.POST("/move", handles.FsMove)
 	g.POST("/recursive_move", handles.FsRecursiveMove)
 	g.POST("/copy", handles.FsCopy)
+	g.POST("/sync", handles.FsSync)
 	g.POST("/remove", handles.FsRemove)
 	g.POST("/remove_empty_directory", handles.FsRemoveEmptyDirectory)
 	uploadLimiter := middlewares.UploadRateLimiter(stream.ClientUploadLimit)
 	g.PUT("/put", middlewares.FsUp, uploadLimiter, handles.FsStream)
 	g.PUT("/form", middlewares.FsUp, uploadLimiter, handles.FsForm)
+	g.OPTIONS("/tus", handles.TusOptions)
+	g.POST("/tus", middlewares.FsUp, uploadLimiter, handles.TusCreate)
+	g.HEAD("/tus/:id", handles.TusHead)
+	g.PATCH("/tus/:id", uploadLimiter, handles.TusPatch)
+	g.DELETE("/tus/:id", handles.TusDelete)
 	g.POST("/link", middlewares.AuthAdmin, handles.Link)
 	// g.POST("/add_aria2", handles.AddOfflineDownload)
 	// g.POST("/add_qbit", handles.AddQbittorrent)
@@ -210,6 +276,16 @@ func _fs(g *gin.RouterGroup) {
 	a.Any("/meta", handles.FsArchiveMeta)
 	a.Any("/list", handles.FsArchiveList)
 	a.POST("/decompress", handles.FsArchiveDecompress)
+	a.POST("/compress", handles.FsArchiveCompress)
+}
+
+func _share(g *gin.RouterGroup) {
+	g.GET("/list", handles.ListShares)
+	g.GET("/get", handles.GetShare)
+	g.POST("/create", handles.CreateShare)
+	g.POST("/update", handles.UpdateShare)
+	g.POST("/delete", handles.DeleteShare)
+	g.GET("/logs", handles.ListShareAccessLogs)
 }
 
 func _task(g *gin.RouterGroup) {
@@ -222,6 +298,8 @@ func Cors(r *gin.Engine) {
 	config.AllowOrigins = conf.Conf.Cors.AllowOrigins
 	config.AllowHeaders = conf.Conf.Cors.AllowHeaders
 	config.AllowMethods = conf.Conf.Cors.AllowMethods
+	// the headers of the resumable uploads read by the browsers
+	config.ExposeHeaders = []string{"Location", "Tus-Resumable", "Tus-Version", "Tus-Extension", "Upload-Offset", "Upload-Length", "Upload-Metadata", "Upload-Expires", "Upload-Task-Id"}
 	r.Use(cors.New(config))
 }
 
@@ -2,10 +2,12 @@ package server
 
 import (
 	"context"
+	"net/http"
 	"path"
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/alist/v3/server/s3"
 	"github.com/gin-gonic/gin"
@@ -24,16 +26,30 @@ func S3(g *gin.RouterGroup) {
 		})
 		return
 	}
-	h, _ := s3.NewServer(context.Background())
+	h, err := s3.NewServer(context.Background())
+	if err != nil {
+		utils.Log.Fatalf("failed to start s3 server: %+v", err)
+	}
 
 	g.Any("/*path", func(c *gin.Context) {
 		adjustedPath := strings.TrimPrefix(c.Request.URL.Path, path.Join(conf.URL.Path, "/s3"))
 		c.Request.URL.Path = adjustedPath
-		gin.WrapH(h)(c)
+		serveS3(h)(c)
 	})
 }
 
 func S3Server(g *gin.RouterGroup) {
-	h, _ := s3.NewServer(context.Background())
-	g.Any("/*path", gin.WrapH(h))
+	h, err := s3.NewServer(context.Background())
+	if err != nil {
+		utils.Log.Fatalf("failed to start s3 server: %+v", err)
+	}
+	g.Any("/*path", serveS3(h))
+}
+
+func serveS3(h http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), conf.ProtocolKey, "s3")
+		ctx = context.WithValue(ctx, conf.ClientIPKey, c.ClientIP())
+		h.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
 }
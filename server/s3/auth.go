@@ -0,0 +1,324 @@
+package s3
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/gofakes3"
+	"github.com/alist-org/gofakes3/signature"
+	"github.com/alist-org/gofakes3/xml"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// errAccessDenied is returned by the backend if the user has no permission of the path
+var errAccessDenied = gofakes3.ErrorCode("AccessDenied")
+
+// errQuotaExceeded is returned by the backend if the object exceeds the quota of the user
+var errQuotaExceeded = gofakes3.ErrorCode("QuotaExceeded")
+
+// errContentSha256Mismatch is returned if the payload doesn't match the X-Amz-Content-Sha256 header
+var errContentSha256Mismatch = gofakes3.ErrorCode("XAmzContentSHA256Mismatch")
+
+// errSignatureDoesNotMatch is returned if the signature of a chunk of the streaming payload is invalid
+var errSignatureDoesNotMatch = gofakes3.ErrorCode("SignatureDoesNotMatch")
+
+const (
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// the chunks of the payload are signed, with or without the trailers
+	streamingSignedPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	// the sha256 of the empty string
+	emptySha256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// server runs the requests as the users of the access keys, the signatures are verified by the faker.
+// The global access key of the settings runs as admin, and the anonymous requests run as guest
+// only if there is no access key at all.
+type server struct {
+	faker        *gofakes3.GoFakeS3
+	handler      http.Handler
+	backend      *s3Backend
+	globalKey    string
+	globalSecret string
+	// the access keys of the users which have been added to the faker
+	keys   sync.Map
+	keysMu sync.Mutex
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := checkPresigned(r); err != nil {
+		writeAccessDenied(w, err.Error())
+		return
+	}
+	user, err := s.getUser(r)
+	if err != nil {
+		writeAccessDenied(w, err.Error())
+		return
+	}
+	r = r.WithContext(context.WithValue(r.Context(), "user", user))
+	if isMultipart(r) {
+		if verifySignature(w, r) {
+			s.serveMultipart(w, r)
+		}
+		return
+	}
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *server) getUser(r *http.Request) (*model.User, error) {
+	accessKeyId := getAccessKeyId(r)
+	if accessKeyId == "" {
+		if s.globalKey != "" || s.hasKeys() {
+			return nil, errors.New("access key is required")
+		}
+		guest, err := op.GetGuest()
+		if err != nil {
+			return nil, err
+		}
+		if guest.Disabled {
+			return nil, errors.New("guest user is disabled")
+		}
+		return guest, nil
+	}
+	if accessKeyId == s.globalKey {
+		return op.GetAdmin()
+	}
+	key, err := op.GetS3AccessKeyByAccessKeyId(accessKeyId)
+	if err != nil {
+		return nil, errors.New("the access key id does not exist")
+	}
+	user, err := op.GetUserById(key.UserId)
+	if err != nil {
+		return nil, errors.New("the user of the access key does not exist")
+	}
+	if user.Disabled {
+		return nil, errors.New("the user of the access key is disabled")
+	}
+	s.addKey(key)
+	// only record the time once a minute, instead of writing the db for every request
+	if time.Since(key.LastUsedTime) > time.Minute {
+		key.UpdateLastUsedTime()
+		if err = op.UpdateS3AccessKey(key); err != nil {
+			log.Warnf("failed update last used time of s3 key %d: %+v", key.ID, err)
+		}
+	}
+	return user, nil
+}
+
+// hasKeys check whether there is any access key, including the keys created after the server started
+func (s *server) hasKeys() bool {
+	has := false
+	s.keys.Range(func(_, _ any) bool {
+		has = true
+		return false
+	})
+	if has {
+		return true
+	}
+	count, err := op.CountS3AccessKeys()
+	return err != nil || count > 0
+}
+
+// addKey add the key created after the server started to the faker, the request is not passed to the faker
+// before the key is added, otherwise the signature may not be verified
+func (s *server) addKey(key *model.S3AccessKey) {
+	if _, ok := s.keys.Load(key.AccessKeyId); ok {
+		return
+	}
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+	if _, ok := s.keys.Load(key.AccessKeyId); ok {
+		return
+	}
+	s.faker.AddAuthKeys(map[string]string{key.AccessKeyId: key.SecretAccessKey})
+	s.keys.Store(key.AccessKeyId, struct{}{})
+}
+
+// verifySignature verify the signature like the faker, for the requests which are handled without the faker.
+// The anonymous requests have been checked by getUser.
+func verifySignature(w http.ResponseWriter, r *http.Request) bool {
+	if getAccessKeyId(r) == "" {
+		return true
+	}
+	code := signature.V4SignVerify(r)
+	if code == signature.ErrUnsupportAlgorithm {
+		code = signature.V2SignVerify(r)
+	}
+	if code == signature.ErrNone {
+		return true
+	}
+	resp := signature.GetAPIError(code)
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(resp.HTTPStatusCode)
+	_, _ = w.Write(signature.EncodeAPIErrorToResponse(resp))
+	return false
+}
+
+// chunkSigner verify the signatures of the chunks of the streaming payload, each of which is chained to the previous one,
+// starting from the signature of the request
+type chunkSigner struct {
+	key     []byte
+	date    string
+	scope   string
+	prevSig string
+}
+
+// newChunkSigner get the signing key of the request which has been verified by verifySignature
+func (s *server) newChunkSigner(r *http.Request) (*chunkSigner, error) {
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	if !ok {
+		return nil, errSignatureDoesNotMatch
+	}
+	var cred, seed string
+	for _, field := range strings.Split(auth, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch k {
+		case "Credential":
+			cred = v
+		case "Signature":
+			seed = v
+		}
+	}
+	// <access key id>/<date>/<region>/<service>/aws4_request
+	parts := strings.Split(cred, "/")
+	if len(parts) != 5 || seed == "" {
+		return nil, errSignatureDoesNotMatch
+	}
+	secret := s.globalSecret
+	if parts[0] != s.globalKey {
+		key, err := op.GetS3AccessKeyByAccessKeyId(parts[0])
+		if err != nil {
+			return nil, errSignatureDoesNotMatch
+		}
+		secret = key.SecretAccessKey
+	}
+	key := []byte("AWS4" + secret)
+	for _, p := range parts[1:] {
+		key = hmacSha256(key, p)
+	}
+	return &chunkSigner{
+		key:     key,
+		date:    r.Header.Get("X-Amz-Date"),
+		scope:   strings.Join(parts[1:], "/"),
+		prevSig: seed,
+	}, nil
+}
+
+func (c *chunkSigner) verify(data []byte, sig string) bool {
+	sum := sha256.Sum256(data)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256-PAYLOAD", c.date, c.scope, c.prevSig, emptySha256, hex.EncodeToString(sum[:])}, "\n")
+	expected := hex.EncodeToString(hmacSha256(c.key, stringToSign))
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return false
+	}
+	c.prevSig = sig
+	return true
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// getAccessKeyId get the access key id from the authorization header of v4 or v2, or the query of the presigned url
+func getAccessKeyId(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if _, cred, ok := strings.Cut(auth, "Credential="); ok {
+		id, _, _ := strings.Cut(cred, "/")
+		return strings.TrimSpace(id)
+	}
+	if cred, ok := strings.CutPrefix(auth, "AWS "); ok {
+		id, _, _ := strings.Cut(cred, ":")
+		return strings.TrimSpace(id)
+	}
+	query := r.URL.Query()
+	if cred := query.Get("X-Amz-Credential"); cred != "" {
+		id, _, _ := strings.Cut(cred, "/")
+		return id
+	}
+	return query.Get("AWSAccessKeyId")
+}
+
+// maxPresignExpires is the longest lifetime of the presigned urls allowed by s3, which is 7 days
+const maxPresignExpires = 7 * 24 * 60 * 60
+
+// checkPresigned check the lifetime of the presigned url, the signature is verified by the faker.
+// The expiration of v4 is checked by the faker too, but not the limit of it or the expiration of v2.
+func checkPresigned(r *http.Request) error {
+	query := r.URL.Query()
+	if query.Get("X-Amz-Signature") != "" {
+		expires, err := strconv.ParseInt(query.Get("X-Amz-Expires"), 10, 64)
+		if err != nil || expires <= 0 || expires > maxPresignExpires {
+			return errors.Errorf("X-Amz-Expires must be between 1 and %d seconds", maxPresignExpires)
+		}
+		date, err := time.Parse("20060102T150405Z", query.Get("X-Amz-Date"))
+		if err != nil {
+			return errors.New("X-Amz-Date is invalid")
+		}
+		if date.After(time.Now().Add(15 * time.Minute)) {
+			return errors.New("request is not valid yet")
+		}
+		return nil
+	}
+	if query.Get("AWSAccessKeyId") != "" && query.Get("Signature") != "" {
+		expires, err := strconv.ParseInt(query.Get("Expires"), 10, 64)
+		if err != nil {
+			return errors.New("Expires is invalid")
+		}
+		if time.Now().Unix() > expires {
+			return errors.New("request has expired")
+		}
+	}
+	return nil
+}
+
+func writeAccessDenied(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusForbidden)
+	_, _ = w.Write(signature.EncodeAPIErrorToResponse(signature.APIError{
+		Code:        string(errAccessDenied),
+		Description: message,
+	}))
+}
+
+func writeXML(w http.ResponseWriter, v any) error {
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write([]byte(xml.Header))
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// writeError write the error like the faker, except that the access denied and quota exceeded errors are 403
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var resp *gofakes3.ErrorResponse
+	if !errors.As(err, &resp) {
+		resp = &gofakes3.ErrorResponse{Code: gofakes3.ErrInternal, Message: "Internal Error"}
+		var e gofakes3.Error
+		if errors.As(err, &e) {
+			resp.Code, resp.Message = e.ErrorCode(), e.Error()
+		} else {
+			log.Errorf("serve s3: %+v", err)
+		}
+	}
+	status := resp.Code.Status()
+	switch resp.Code {
+	case errAccessDenied, errQuotaExceeded, errSignatureDoesNotMatch:
+		status = http.StatusForbidden
+	case errContentSha256Mismatch:
+		status = http.StatusBadRequest
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		_ = xml.NewEncoder(w).Encode(resp)
+	}
+}
@@ -9,11 +9,11 @@ import (
 	"io"
 	"path"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -33,26 +33,28 @@ var (
 
 // s3Backend implements the gofacess3.Backend interface to make an S3
 // backend for gofakes3
-type s3Backend struct {
-	meta *sync.Map
-}
+type s3Backend struct{}
 
 // newBackend creates a new SimpleBucketBackend.
-func newBackend() gofakes3.Backend {
-	return &s3Backend{
-		meta: new(sync.Map),
-	}
+func newBackend() *s3Backend {
+	return &s3Backend{}
 }
 
-// ListBuckets always returns the default bucket.
+// ListBuckets returns the buckets which can be read by the user.
 func (b *s3Backend) ListBuckets(ctx context.Context) ([]gofakes3.BucketInfo, error) {
-	buckets, err := getAndParseBuckets()
+	buckets, err := getUserBuckets(ctx)
 	if err != nil {
 		return nil, err
 	}
 	var response []gofakes3.BucketInfo
 	for _, b := range buckets {
-		node, _ := fs.Get(ctx, b.Path, &fs.GetArgs{})
+		if !canRead(ctx, b.Path) {
+			continue
+		}
+		node, err := fs.Get(ctx, b.Path, &fs.GetArgs{})
+		if err != nil {
+			continue
+		}
 		response = append(response, gofakes3.BucketInfo{
 			// Name:         gofakes3.URLEncode(b.Name),
 			Name:         b.Name,
@@ -64,7 +66,7 @@ func (b *s3Backend) ListBuckets(ctx context.Context) ([]gofakes3.BucketInfo, err
 
 // ListBucket lists the objects in the given bucket.
 func (b *s3Backend) ListBucket(ctx context.Context, bucketName string, prefix *gofakes3.Prefix, page gofakes3.ListBucketPage) (*gofakes3.ObjectList, error) {
-	bucket, err := getBucketByName(bucketName)
+	bucket, err := getBucketByName(ctx, bucketName)
 	if err != nil {
 		return nil, err
 	}
@@ -85,7 +87,7 @@ func (b *s3Backend) ListBucket(ctx context.Context, bucketName string, prefix *g
 	response := gofakes3.NewObjectList()
 	path, remaining := prefixParser(prefix)
 
-	err = b.entryListR(bucketPath, path, remaining, prefix.HasDelimiter, response)
+	err = b.entryListR(ctx, bucketPath, path, remaining, prefix.HasDelimiter, response)
 	if err == gofakes3.ErrNoSuchKey {
 		// AWS just returns an empty list
 		response = gofakes3.NewObjectList()
@@ -100,13 +102,16 @@ func (b *s3Backend) ListBucket(ctx context.Context, bucketName string, prefix *g
 //
 // Note that the metadata is not supported yet.
 func (b *s3Backend) HeadObject(ctx context.Context, bucketName, objectName string) (*gofakes3.Object, error) {
-	bucket, err := getBucketByName(bucketName)
+	bucket, err := getBucketByName(ctx, bucketName)
 	if err != nil {
 		return nil, err
 	}
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if !canRead(ctx, fp) {
+		return nil, errAccessDenied
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	node, err := fs.Get(context.WithValue(ctx, "meta", fmeta), fp, &fs.GetArgs{})
 	if err != nil {
@@ -118,23 +123,18 @@ func (b *s3Backend) HeadObject(ctx context.Context, bucketName, objectName strin
 	}
 
 	size := node.GetSize()
-	// hash := getFileHashByte(fobj)
+	hash := getFileHashByte(node)
 
 	meta := map[string]string{
 		"Last-Modified": node.ModTime().Format(timeFormat),
 		"Content-Type":  utils.GetMimeType(fp),
 	}
 
-	if val, ok := b.meta.Load(fp); ok {
-		metaMap := val.(map[string]string)
-		for k, v := range metaMap {
-			meta[k] = v
-		}
-	}
+	loadObjectMeta(fp, meta)
 
 	return &gofakes3.Object{
-		Name: objectName,
-		// Hash:     hash,
+		Name:     objectName,
+		Hash:     hash,
 		Metadata: meta,
 		Size:     size,
 		Contents: noOpReadCloser{},
@@ -143,13 +143,16 @@ func (b *s3Backend) HeadObject(ctx context.Context, bucketName, objectName strin
 
 // GetObject fetchs the object from the filesystem.
 func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string, rangeRequest *gofakes3.ObjectRangeRequest) (obj *gofakes3.Object, err error) {
-	bucket, err := getBucketByName(bucketName)
+	bucket, err := getBucketByName(ctx, bucketName)
 	if err != nil {
 		return nil, err
 	}
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if !canRead(ctx, fp) {
+		return nil, errAccessDenied
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	node, err := fs.Get(context.WithValue(ctx, "meta", fmeta), fp, &fs.GetArgs{})
 	if err != nil {
@@ -161,6 +164,7 @@ func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string
 	}
 
 	link, file, err := fs.Link(ctx, fp, model.LinkArgs{})
+	fs.AuditDownload(ctx, fp, err)
 	if err != nil {
 		return nil, err
 	}
@@ -217,17 +221,12 @@ func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string
 		"Content-Type":  utils.GetMimeType(fp),
 	}
 
-	if val, ok := b.meta.Load(fp); ok {
-		metaMap := val.(map[string]string)
-		for k, v := range metaMap {
-			meta[k] = v
-		}
-	}
+	loadObjectMeta(fp, meta)
 
 	return &gofakes3.Object{
 		// Name: gofakes3.URLEncode(objectName),
-		Name: objectName,
-		// Hash:     "",
+		Name:     objectName,
+		Hash:     getFileHashByte(node),
 		Metadata: meta,
 		Size:     size,
 		Range:    rnge,
@@ -235,10 +234,26 @@ func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string
 	}, nil
 }
 
-// TouchObject creates or updates meta on specified object.
+// TouchObject updates meta on specified object, fp is the path of the object in the bucket, as "<bucket>/<key>".
+//
+// Note that the modified time of the object can't be changed.
 func (b *s3Backend) TouchObject(ctx context.Context, fp string, meta map[string]string) (result gofakes3.PutObjectResult, err error) {
-	//TODO: implement
-	return result, gofakes3.ErrNotImplemented
+	bucketName, objectName, _ := strings.Cut(strings.TrimPrefix(fp, "/"), "/")
+	bucket, err := getBucketByName(ctx, bucketName)
+	if err != nil {
+		return result, err
+	}
+	fp = path.Join(bucket.Path, objectName)
+	if !canWrite(ctx, fp) {
+		return result, errAccessDenied
+	}
+	fmeta, _ := op.GetNearestMeta(fp)
+	node, err := fs.Get(context.WithValue(ctx, "meta", fmeta), fp, &fs.GetArgs{})
+	if err != nil || node.IsDir() {
+		return result, gofakes3.KeyNotFound(objectName)
+	}
+	saveObjectMeta(fp, meta)
+	return result, nil
 }
 
 // PutObject creates or overwrites the object with the given name.
@@ -247,7 +262,7 @@ func (b *s3Backend) PutObject(
 	meta map[string]string,
 	input io.Reader, size int64,
 ) (result gofakes3.PutObjectResult, err error) {
-	bucket, err := getBucketByName(bucketName)
+	bucket, err := getBucketByName(ctx, bucketName)
 	if err != nil {
 		return result, err
 	}
@@ -266,6 +281,9 @@ func (b *s3Backend) PutObject(
 		reqPath = path.Dir(fp)
 	}
 	log.Debugf("reqPath: %s", reqPath)
+	if !canWrite(ctx, fp) {
+		return result, errAccessDenied
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	ctx = context.WithValue(ctx, "meta", fmeta)
 
@@ -309,17 +327,20 @@ func (b *s3Backend) PutObject(
 	}
 
 	err = fs.PutDirectly(ctx, reqPath, stream)
+	if errors.Is(err, errs.QuotaExceeded) {
+		return result, gofakes3.ErrorMessage(errQuotaExceeded, err.Error())
+	}
 	if err != nil {
 		return result, err
 	}
 
 	if err := stream.Close(); err != nil {
 		// remove file when close error occurred (FsPutErr)
-		_ = fs.Remove(ctx, fp)
+		_ = fs.Remove(context.WithValue(ctx, conf.NoTrashKey, struct{}{}), fp)
 		return result, err
 	}
 
-	b.meta.Store(fp, meta)
+	saveObjectMeta(fp, meta)
 
 	return result, nil
 }
@@ -351,13 +372,16 @@ func (b *s3Backend) DeleteObject(ctx context.Context, bucketName, objectName str
 
 // deleteObject deletes the object from the filesystem.
 func (b *s3Backend) deleteObject(ctx context.Context, bucketName, objectName string) error {
-	bucket, err := getBucketByName(bucketName)
+	bucket, err := getBucketByName(ctx, bucketName)
 	if err != nil {
 		return err
 	}
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if !canRemove(ctx, fp) {
+		return errAccessDenied
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	// S3 does not report an error when attemping to delete a key that does not exist, so
 	// we need to skip IsNotExist errors.
@@ -365,6 +389,7 @@ func (b *s3Backend) deleteObject(ctx context.Context, bucketName, objectName str
 		return err
 	}
 
+	// the kept metadata is removed with the object
 	fs.Remove(ctx, fp)
 	return nil
 }
@@ -381,7 +406,7 @@ func (b *s3Backend) DeleteBucket(ctx context.Context, name string) error {
 
 // BucketExists checks if the bucket exists.
 func (b *s3Backend) BucketExists(ctx context.Context, name string) (exists bool, err error) {
-	buckets, err := getAndParseBuckets()
+	buckets, err := getUserBuckets(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -395,12 +420,15 @@ func (b *s3Backend) BucketExists(ctx context.Context, name string) (exists bool,
 
 // CopyObject copy specified object from srcKey to dstKey.
 func (b *s3Backend) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, meta map[string]string) (result gofakes3.CopyObjectResult, err error) {
+	replace := meta["X-Amz-Metadata-Directive"] == "REPLACE"
 	if srcBucket == dstBucket && srcKey == dstKey {
-		//TODO: update meta
-		return result, nil
+		if replace {
+			_, err = b.TouchObject(ctx, path.Join(srcBucket, srcKey), meta)
+		}
+		return result, err
 	}
 
-	srcB, err := getBucketByName(srcBucket)
+	srcB, err := getBucketByName(ctx, srcBucket)
 	if err != nil {
 		return result, err
 	}
@@ -418,8 +446,10 @@ func (b *s3Backend) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket
 		_ = c.Contents.Close()
 	}()
 
-	for k, v := range c.Metadata {
-		if _, found := meta[k]; !found && k != "X-Amz-Acl" {
+	// the metadata of the source is copied unless it is replaced by the request
+	if !replace {
+		meta = make(map[string]string, len(c.Metadata))
+		for k, v := range c.Metadata {
 			meta[k] = v
 		}
 	}
@@ -0,0 +1,82 @@
+package s3
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	log "github.com/sirupsen/logrus"
+)
+
+// the headers which are kept as the metadata of the objects, besides the user metadata of x-amz-meta-*
+var storedHeaders = map[string]bool{
+	"Content-Type":        true,
+	"Content-Disposition": true,
+	"Content-Encoding":    true,
+	"Content-Language":    true,
+	"Cache-Control":       true,
+	"Expires":             true,
+}
+
+const userMetaPrefix = "X-Amz-Meta-"
+
+// storedMeta return the metadata which is kept of the object from the metadata of the request
+func storedMeta(meta map[string]string) map[string]string {
+	res := make(map[string]string)
+	for k, v := range meta {
+		k = http.CanonicalHeaderKey(k)
+		if storedHeaders[k] || strings.HasPrefix(k, userMetaPrefix) {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+func headerMeta(h http.Header) map[string]string {
+	meta := make(map[string]string, len(h))
+	for k, v := range h {
+		if len(v) > 0 {
+			meta[k] = v[0]
+		}
+	}
+	return storedMeta(meta)
+}
+
+// loadObjectMeta add the kept metadata of the object to the meta
+func loadObjectMeta(fp string, meta map[string]string) {
+	m, err := op.GetS3ObjectMeta(fp)
+	if err != nil {
+		log.Warnf("failed get s3 metadata of %s: %+v", fp, err)
+		return
+	}
+	if m == nil {
+		return
+	}
+	var kept map[string]string
+	if err = json.Unmarshal([]byte(m.Meta), &kept); err != nil {
+		log.Warnf("failed parse s3 metadata of %s: %+v", fp, err)
+		return
+	}
+	for k, v := range kept {
+		meta[k] = v
+	}
+}
+
+// saveObjectMeta keep the metadata of the object, which replaces the old one
+func saveObjectMeta(fp string, meta map[string]string) {
+	kept := storedMeta(meta)
+	var err error
+	if len(kept) == 0 {
+		err = op.DeleteS3ObjectMeta(fp)
+	} else {
+		var data []byte
+		if data, err = json.Marshal(kept); err == nil {
+			err = op.SaveS3ObjectMeta(&model.S3ObjectMeta{Path: fp, Meta: string(data)})
+		}
+	}
+	if err != nil {
+		log.Warnf("failed save s3 metadata of %s: %+v", fp, err)
+	}
+}
@@ -0,0 +1,590 @@
+package s3
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/alist-org/gofakes3"
+	"github.com/alist-org/gofakes3/xml"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// The multipart uploads are handled here instead of the faker, which keeps the parts in memory.
+// The parts are kept in <temp dir>/s3-multipart/<upload id>/<part number> and recorded in the db,
+// so the uploads can be continued after restarting, and they are put by the backend once completed.
+
+const s3Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+type initiateMultipartUploadResult struct {
+	XMLName xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	gofakes3.InitiateMultipartUpload
+}
+
+type completeMultipartUploadResult struct {
+	XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	gofakes3.CompleteMultipartUploadResult
+}
+
+type listMultipartUploadsResult struct {
+	XMLName xml.Name `xml:"ListMultipartUploadsResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	gofakes3.ListMultipartUploadsResult
+}
+
+// the parts of an upload can be written at the same time, but not while it is being completed or aborted
+var uploadLocks sync.Map
+
+func uploadLock(id string) *sync.RWMutex {
+	l, _ := uploadLocks.LoadOrStore(id, new(sync.RWMutex))
+	return l.(*sync.RWMutex)
+}
+
+func multipartDir() string {
+	return filepath.Join(conf.Conf.TempDir, conf.S3MultipartDirName)
+}
+
+func uploadDir(id string) string {
+	return filepath.Join(multipartDir(), id)
+}
+
+func partFile(id string, number int) string {
+	return filepath.Join(uploadDir(id), strconv.Itoa(number))
+}
+
+// isMultipart check whether the request is routed to the multipart uploads by the faker
+func isMultipart(r *http.Request) bool {
+	query := r.URL.Query()
+	if query.Get("uploadId") != "" {
+		return true
+	}
+	_, ok := query["uploads"]
+	return ok
+}
+
+func (s *server) serveMultipart(w http.ResponseWriter, r *http.Request) {
+	bucket, object, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+	var err error
+	if uploadID := r.URL.Query().Get("uploadId"); uploadID != "" {
+		switch r.Method {
+		case http.MethodGet:
+			err = s.listParts(w, r, bucket, object, uploadID)
+		case http.MethodPut:
+			err = s.uploadPart(w, r, bucket, object, uploadID)
+		case http.MethodDelete:
+			err = s.abortUpload(w, r, bucket, object, uploadID)
+		case http.MethodPost:
+			err = s.completeUpload(w, r, bucket, object, uploadID)
+		default:
+			err = gofakes3.ErrMethodNotAllowed
+		}
+	} else {
+		switch r.Method {
+		case http.MethodGet:
+			err = s.listUploads(w, r, bucket)
+		case http.MethodPost:
+			err = s.createUpload(w, r, bucket, object)
+		default:
+			err = gofakes3.ErrMethodNotAllowed
+		}
+	}
+	if err != nil {
+		writeError(w, r, err)
+	}
+}
+
+func (s *server) createUpload(w http.ResponseWriter, r *http.Request, bucketName, object string) error {
+	ctx := r.Context()
+	if object == "" {
+		return gofakes3.ErrInvalidURI
+	}
+	bucket, err := getBucketByName(ctx, bucketName)
+	if err != nil {
+		return err
+	}
+	if !canWrite(ctx, path.Join(bucket.Path, object)) {
+		return errAccessDenied
+	}
+	meta, err := json.Marshal(headerMeta(r.Header))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	u := &model.S3MultipartUpload{
+		ID:        random.String(32),
+		UserID:    getUser(ctx).ID,
+		Bucket:    bucketName,
+		ObjectKey: object,
+		Meta:      string(meta),
+		Created:   now,
+		Updated:   now,
+	}
+	if err = os.MkdirAll(uploadDir(u.ID), 0o777); err != nil {
+		return errors.Wrap(err, "failed create multipart upload dir")
+	}
+	if err = op.CreateS3MultipartUpload(u); err != nil {
+		_ = os.RemoveAll(uploadDir(u.ID))
+		return err
+	}
+	return writeXML(w, initiateMultipartUploadResult{
+		Xmlns: s3Xmlns,
+		InitiateMultipartUpload: gofakes3.InitiateMultipartUpload{
+			Bucket:   bucketName,
+			Key:      object,
+			UploadID: gofakes3.UploadID(u.ID),
+		},
+	})
+}
+
+// getUpload get the upload of the user, the uploads of the other users are hidden
+func getUpload(r *http.Request, bucket, object, id string) (*model.S3MultipartUpload, error) {
+	u, err := op.GetS3MultipartUploadById(id)
+	if errors.Is(err, errs.UploadSessionNotFound) {
+		return nil, gofakes3.ErrNoSuchUpload
+	} else if err != nil {
+		return nil, err
+	}
+	if u.UserID != getUser(r.Context()).ID || u.Bucket != bucket || u.ObjectKey != object {
+		return nil, gofakes3.ErrNoSuchUpload
+	}
+	return u, nil
+}
+
+func (s *server) uploadPart(w http.ResponseWriter, r *http.Request, bucket, object, id string) error {
+	defer r.Body.Close()
+	number, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || number <= 0 || number > gofakes3.MaxUploadPartNumber {
+		return gofakes3.ErrInvalidPart
+	}
+	size, err := strconv.ParseInt(r.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return gofakes3.ErrMissingContentLength
+	}
+	var body io.Reader = r.Body
+	// the sha256 of the payload to check, if the payload is signed as a whole
+	var payloadHash hash.Hash
+	contentSha256 := r.Header.Get("X-Amz-Content-Sha256")
+	switch {
+	case strings.HasPrefix(contentSha256, "STREAMING-"):
+		cr := newChunkedReader(r.Body)
+		if strings.HasPrefix(contentSha256, streamingSignedPayload) {
+			if cr.signer, err = s.newChunkSigner(r); err != nil {
+				return err
+			}
+		}
+		body = cr
+		if size, err = strconv.ParseInt(r.Header.Get("X-Amz-Decoded-Content-Length"), 10, 64); err != nil {
+			return gofakes3.ErrMissingContentLength
+		}
+	case contentSha256 != "" && contentSha256 != unsignedPayload && getAccessKeyId(r) != "":
+		payloadHash = sha256.New()
+	}
+
+	l := uploadLock(id)
+	l.RLock()
+	defer l.RUnlock()
+	if _, err = getUpload(r, bucket, object, id); err != nil {
+		return err
+	}
+	// the part is written to a temp file first, so the old part of the same number is kept if failed
+	f, err := os.CreateTemp(uploadDir(id), "part-*")
+	if err != nil {
+		return errors.Wrap(err, "failed create part file")
+	}
+	defer os.Remove(f.Name())
+	h := md5.New()
+	writers := []io.Writer{f, h}
+	if payloadHash != nil {
+		writers = append(writers, payloadHash)
+	}
+	n, err := utils.CopyWithBuffer(io.MultiWriter(writers...), io.LimitReader(body, size))
+	_ = f.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed write part file")
+	}
+	if n != size {
+		return gofakes3.ErrIncompleteBody
+	}
+	if payloadHash != nil && hex.EncodeToString(payloadHash.Sum(nil)) != strings.ToLower(contentSha256) {
+		return errContentSha256Mismatch
+	}
+	sum := h.Sum(nil)
+	if contentMd5 := r.Header.Get("Content-MD5"); contentMd5 != "" {
+		if expected, err := base64.StdEncoding.DecodeString(contentMd5); err != nil || string(expected) != string(sum) {
+			return gofakes3.ErrBadDigest
+		}
+	}
+	if err = os.Rename(f.Name(), partFile(id, number)); err != nil {
+		return errors.Wrap(err, "failed save part file")
+	}
+	etag := hex.EncodeToString(sum)
+	err = op.SaveS3MultipartPart(&model.S3MultipartPart{
+		UploadID: id,
+		Number:   number,
+		Size:     size,
+		ETag:     etag,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	w.Header().Set("ETag", `"`+etag+`"`)
+	return nil
+}
+
+func (s *server) completeUpload(w http.ResponseWriter, r *http.Request, bucket, object, id string) error {
+	var req gofakes3.CompleteMultipartUploadRequest
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		return gofakes3.ErrorMessage(gofakes3.ErrMalformedXML, err.Error())
+	}
+	l := uploadLock(id)
+	l.Lock()
+	defer l.Unlock()
+	u, err := getUpload(r, bucket, object, id)
+	if err != nil {
+		return err
+	}
+	parts, err := op.GetS3MultipartParts(id)
+	if err != nil {
+		return err
+	}
+	uploaded := make(map[int]model.S3MultipartPart, len(parts))
+	for _, p := range parts {
+		uploaded[p.Number] = p
+	}
+	if len(req.Parts) == 0 {
+		return gofakes3.ErrorMessage(gofakes3.ErrMalformedXML, "no part is specified")
+	}
+	// the etag of the object is the md5 of the md5 of the parts
+	h := md5.New()
+	var size int64
+	files := make([]string, 0, len(req.Parts))
+	for i, p := range req.Parts {
+		if i > 0 && p.PartNumber <= req.Parts[i-1].PartNumber {
+			return gofakes3.ErrInvalidPartOrder
+		}
+		part, ok := uploaded[p.PartNumber]
+		if !ok || strings.Trim(p.ETag, `"`) != part.ETag {
+			return gofakes3.ErrInvalidPart
+		}
+		sum, _ := hex.DecodeString(part.ETag)
+		h.Write(sum)
+		size += part.Size
+		file := partFile(id, p.PartNumber)
+		if _, err = os.Stat(file); err != nil {
+			return errors.Wrap(err, "failed get part file")
+		}
+		files = append(files, file)
+	}
+	var meta map[string]string
+	if err = json.Unmarshal([]byte(u.Meta), &meta); err != nil || meta == nil {
+		meta = make(map[string]string)
+	}
+	// the upload is kept to be completed again if failed
+	reader := &partsReader{files: files}
+	_, err = s.backend.PutObject(r.Context(), bucket, object, meta, reader, size)
+	_ = reader.Close()
+	if err != nil {
+		return err
+	}
+	if err = removeUpload(id); err != nil {
+		log.Errorf("failed remove s3 multipart upload %s: %+v", id, err)
+	}
+	return writeXML(w, completeMultipartUploadResult{
+		Xmlns: s3Xmlns,
+		CompleteMultipartUploadResult: gofakes3.CompleteMultipartUploadResult{
+			Bucket: bucket,
+			Key:    object,
+			ETag:   `"` + hex.EncodeToString(h.Sum(nil)) + "-" + strconv.Itoa(len(req.Parts)) + `"`,
+		},
+	})
+}
+
+func (s *server) abortUpload(w http.ResponseWriter, r *http.Request, bucket, object, id string) error {
+	l := uploadLock(id)
+	l.Lock()
+	defer l.Unlock()
+	if _, err := getUpload(r, bucket, object, id); err != nil {
+		return err
+	}
+	if err := removeUpload(id); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func removeUpload(id string) error {
+	if err := os.RemoveAll(uploadDir(id)); err != nil {
+		return errors.Wrap(err, "failed remove multipart upload dir")
+	}
+	uploadLocks.Delete(id)
+	return op.DeleteS3MultipartUploadById(id)
+}
+
+func (s *server) listParts(w http.ResponseWriter, r *http.Request, bucket, object, id string) error {
+	query := r.URL.Query()
+	marker, _ := strconv.Atoi(query.Get("part-number-marker"))
+	maxParts, err := strconv.Atoi(query.Get("max-parts"))
+	if err != nil || maxParts <= 0 || maxParts > gofakes3.MaxUploadPartsLimit {
+		maxParts = gofakes3.DefaultMaxUploadParts
+	}
+	if _, err = getUpload(r, bucket, object, id); err != nil {
+		return err
+	}
+	parts, err := op.GetS3MultipartParts(id)
+	if err != nil {
+		return err
+	}
+	res := gofakes3.ListMultipartUploadPartsResult{
+		Bucket:           bucket,
+		Key:              object,
+		UploadID:         gofakes3.UploadID(id),
+		StorageClass:     gofakes3.StorageStandard,
+		PartNumberMarker: marker,
+		MaxParts:         int64(maxParts),
+	}
+	for _, p := range parts {
+		if p.Number <= marker {
+			continue
+		}
+		if len(res.Parts) == maxParts {
+			res.IsTruncated = true
+			break
+		}
+		res.Parts = append(res.Parts, gofakes3.ListMultipartUploadPartItem{
+			PartNumber:   p.Number,
+			LastModified: gofakes3.NewContentTime(p.Modified),
+			ETag:         `"` + p.ETag + `"`,
+			Size:         p.Size,
+		})
+		res.NextPartNumberMarker = p.Number
+	}
+	return writeXML(w, res)
+}
+
+func (s *server) listUploads(w http.ResponseWriter, r *http.Request, bucket string) error {
+	ctx := r.Context()
+	if _, err := getBucketByName(ctx, bucket); err != nil {
+		return err
+	}
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	maxUploads, err := strconv.Atoi(query.Get("max-uploads"))
+	if err != nil || maxUploads <= 0 || maxUploads > gofakes3.MaxUploadsLimit {
+		maxUploads = gofakes3.DefaultMaxUploads
+	}
+	uploads, err := op.GetS3MultipartUploadsByBucket(getUser(ctx).ID, bucket)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(uploads, func(i, j int) bool {
+		return uploads[i].ObjectKey < uploads[j].ObjectKey
+	})
+	res := gofakes3.ListMultipartUploadsResult{
+		Bucket:     bucket,
+		Prefix:     prefix,
+		MaxUploads: int64(maxUploads),
+	}
+	for _, u := range uploads {
+		if !strings.HasPrefix(u.ObjectKey, prefix) {
+			continue
+		}
+		if len(res.Uploads) == maxUploads {
+			res.IsTruncated = true
+			break
+		}
+		res.Uploads = append(res.Uploads, gofakes3.ListMultipartUploadItem{
+			Key:          u.ObjectKey,
+			UploadID:     gofakes3.UploadID(u.ID),
+			StorageClass: gofakes3.StorageStandard,
+			Initiated:    gofakes3.NewContentTime(u.Created),
+		})
+	}
+	return writeXML(w, listMultipartUploadsResult{Xmlns: s3Xmlns, ListMultipartUploadsResult: res})
+}
+
+// purgeExpiredUploads remove the uploads which have no part uploaded for a long time,
+// the expiry is the same as the resumable uploads of the web
+func purgeExpiredUploads() {
+	expiry := time.Duration(setting.GetInt(conf.UploadSessionExpiry, 24)) * time.Hour
+	if expiry <= 0 {
+		return
+	}
+	before := time.Now().Add(-expiry)
+	uploads, err := op.GetS3MultipartUploadsUpdatedBefore(before)
+	if err != nil {
+		log.Errorf("failed get expired s3 multipart uploads: %+v", err)
+		return
+	}
+	for _, u := range uploads {
+		l := uploadLock(u.ID)
+		l.Lock()
+		if err = removeUpload(u.ID); err != nil {
+			log.Errorf("failed remove expired s3 multipart upload %s: %+v", u.ID, err)
+		}
+		l.Unlock()
+	}
+	// the dirs which are not recorded, if the server was stopped while creating the upload
+	entries, err := os.ReadDir(multipartDir())
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(before) {
+			continue
+		}
+		if _, err = op.GetS3MultipartUploadById(entry.Name()); errors.Is(err, errs.UploadSessionNotFound) {
+			_ = os.RemoveAll(filepath.Join(multipartDir(), entry.Name()))
+		}
+	}
+}
+
+// partsReader read the part files in order, only one of the files is opened at a time
+type partsReader struct {
+	files []string
+	cur   *os.File
+}
+
+func (p *partsReader) Read(b []byte) (int, error) {
+	for {
+		if p.cur == nil {
+			if len(p.files) == 0 {
+				return 0, io.EOF
+			}
+			f, err := os.Open(p.files[0])
+			if err != nil {
+				return 0, errors.Wrap(err, "failed open part file")
+			}
+			p.cur, p.files = f, p.files[1:]
+		}
+		n, err := p.cur.Read(b)
+		if err == io.EOF {
+			_ = p.cur.Close()
+			p.cur = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (p *partsReader) Close() error {
+	if p.cur == nil {
+		return nil
+	}
+	return p.cur.Close()
+}
+
+// the chunks of the signed payload are kept in memory to be verified before being written,
+// the sdks send the chunks of 64KB by default
+const maxSignedChunkSize = 16 * 1024 * 1024
+
+// chunkedReader decode the body of aws-chunked encoding, the signatures of the chunks are verified if signer is set
+type chunkedReader struct {
+	r      *bufio.Reader
+	left   int64
+	done   bool
+	signer *chunkSigner
+	// the verified data of the current chunk which has not been read
+	buf []byte
+}
+
+func newChunkedReader(r io.Reader) *chunkedReader {
+	return &chunkedReader{r: bufio.NewReader(r)}
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if c.left == 0 && len(c.buf) == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		// <hex size>[;chunk-signature=<signature>]\r\n
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return 0, io.ErrUnexpectedEOF
+		}
+		sizeStr, ext, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeStr, 16, 64)
+		if err != nil || size < 0 {
+			return 0, errors.New("invalid chunk size")
+		}
+		if c.signer != nil {
+			if err = c.readSigned(size, strings.TrimPrefix(ext, "chunk-signature=")); err != nil {
+				return 0, err
+			}
+		} else {
+			c.left = size
+		}
+		// the trailers after the last chunk are ignored
+		if size == 0 {
+			c.done = true
+			return 0, io.EOF
+		}
+	}
+	if len(c.buf) > 0 {
+		n := copy(p, c.buf)
+		c.buf = c.buf[n:]
+		return n, nil
+	}
+	if int64(len(p)) > c.left {
+		p = p[:c.left]
+	}
+	n, err := c.r.Read(p)
+	c.left -= int64(n)
+	if c.left == 0 {
+		// the \r\n after the data of the chunk
+		if _, err := c.r.ReadString('\n'); err != nil {
+			return n, io.ErrUnexpectedEOF
+		}
+	}
+	return n, err
+}
+
+// readSigned read the whole chunk and verify its signature before it is read
+func (c *chunkedReader) readSigned(size int64, sig string) error {
+	if size > maxSignedChunkSize {
+		return errors.Errorf("the chunk of %d bytes is too large", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	if size > 0 {
+		if _, err := c.r.ReadString('\n'); err != nil {
+			return io.ErrUnexpectedEOF
+		}
+	}
+	if !c.signer.verify(data, sig) {
+		return errSignatureDoesNotMatch
+	}
+	c.buf = data
+	return nil
+}
@@ -9,59 +9,47 @@ import (
 )
 
 // pager splits the object list into smulitply pages.
+//
+// The prefixes and the objects are listed together in the order of their keys like s3, so the marker,
+// which is the start-after or the decoded continuation-token of v2, can be any key instead of a listed one.
 func (db *s3Backend) pager(list *gofakes3.ObjectList, page gofakes3.ListBucketPage) (*gofakes3.ObjectList, error) {
-	// sort by alphabet
-	sort.Slice(list.CommonPrefixes, func(i, j int) bool {
-		return list.CommonPrefixes[i].Prefix < list.CommonPrefixes[j].Prefix
-	})
-	// sort by modtime
-	sort.Slice(list.Contents, func(i, j int) bool {
-		return list.Contents[i].LastModified.Before(list.Contents[j].LastModified.Time)
-	})
-	tokens := page.MaxKeys
-	if tokens == 0 {
-		tokens = 1000
+	type entry struct {
+		key     string
+		content *gofakes3.Content
 	}
-	if page.HasMarker {
-		for i, obj := range list.Contents {
-			if obj.Key == page.Marker {
-				list.Contents = list.Contents[i+1:]
-				break
-			}
-		}
-		for i, obj := range list.CommonPrefixes {
-			if obj.Prefix == page.Marker {
-				list.CommonPrefixes = list.CommonPrefixes[i+1:]
-				break
-			}
-		}
+	entries := make([]entry, 0, len(list.CommonPrefixes)+len(list.Contents))
+	for _, p := range list.CommonPrefixes {
+		entries = append(entries, entry{key: p.Prefix})
 	}
-
-	response := gofakes3.NewObjectList()
-	for _, obj := range list.CommonPrefixes {
-		if tokens <= 0 {
-			break
-		}
-		response.AddPrefix(obj.Prefix)
-		tokens--
+	for _, c := range list.Contents {
+		entries = append(entries, entry{key: c.Key, content: c})
 	}
-
-	for _, obj := range list.Contents {
-		if tokens <= 0 {
-			break
-		}
-		response.Add(obj)
-		tokens--
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+	if page.HasMarker {
+		i := sort.Search(len(entries), func(i int) bool {
+			return entries[i].key > page.Marker
+		})
+		entries = entries[i:]
+	}
+	maxKeys := int(page.MaxKeys)
+	if maxKeys <= 0 {
+		maxKeys = gofakes3.DefaultMaxBucketKeys
 	}
 
-	if len(list.CommonPrefixes)+len(list.Contents) > int(page.MaxKeys) {
+	response := gofakes3.NewObjectList()
+	if len(entries) > maxKeys {
+		entries = entries[:maxKeys]
 		response.IsTruncated = true
-		if len(response.Contents) > 0 {
-			response.NextMarker = response.Contents[len(response.Contents)-1].Key
+		response.NextMarker = entries[len(entries)-1].key
+	}
+	for _, e := range entries {
+		if e.content != nil {
+			response.Add(e.content)
 		} else {
-			response.NextMarker = response.CommonPrefixes[len(response.CommonPrefixes)-1].Prefix
+			response.AddPrefix(e.key)
 		}
 	}
-
 	return response, nil
 }
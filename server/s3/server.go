@@ -6,22 +6,50 @@ import (
 	"context"
 	"math/rand"
 	"net/http"
+	"time"
 
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
 	"github.com/alist-org/gofakes3"
 )
 
 // Make a new S3 Server to serve the remote
 func NewServer(ctx context.Context) (h http.Handler, err error) {
 	var newLogger logger
+	authList := authlistResolver()
+	if authList == nil {
+		authList = make(map[string]string)
+	}
+	keys, err := op.GetS3AccessKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		authList[k.AccessKeyId] = k.SecretAccessKey
+	}
+	backend := newBackend()
 	faker := gofakes3.New(
-		newBackend(),
+		backend,
 		// gofakes3.WithHostBucket(!opt.pathBucketMode),
 		gofakes3.WithLogger(newLogger),
 		gofakes3.WithRequestID(rand.Uint64()),
 		gofakes3.WithoutVersioning(),
-		gofakes3.WithV4Auth(authlistResolver()),
+		gofakes3.WithV4Auth(authList),
 		gofakes3.WithIntegrityCheck(true), // Check Content-MD5 if supplied
 	)
 
-	return faker.Server(), nil
+	s := &server{
+		faker:        faker,
+		handler:      faker.Server(),
+		backend:      backend,
+		globalKey:    setting.GetStr(conf.S3AccessKeyId),
+		globalSecret: setting.GetStr(conf.S3SecretAccessKey),
+	}
+	for _, k := range keys {
+		s.keys.Store(k.AccessKeyId, struct{}{})
+	}
+	cron.NewCron(time.Hour).Do(purgeExpiredUploads)
+	return s, nil
 }
@@ -4,7 +4,10 @@ package s3
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"encoding/json"
+	stdpath "path"
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
@@ -13,12 +16,17 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/gofakes3"
+	"github.com/pkg/errors"
 )
 
 type Bucket struct {
 	Name string `json:"name"`
 	Path string `json:"path"`
+	// User is the name of the only user who can see the bucket, the bucket is shared by all users if it is empty
+	User string `json:"user,omitempty"`
 }
 
 func getAndParseBuckets() ([]Bucket, error) {
@@ -27,8 +35,31 @@ func getAndParseBuckets() ([]Bucket, error) {
 	return res, err
 }
 
-func getBucketByName(name string) (Bucket, error) {
+// getUserBuckets return the buckets which can be seen by the user of the request,
+// and the paths of the buckets are joined with the base path of the user
+func getUserBuckets(ctx context.Context) ([]Bucket, error) {
 	buckets, err := getAndParseBuckets()
+	if err != nil {
+		return nil, err
+	}
+	user := getUser(ctx)
+	var res []Bucket
+	for _, b := range buckets {
+		if b.User != "" && b.User != user.Username {
+			continue
+		}
+		p, err := user.JoinPath(b.Path)
+		if err != nil {
+			continue
+		}
+		b.Path = p
+		res = append(res, b)
+	}
+	return res, nil
+}
+
+func getBucketByName(ctx context.Context, name string) (Bucket, error) {
+	buckets, err := getUserBuckets(ctx)
 	if err != nil {
 		return Bucket{}, err
 	}
@@ -40,8 +71,53 @@ func getBucketByName(name string) (Bucket, error) {
 	return Bucket{}, gofakes3.BucketNotFound(name)
 }
 
-func getDirEntries(path string) ([]model.Obj, error) {
-	ctx := context.Background()
+func getUser(ctx context.Context) *model.User {
+	return ctx.Value("user").(*model.User)
+}
+
+func getMeta(path string) (*model.Meta, error) {
+	meta, err := op.GetNearestMeta(path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// canRead check whether the user can read the path. The s3 clients can't provide the passwords of the metas,
+// so the paths with password are only readable with the permission of accessing without password.
+func canRead(ctx context.Context, path string) bool {
+	user := getUser(ctx)
+	meta, err := getMeta(path)
+	if err != nil {
+		return false
+	}
+	return common.CheckPathLimitWithRoles(user, path) && common.CanAccessWithRoles(user, meta, path, "")
+}
+
+// canWrite check whether the user can put the file or make the dir of the path
+func canWrite(ctx context.Context, path string) bool {
+	if !canRead(ctx, path) {
+		return false
+	}
+	user := getUser(ctx)
+	if common.HasPermission(common.MergeRolePermissions(user, path), common.PermWrite) {
+		return true
+	}
+	meta, err := getMeta(stdpath.Dir(path))
+	return err == nil && common.CanWrite(meta, stdpath.Dir(path))
+}
+
+// canRemove check whether the user can remove the path
+func canRemove(ctx context.Context, path string) bool {
+	return canRead(ctx, path) &&
+		common.HasPermission(common.MergeRolePermissions(getUser(ctx), path), common.PermRemove)
+}
+
+// getDirEntries list the dir, the entries which are hidden from the user are excluded
+func getDirEntries(ctx context.Context, path string) ([]model.Obj, error) {
+	if !canRead(ctx, path) {
+		return nil, errAccessDenied
+	}
 	meta, _ := op.GetNearestMeta(path)
 	fi, err := fs.Get(context.WithValue(ctx, "meta", meta), path, &fs.GetArgs{})
 	if errs.IsNotFoundError(err) {
@@ -59,53 +135,28 @@ func getDirEntries(path string) ([]model.Obj, error) {
 		return nil, err
 	}
 
-	return dirEntries, nil
+	user := getUser(ctx)
+	res := make([]model.Obj, 0, len(dirEntries))
+	for _, entry := range dirEntries {
+		if common.CanAccessWithRoles(user, meta, stdpath.Join(path, entry.GetName()), "") {
+			res = append(res, entry)
+		}
+	}
+	return res, nil
 }
 
-// func getFileHashByte(node interface{}) []byte {
-// 	b, err := hex.DecodeString(getFileHash(node))
-// 	if err != nil {
-// 		return nil
-// 	}
-// 	return b
-// }
+// getFileHashByte return the md5 of the object if the driver provides it, which is used as the etag
+func getFileHashByte(obj model.Obj) []byte {
+	b, err := hex.DecodeString(getFileHash(obj))
+	if err != nil || len(b) != md5.Size {
+		return nil
+	}
+	return b
+}
 
-func getFileHash(node interface{}) string {
-	// var o fs.Object
-
-	// switch b := node.(type) {
-	// case vfs.Node:
-	// 	fsObj, ok := b.DirEntry().(fs.Object)
-	// 	if !ok {
-	// 		fs.Debugf("serve s3", "File uploading - reading hash from VFS cache")
-	// 		in, err := b.Open(os.O_RDONLY)
-	// 		if err != nil {
-	// 			return ""
-	// 		}
-	// 		defer func() {
-	// 			_ = in.Close()
-	// 		}()
-	// 		h, err := hash.NewMultiHasherTypes(hash.NewHashSet(hash.MD5))
-	// 		if err != nil {
-	// 			return ""
-	// 		}
-	// 		_, err = io.Copy(h, in)
-	// 		if err != nil {
-	// 			return ""
-	// 		}
-	// 		return h.Sums()[hash.MD5]
-	// 	}
-	// 	o = fsObj
-	// case fs.Object:
-	// 	o = b
-	// }
-
-	// hash, err := o.Hash(context.Background(), hash.MD5)
-	// if err != nil {
-	// 	return ""
-	// }
-	// return hash
-	return ""
+// getFileHash return the hex md5 of the object, empty if the driver doesn't provide it
+func getFileHash(obj model.Obj) string {
+	return strings.ToLower(obj.GetHash().GetHash(utils.MD5))
 }
 
 func prefixParser(p *gofakes3.Prefix) (path, remaining string) {
@@ -26,7 +26,7 @@ var handler *webdav.Handler
 func WebDav(dav *gin.RouterGroup) {
 	handler = &webdav.Handler{
 		Prefix:     path.Join(conf.URL.Path, "/dav"),
-		LockSystem: webdav.NewMemLS(),
+		LockSystem: lockSystem(),
 		Logger: func(request *http.Request, err error) {
 			log.Errorf("%s %s %+v", request.Method, request.URL.Path, err)
 		},
@@ -46,9 +46,18 @@ func WebDav(dav *gin.RouterGroup) {
 	dav.Handle("MOVE", "/*path", ServeWebDAV)
 }
 
+func lockSystem() webdav.LockSystem {
+	if setting.GetStr(conf.WebdavLockSystem) == "memory" {
+		return webdav.NewMemLS()
+	}
+	return webdav.NewDBLS()
+}
+
 func ServeWebDAV(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
 	ctx := context.WithValue(c.Request.Context(), "user", user)
+	ctx = context.WithValue(ctx, conf.ProtocolKey, "webdav")
+	ctx = context.WithValue(ctx, conf.ClientIPKey, c.ClientIP())
 	handler.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
 }
 
@@ -0,0 +1,194 @@
+package webdav
+
+import (
+	"errors"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// webdavHoldLease is how long the locks confirmed by a request are held without renewing,
+// the holding request renews it until it is released
+const webdavHoldLease = time.Minute
+
+// NewDBLS returns a LockSystem which keeps the locks in the db of alist, so the locks are kept
+// after restarting and shared by the instances using the same db. The locks held by Confirm
+// are also kept in the db with a lease, so they are held against the requests of all the instances.
+func NewDBLS() LockSystem {
+	return &dbLS{}
+}
+
+type dbLS struct{}
+
+func (m *dbLS) Confirm(now time.Time, name0, name1 string, conditions ...Condition) (func(), error) {
+	var t0, t1 string
+	var err error
+	if name0 != "" {
+		if t0, err = m.lookup(now, slashClean(name0), conditions...); err != nil || t0 == "" {
+			return nil, confirmErr(err)
+		}
+	}
+	if name1 != "" {
+		if t1, err = m.lookup(now, slashClean(name1), conditions...); err != nil || t1 == "" {
+			return nil, confirmErr(err)
+		}
+	}
+
+	// Don't hold the same lock twice.
+	if t1 == t0 {
+		t1 = ""
+	}
+	var tokens []string
+	for _, t := range []string{t0, t1} {
+		if t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	if len(tokens) == 0 {
+		return func() {}, nil
+	}
+	holder := uuid.NewString()
+	err = op.HoldWebdavLocks(tokens, holder, now, time.Now().Add(webdavHoldLease))
+	if errors.Is(err, errs.WebdavLocked) {
+		return nil, ErrConfirmationFailed
+	}
+	if err != nil {
+		return nil, err
+	}
+	stop := make(chan struct{})
+	go renewHold(tokens, holder, stop)
+	return func() {
+		close(stop)
+		if err := op.ReleaseWebdavLocks(tokens, holder); err != nil {
+			log.Warnf("failed release webdav locks %v: %+v", tokens, err)
+		}
+	}, nil
+}
+
+// renewHold renew the lease of the held locks until stop is closed
+func renewHold(tokens []string, holder string, stop chan struct{}) {
+	ticker := time.NewTicker(webdavHoldLease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := op.RenewWebdavLocks(tokens, holder, time.Now().Add(webdavHoldLease)); err != nil {
+				log.Warnf("failed renew webdav locks %v: %+v", tokens, err)
+			}
+		}
+	}
+}
+
+func confirmErr(err error) error {
+	if err != nil {
+		return err
+	}
+	return ErrConfirmationFailed
+}
+
+// lookup returns the token of the lock that locks the named resource, provided that the lock
+// matches at least one of the given conditions and isn't held by another request.
+func (m *dbLS) lookup(now time.Time, name string, conditions ...Condition) (string, error) {
+	// TODO: support Condition.Not and Condition.ETag.
+	for _, c := range conditions {
+		if c.Token == "" {
+			continue
+		}
+		l, err := m.get(now, c.Token)
+		if err == ErrNoSuchLock {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if l.Held(now) {
+			continue
+		}
+		if l.Covers(name) {
+			return l.Token, nil
+		}
+	}
+	return "", nil
+}
+
+// get returns the lock of the token which has not expired
+func (m *dbLS) get(now time.Time, token string) (*model.WebdavLock, error) {
+	l, err := op.GetWebdavLockByToken(token)
+	if errors.Is(err, errs.WebdavLockNotFound) {
+		return nil, ErrNoSuchLock
+	}
+	if err != nil {
+		return nil, err
+	}
+	if l.Expired(now) {
+		return nil, ErrNoSuchLock
+	}
+	return l, nil
+}
+
+func (m *dbLS) Create(now time.Time, details LockDetails) (string, error) {
+	details.Root = slashClean(details.Root)
+	l := &model.WebdavLock{
+		Token:     "opaquelocktoken:" + uuid.NewString(),
+		Root:      details.Root,
+		ZeroDepth: details.ZeroDepth,
+		OwnerXML:  details.OwnerXML,
+		Created:   now,
+	}
+	setLockTimeout(l, now, details.Duration)
+	err := op.CreateWebdavLock(l, now)
+	if errors.Is(err, errs.WebdavLocked) {
+		return "", ErrLocked
+	}
+	if err != nil {
+		return "", err
+	}
+	return l.Token, nil
+}
+
+func (m *dbLS) Refresh(now time.Time, token string, duration time.Duration) (LockDetails, error) {
+	l, err := m.get(now, token)
+	if err != nil {
+		return LockDetails{}, err
+	}
+	setLockTimeout(l, now, duration)
+	err = op.RefreshWebdavLock(l, now)
+	if errors.Is(err, errs.WebdavLocked) {
+		return LockDetails{}, ErrLocked
+	}
+	if err != nil {
+		return LockDetails{}, err
+	}
+	return LockDetails{
+		Root:      l.Root,
+		Duration:  duration,
+		OwnerXML:  l.OwnerXML,
+		ZeroDepth: l.ZeroDepth,
+	}, nil
+}
+
+func (m *dbLS) Unlock(now time.Time, token string) error {
+	if _, err := m.get(now, token); err != nil {
+		return err
+	}
+	err := op.UnlockWebdavLock(token, now)
+	if errors.Is(err, errs.WebdavLocked) {
+		return ErrLocked
+	}
+	return err
+}
+
+func setLockTimeout(l *model.WebdavLock, now time.Time, duration time.Duration) {
+	if duration < 0 {
+		l.Timeout, l.Expires = -1, nil
+		return
+	}
+	expires := now.Add(duration)
+	l.Timeout, l.Expires = int64(duration/time.Second), &expires
+}
@@ -0,0 +1,88 @@
+package webdav
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+// the lock systems of two instances sharing the db
+func TestDBLSShared(t *testing.T) {
+	a, b := NewDBLS(), NewDBLS()
+	now := time.Now()
+	token, err := a.Create(now, LockDetails{Root: "/shared/dir", Duration: time.Hour})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err = b.Create(now, LockDetails{Root: "/shared/dir/file", Duration: time.Hour, ZeroDepth: true}); err != ErrLocked {
+		t.Fatalf("Create (descendant): got %v, want ErrLocked", err)
+	}
+
+	release, err := a.Confirm(now, "/shared/dir/file", "", Condition{Token: token})
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	if _, err = b.Confirm(now, "/shared/dir/file", "", Condition{Token: token}); err != ErrConfirmationFailed {
+		t.Fatalf("Confirm (held by another instance): got %v, want ErrConfirmationFailed", err)
+	}
+	if _, err = b.Refresh(now, token, time.Hour); err != ErrLocked {
+		t.Fatalf("Refresh (held by another instance): got %v, want ErrLocked", err)
+	}
+	if err = b.Unlock(now, token); err != ErrLocked {
+		t.Fatalf("Unlock (held by another instance): got %v, want ErrLocked", err)
+	}
+	// the hold of a crashed instance ends with its lease
+	later := now.Add(webdavHoldLease + time.Second)
+	if _, err = b.Refresh(later, token, time.Hour); err != nil {
+		t.Fatalf("Refresh (lease expired): %v", err)
+	}
+
+	release()
+	if err = b.Unlock(now, token); err != nil {
+		t.Fatalf("Unlock (released): %v", err)
+	}
+}
+
+func TestDBLSCreateConcurrently(t *testing.T) {
+	now := time.Now()
+	roots := []string{"/concurrent", "/concurrent/a", "/concurrent/a/b", "/concurrent/a/b/c"}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var created []string
+	for _, root := range roots {
+		wg.Add(1)
+		go func(root string) {
+			defer wg.Done()
+			ls := NewDBLS()
+			if token, err := ls.Create(now, LockDetails{Root: root, Duration: time.Hour}); err == nil {
+				mu.Lock()
+				created = append(created, root)
+				mu.Unlock()
+				t.Cleanup(func() {
+					_ = ls.Unlock(now, token)
+				})
+			} else if err != ErrLocked {
+				t.Errorf("Create %s: %v", root, err)
+			}
+		}(root)
+	}
+	wg.Wait()
+	// each root is under the previous one, so only one of the infinite depth locks can be created
+	if len(created) != 1 {
+		t.Errorf("expected one lock to be created, got %v", created)
+	}
+}
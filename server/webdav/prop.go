@@ -17,6 +17,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/server/common"
 )
@@ -109,6 +110,9 @@ var liveProps = map[xml.Name]struct {
 	findFn func(context.Context, LockSystem, string, model.Obj) (string, error)
 	// dir is true if the property applies to directories.
 	dir bool
+	// explicit is true if the property is only returned when requested by name,
+	// it is not part of allprop.
+	explicit bool
 }{
 	{Space: "DAV:", Local: "resourcetype"}: {
 		findFn: findResourceType,
@@ -165,6 +169,17 @@ var liveProps = map[xml.Name]struct {
 		findFn: findChecksums,
 		dir:    false,
 	},
+	// http://www.webdav.org/specs/rfc4331.html
+	{Space: "DAV:", Local: "quota-available-bytes"}: {
+		findFn:   findQuotaAvailableBytes,
+		dir:      true,
+		explicit: true,
+	},
+	{Space: "DAV:", Local: "quota-used-bytes"}: {
+		findFn:   findQuotaUsedBytes,
+		dir:      true,
+		explicit: true,
+	},
 }
 
 // TODO(nigeltao) merge props and allprop?
@@ -173,7 +188,7 @@ var liveProps = map[xml.Name]struct {
 //
 // Each Propstat has a unique status and each property name will only be part
 // of one Propstat element.
-func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name) ([]Propstat, error) {
+func props(ctx context.Context, ls LockSystem, name string, fi model.Obj, pnames []xml.Name) ([]Propstat, error) {
 	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
 	//if err != nil {
 	//	return nil, err
@@ -185,14 +200,10 @@ func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name)
 	//}
 	isDir := fi.IsDir()
 
-	var deadProps map[xml.Name]Property
-	// ??? what is this for?
-	//if dph, ok := f.(DeadPropsHolder); ok {
-	//	deadProps, err = dph.DeadProps()
-	//	if err != nil {
-	//		return nil, err
-	//	}
-	//}
+	deadProps, err := getDeadProps(ctx, name)
+	if err != nil {
+		return nil, err
+	}
 
 	pstatOK := Propstat{Status: http.StatusOK}
 	pstatNotFound := Propstat{Status: http.StatusNotFound}
@@ -204,7 +215,13 @@ func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name)
 		}
 		// Otherwise, it must either be a live property or we don't know it.
 		if prop := liveProps[pn]; prop.findFn != nil && (prop.dir || !isDir) {
-			innerXML, err := prop.findFn(ctx, ls, fi.GetName(), fi)
+			innerXML, err := prop.findFn(ctx, ls, name, fi)
+			if errors.Is(err, ErrNotImplemented) {
+				pstatNotFound.Props = append(pstatNotFound.Props, Property{
+					XMLName: pn,
+				})
+				continue
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -222,7 +239,7 @@ func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name)
 }
 
 // Propnames returns the property names defined for resource name.
-func propnames(ctx context.Context, ls LockSystem, fi model.Obj) ([]xml.Name, error) {
+func propnames(ctx context.Context, ls LockSystem, name string, fi model.Obj) ([]xml.Name, error) {
 	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
 	//if err != nil {
 	//	return nil, err
@@ -234,14 +251,10 @@ func propnames(ctx context.Context, ls LockSystem, fi model.Obj) ([]xml.Name, er
 	//}
 	isDir := fi.IsDir()
 
-	var deadProps map[xml.Name]Property
-	// ??? what is this for?
-	//if dph, ok := f.(DeadPropsHolder); ok {
-	//	deadProps, err = dph.DeadProps()
-	//	if err != nil {
-	//		return nil, err
-	//	}
-	//}
+	deadProps, err := getDeadProps(ctx, name)
+	if err != nil {
+		return nil, err
+	}
 
 	pnames := make([]xml.Name, 0, len(liveProps)+len(deadProps))
 	for pn, prop := range liveProps {
@@ -263,11 +276,17 @@ func propnames(ctx context.Context, ls LockSystem, fi model.Obj) ([]xml.Name, er
 // returned if they are named in 'include'.
 //
 // See http://www.webdav.org/specs/rfc4918.html#METHOD_PROPFIND
-func allprop(ctx context.Context, ls LockSystem, fi model.Obj, include []xml.Name) ([]Propstat, error) {
-	pnames, err := propnames(ctx, ls, fi)
+func allprop(ctx context.Context, ls LockSystem, name string, fi model.Obj, include []xml.Name) ([]Propstat, error) {
+	names, err := propnames(ctx, ls, name, fi)
 	if err != nil {
 		return nil, err
 	}
+	pnames := make([]xml.Name, 0, len(names))
+	for _, pn := range names {
+		if !liveProps[pn].explicit {
+			pnames = append(pnames, pn)
+		}
+	}
 	// Add names from include if they are not already covered in pnames.
 	nameset := make(map[xml.Name]bool)
 	for _, pn := range pnames {
@@ -278,7 +297,7 @@ func allprop(ctx context.Context, ls LockSystem, fi model.Obj, include []xml.Nam
 			pnames = append(pnames, pn)
 		}
 	}
-	return props(ctx, ls, fi, pnames)
+	return props(ctx, ls, name, fi, pnames)
 }
 
 // Patch patches the properties of resource name. The return values are
@@ -314,37 +333,128 @@ loop:
 		return makePropstats(pstatForbidden, pstatFailedDep), nil
 	}
 
-	// ------------------------------------------------------------
-	//f, err := fs.OpenFile(ctx, name, os.O_RDWR, 0)
-	//if err != nil {
-	//	return nil, err
-	//}
-	//defer f.Close()
-	//if dph, ok := f.(DeadPropsHolder); ok {
-	//	ret, err := dph.Patch(patches)
-	//	if err != nil {
-	//		return nil, err
-	//	}
-	//	// http://www.webdav.org/specs/rfc4918.html#ELEMENT_propstat says that
-	//	// "The contents of the prop XML element must only list the names of
-	//	// properties to which the result in the status element applies."
-	//	for _, pstat := range ret {
-	//		for i, p := range pstat.Props {
-	//			pstat.Props[i] = Property{XMLName: p.XMLName}
-	//		}
-	//	}
-	//	return ret, nil
-	//}
-	// ------------------------------------------------------------
+	return patchDeadProps(name, patches)
+}
+
+// getDeadProps returns the dead properties of resource name, which are kept as the custom properties
+// of the object by fs, so they are moved, renamed and removed with the object.
+func getDeadProps(ctx context.Context, name string) (map[xml.Name]Property, error) {
+	if c, ok := ctx.Value("deadProps").(*deadPropsCache); ok {
+		return c.get(name)
+	}
+	objProps, err := fs.GetObjProps(name)
+	if err != nil {
+		return nil, err
+	}
+	return toDeadProps(objProps)[name], nil
+}
+
+// toDeadProps groups the properties by the paths of the objects
+func toDeadProps(objProps []model.ObjProp) map[string]map[xml.Name]Property {
+	deadProps := make(map[string]map[xml.Name]Property)
+	for _, p := range objProps {
+		if deadProps[p.Path] == nil {
+			deadProps[p.Path] = make(map[xml.Name]Property)
+		}
+		pn := xml.Name{Space: p.Space, Local: p.Name}
+		deadProps[p.Path][pn] = Property{
+			XMLName:  pn,
+			Lang:     p.Lang,
+			InnerXML: []byte(p.Value),
+		}
+	}
+	return deadProps
+}
+
+// deadPropsCache keeps the dead properties of the resources walked by PROPFIND, the properties of
+// the resources in a dir are loaded at once instead of one query for each of them.
+type deadPropsCache struct {
+	root string
+	// the dirs whose resources are loaded, the root is loaded alone with the empty key
+	loaded map[string]bool
+	props  map[string]map[xml.Name]Property
+}
 
-	// The file doesn't implement the optional DeadPropsHolder interface, so
-	// all patches are forbidden.
-	pstat := Propstat{Status: http.StatusForbidden}
+func newDeadPropsCache(root string) *deadPropsCache {
+	return &deadPropsCache{
+		root:   root,
+		loaded: make(map[string]bool),
+		props:  make(map[string]map[xml.Name]Property),
+	}
+}
+
+func (c *deadPropsCache) get(name string) (map[xml.Name]Property, error) {
+	// the resources other than the root are walked after listing their dir
+	key := path.Dir(name)
+	if name == c.root {
+		key = ""
+	}
+	if !c.loaded[key] {
+		var objProps []model.ObjProp
+		var err error
+		if key == "" {
+			objProps, err = fs.GetObjProps(name)
+		} else {
+			objProps, err = fs.GetObjPropsInDir(key)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for p, props := range toDeadProps(objProps) {
+			c.props[p] = props
+		}
+		c.loaded[key] = true
+	}
+	return c.props[name], nil
+}
+
+// patchDeadProps applies the patches to the dead properties of resource name in order,
+// and saves them at once, so either all or no patches succeed.
+func patchDeadProps(name string, patches []Proppatch) ([]Propstat, error) {
+	objProps, err := fs.GetObjProps(name)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[xml.Name]int, len(objProps))
+	for i, p := range objProps {
+		index[xml.Name{Space: p.Space, Local: p.Name}] = i
+	}
+	pstat := Propstat{Status: http.StatusOK}
 	for _, patch := range patches {
 		for _, p := range patch.Props {
 			pstat.Props = append(pstat.Props, Property{XMLName: p.XMLName})
+			i, ok := index[p.XMLName]
+			if patch.Remove {
+				if ok {
+					objProps[i].Name, objProps[i].Space = "", ""
+					delete(index, p.XMLName)
+				}
+				continue
+			}
+			prop := model.ObjProp{
+				Space: p.XMLName.Space,
+				Name:  p.XMLName.Local,
+				Lang:  p.Lang,
+				Value: string(p.InnerXML),
+			}
+			if ok {
+				objProps[i] = prop
+			} else {
+				index[p.XMLName] = len(objProps)
+				objProps = append(objProps, prop)
+			}
+		}
+	}
+	// the removed properties have no name
+	saved := make([]model.ObjProp, 0, len(objProps))
+	for _, p := range objProps {
+		if p.Name != "" {
+			saved = append(saved, p)
 		}
 	}
+	if err = fs.SaveObjProps(name, saved); err != nil {
+		return nil, err
+	}
 	return []Propstat{pstat}, nil
 }
 
@@ -489,6 +599,35 @@ func findSupportedLock(ctx context.Context, ls LockSystem, name string, fi model
 		`</D:lockentry>`, nil
 }
 
+// findQuotaAvailableBytes returns ErrNotImplemented if the storage can't report its capacity,
+// the property will be reported as not found instead of failing the whole request.
+func findQuotaAvailableBytes(ctx context.Context, ls LockSystem, name string, fi model.Obj) (string, error) {
+	details, err := getStorageDetails(ctx, name, fi)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(details.FreeSpace, 10), nil
+}
+
+func findQuotaUsedBytes(ctx context.Context, ls LockSystem, name string, fi model.Obj) (string, error) {
+	details, err := getStorageDetails(ctx, name, fi)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(details.UsedSpace, 10), nil
+}
+
+func getStorageDetails(ctx context.Context, name string, fi model.Obj) (*model.StorageDetails, error) {
+	if !fi.IsDir() {
+		return nil, ErrNotImplemented
+	}
+	details, err := fs.GetStorageDetails(ctx, name)
+	if err != nil {
+		return nil, ErrNotImplemented
+	}
+	return details, nil
+}
+
 func findChecksums(ctx context.Context, ls LockSystem, name string, fi model.Obj) (string, error) {
 	checksums := ""
 	for hashType, hashValue := range fi.GetHash().All() {
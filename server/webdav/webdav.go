@@ -99,10 +99,15 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// temporaryLockTimeout is the timeout of the temporary locks created by the requests without the If header,
+// they are unlocked at the end of the request, the timeout only matters if the lock is kept in the db and
+// the instance exits before unlocking it
+const temporaryLockTimeout = 10 * time.Minute
+
 func (h *Handler) lock(now time.Time, root string) (token string, status int, err error) {
 	token, err = h.LockSystem.Create(now, LockDetails{
 		Root:      root,
-		Duration:  infiniteTimeout,
+		Duration:  temporaryLockTimeout,
 		ZeroDepth: true,
 	})
 	if err != nil {
@@ -242,6 +247,9 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 	downProxyUrl := storage.GetStorage().DownProxyUrl
 	if storage.GetStorage().WebdavNative() || (storage.GetStorage().WebdavProxy() && downProxyUrl == "") {
 		link, _, err := fs.Link(ctx, reqPath, model.LinkArgs{Header: r.Header, HttpReq: r})
+		if r.Method == http.MethodGet {
+			fs.AuditDownload(ctx, reqPath, err)
+		}
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
@@ -258,9 +266,15 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 			utils.EncodePath(reqPath, true),
 			sign.Sign(reqPath))
 		w.Header().Set("Cache-Control", "max-age=0, no-cache, no-store, must-revalidate")
+		if r.Method == http.MethodGet {
+			fs.AuditDownload(ctx, reqPath, nil)
+		}
 		http.Redirect(w, r, u, http.StatusFound)
 	} else {
 		link, _, err := fs.Link(ctx, reqPath, model.LinkArgs{IP: utils.ClientIP(r), Header: r.Header, HttpReq: r, Redirect: true})
+		if r.Method == http.MethodGet {
+			fs.AuditDownload(ctx, reqPath, err)
+		}
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
@@ -343,6 +357,11 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 	if errs.IsNotFoundError(err) {
 		return http.StatusNotFound, err
 	}
+	if errors.Is(err, errs.QuotaExceeded) {
+		_ = r.Body.Close()
+		_ = fsStream.Close()
+		return StatusInsufficientStorage, err
+	}
 
 	_ = r.Body.Close()
 	_ = fsStream.Close()
@@ -647,6 +666,7 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 	}
 
 	mw := multistatusWriter{w: w}
+	ctx = context.WithValue(ctx, "deadProps", newDeadPropsCache(reqPath))
 
 	walkFn := func(reqPath string, info model.Obj, err error) error {
 		if err != nil {
@@ -654,7 +674,7 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 		}
 		var pstats []Propstat
 		if pf.Propname != nil {
-			pnames, err := propnames(ctx, h.LockSystem, info)
+			pnames, err := propnames(ctx, h.LockSystem, reqPath, info)
 			if err != nil {
 				return err
 			}
@@ -664,9 +684,9 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 			}
 			pstats = append(pstats, pstat)
 		} else if pf.Allprop != nil {
-			pstats, err = allprop(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = allprop(ctx, h.LockSystem, reqPath, info, pf.Prop)
 		} else {
-			pstats, err = props(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = props(ctx, h.LockSystem, reqPath, info, pf.Prop)
 		}
 		if err != nil {
 			return err